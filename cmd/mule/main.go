@@ -32,6 +32,7 @@ func init() {
 		"add": func(a, b int) int {
 			return a + b
 		},
+		"join": strings.Join,
 	}
 
 	templates, err = template.New("").Funcs(funcMap).ParseFS(templatesFS, "templates/*.html")
@@ -58,6 +59,7 @@ func main() {
 	}
 
 	state.State = appState
+	handlers.InitWebhooks(l.WithName("webhook"))
 
 	mux := http.NewServeMux()
 
@@ -65,6 +67,7 @@ func main() {
 	mux.HandleFunc("/api/repositories", methodsHandler(map[string]http.HandlerFunc{
 		http.MethodGet:    handlers.HandleListRepositories,
 		http.MethodPost:   handlers.HandleAddRepository,
+		http.MethodPut:    handlers.HandleEditRepository,
 		http.MethodDelete: handlers.HandleDeleteRepository,
 	}))
 
@@ -77,6 +80,13 @@ func main() {
 	mux.HandleFunc("/api/repositories/sync", methodsHandler(map[string]http.HandlerFunc{
 		http.MethodPost: handlers.HandleSyncRepository,
 	}))
+	mux.HandleFunc("/api/repositories/dry-runs", methodsHandler(map[string]http.HandlerFunc{
+		http.MethodGet: handlers.HandleGetDryRuns,
+	}))
+	mux.HandleFunc("/api/repositories/queue", methodsHandler(map[string]http.HandlerFunc{
+		http.MethodGet: handlers.HandleGetIssueQueue,
+		http.MethodPut: handlers.HandleSetIssueQueue,
+	}))
 	mux.HandleFunc("/api/repositories/provider", methodsHandler(map[string]http.HandlerFunc{
 		http.MethodPost: handlers.HandleSwitchProvider,
 	}))
@@ -108,6 +118,11 @@ func main() {
 		http.MethodGet: handlers.HandleGitHubIssues,
 	}))
 
+	// Webhook routes
+	mux.HandleFunc("/api/webhooks/github", methodsHandler(map[string]http.HandlerFunc{
+		http.MethodPost: handlers.HandleGitHubWebhook,
+	}))
+
 	// Local provider routes
 	mux.HandleFunc("/api/local/issues", methodsHandler(map[string]http.HandlerFunc{
 		http.MethodPost:   handlers.HandleCreateLocalIssue,
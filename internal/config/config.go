@@ -39,6 +39,7 @@ func LoadConfig(path string, l logr.Logger) (*state.AppState, error) {
 				Repositories: make(map[string]*repository.Repository),
 				Settings:     settings.DefaultSettings,
 				Scheduler:    scheduler.NewScheduler(l),
+				WorkState:    loadWorkState(path, l),
 			}
 			state.State = appState
 			return appState, SaveConfig(path)
@@ -52,6 +53,7 @@ func LoadConfig(path string, l logr.Logger) (*state.AppState, error) {
 	}
 	// Create state from config
 	appState := state.NewState(l, config.Settings)
+	appState.WorkState = loadWorkState(path, l)
 
 	// Set up repositories and their schedules
 	for path, repo := range config.Repositories {
@@ -71,7 +73,25 @@ func LoadConfig(path string, l logr.Logger) (*state.AppState, error) {
 		r.Logger = l.WithName("repository").WithValues("path", repo.Path)
 		r.Schedule = repo.Schedule
 		r.RemotePath = repo.RemotePath
+		r.BaseBranch = repo.BaseBranch
+		r.MaxConcurrency = repo.MaxConcurrency
+		r.SchedulingPolicy = repo.SchedulingPolicy
+		r.Queue = repo.Queue
+		r.CommentMode = repo.CommentMode
+		r.ReplyToComments = repo.ReplyToComments
+		r.Authorization = repo.Authorization
+		r.Labels = repo.Labels
+		r.BranchUpdate = repo.BranchUpdate
+		r.ConflictWorkflow = repo.ConflictWorkflow
+		r.DeleteBranches = repo.DeleteBranches
+		r.Workflow = repo.Workflow
+		r.DryRun = repo.DryRun
+		r.Commits = repo.Commits
+		r.Workflows = appState.Workflows
+		r.RAG = appState.RAG
+		r.WorkState = appState.WorkState
 		r.RemoteProvider = repo.RemoteProvider
+		r.Auth = repo.Auth
 		err = r.UpdateStatus()
 		if err != nil {
 			l.Error(err, "Error getting repo status")
@@ -92,6 +112,15 @@ func LoadConfig(path string, l logr.Logger) (*state.AppState, error) {
 	return appState, nil
 }
 
+// loadWorkState loads the issue work state stored next to the config file
+func loadWorkState(configPath string, l logr.Logger) *repository.WorkStateStore {
+	store, err := repository.LoadWorkStateStore(filepath.Join(filepath.Dir(configPath), repository.WorkStateFile))
+	if err != nil {
+		l.Error(err, "Error loading work state")
+	}
+	return store
+}
+
 func SaveConfig(path string) error {
 	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
 		return err
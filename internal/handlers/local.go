@@ -9,6 +9,7 @@ import (
 
 	"github.com/mule-ai/mule/internal/settings"
 	"github.com/mule-ai/mule/internal/state"
+	"github.com/mule-ai/mule/pkg/remote/local"
 	"github.com/mule-ai/mule/pkg/remote/types"
 )
 
@@ -196,6 +197,7 @@ func HandleAddLocalComment(w http.ResponseWriter, r *http.Request) {
 		Body:      req.Body,
 		DiffHunk:  req.DiffHunk,
 		Reactions: types.Reactions{},
+		Author:    local.WebUser,
 	}
 
 	switch req.ResourceType {
@@ -7,12 +7,14 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/go-git/go-git/v5"
 
 	"github.com/mule-ai/mule/internal/config"
 	"github.com/mule-ai/mule/internal/state"
+	"github.com/mule-ai/mule/pkg/auth"
 	"github.com/mule-ai/mule/pkg/remote"
 	"github.com/mule-ai/mule/pkg/repository"
 )
@@ -26,7 +28,8 @@ when adding a new repository. It includes the RepoURL, the BasePath where the re
 should be located, and a Schedule for periodic synchronization.
 
 HandleListRepositories: This handler responds to requests by returning a JSON representation of all 
-repositories currently tracked in the application's state.State.Repositories. 
+repositories currently tracked in the application's state.State.Repositories, including the work state of their issues 
+and the report of their latest sync. 
 It uses a read lock on the state for safe concurrent access.
 
 HandleAddRepository: This handler processes requests to add a new repository to the application. 
@@ -34,6 +37,9 @@ It takes a RepoAddRequest as input, determines the local path for the repository
 creates a repository.Repository object, validates that the path is a valid Git repository using go-git, 
 updates the repository's status, adds a scheduled task for syncing the repository using the application's Scheduler, and saves the updated configuration.
 
+HandleEditRepository: This handler changes the settings of a tracked repository, like the base branch 
+issue branches are created from and pull requests target, how many issues are worked on at once, how review comments are addressed or how git authenticates. Fields missing from the JSON body are left unchanged.
+
 HandleUpdateRepository: This handler triggers an update (fetch) for a specific repository identified by its 
 path in the JSON request body. It retrieves the repository, performs a Git fetch operation, 
 updates the repository's status, and returns the updated repository state as JSON.
@@ -50,7 +56,14 @@ removes it from state.State.Repositories, cancels its scheduled sync task, and s
 
 HandleSyncRepository: This handler manually triggers a synchronization process for a specific repository 
 identified by its path query parameter. It retrieves the repository and calls the repo.Sync method, 
-which likely involves fetching updates and potentially running workflows.
+which likely involves fetching updates and potentially running workflows. Issues that fail don't fail the request, 
+the sync report listing the result of every issue is returned as JSON.
+
+HandleGetIssueQueue: This handler returns the scheduling policy of the repository identified by its path 
+query parameter, its explicit queue and its open issues in the order the next sync works on them.
+
+HandleSetIssueQueue: This handler reorders the issues of a repository. It takes a RepoQueueRequest, 
+replaces the explicit queue and changes the scheduling policy when they are set, and saves the configuration.
 
 HandleSwitchProvider: This handler allows changing the remote provider associated with a repository. 
 It takes a JSON body with the repository path and the new provider name. 
@@ -67,16 +80,104 @@ repo.UpdateStatus and then saves the updated repository object back into state.S
 
 **/
 type RepoAddRequest struct {
-	RepoURL  string `json:"repoUrl"`
-	BasePath string `json:"path"`
-	Schedule string `json:"schedule"`
+	RepoURL    string        `json:"repoUrl"`
+	BasePath   string        `json:"path"`
+	Schedule   string        `json:"schedule"`
+	BaseBranch string        `json:"baseBranch"`
+	Auth       auth.Settings `json:"auth"`
+}
+
+// RepoEditRequest changes the settings of a tracked repository, fields that
+// are not set are left unchanged
+type RepoEditRequest struct {
+	Path           string  `json:"path"`
+	BaseBranch     *string `json:"baseBranch"`
+	MaxConcurrency *int    `json:"maxConcurrency"`
+	// CommentMode is "single" or "batch", see repository.CommentMode
+	CommentMode     *string `json:"commentMode"`
+	ReplyToComments *bool   `json:"replyToComments"`
+	// Authorization replaces the trusted authors, empty rules trust everyone
+	Authorization *repository.AuthorizationRules `json:"authorization"`
+	// Labels replaces the labels, an empty trigger label means "mule"
+	Labels *repository.LabelSettings `json:"labels"`
+	// BranchUpdate is "rebase", "merge" or "none", see
+	// repository.BranchUpdate
+	BranchUpdate     *string `json:"branchUpdate"`
+	ConflictWorkflow *string `json:"conflictWorkflow"`
+	// DeleteBranches removes the branches of merged and closed pull requests
+	DeleteBranches *bool `json:"deleteBranches"`
+	// Workflow names a configured workflow, empty uses the default workflow
+	Workflow *string `json:"workflow"`
+	// DryRun makes every sync a dry run
+	DryRun *bool `json:"dryRun"`
+	// Commits replaces the commit identity and signing
+	Commits *repository.CommitSettings `json:"commits"`
+	// Auth replaces the git authentication, an empty passphrase or token
+	// keeps the current one since the list leaves them out
+	Auth *auth.Settings `json:"auth"`
+}
+
+// RepoQueueRequest changes the order issues are worked on in. Queue replaces
+// the explicit queue when set, Policy changes the scheduling policy.
+type RepoQueueRequest struct {
+	Path   string  `json:"path"`
+	Policy *string `json:"policy"`
+	Queue  []int   `json:"queue"`
+}
+
+// issueQueueView is the scheduling of a repository with its open issues in
+// the order they are worked on
+type issueQueueView struct {
+	Policy repository.SchedulingPolicy `json:"policy"`
+	Queue  []int                       `json:"queue"`
+	Issues []repository.QueuedIssue    `json:"issues"`
+}
+
+// repositoryView adds the work state of the issues and the result of the
+// latest sync to a repository. RemoteProvider, Auth and Commits hide the
+// settings of the repository with copies that leave the secrets out.
+type repositoryView struct {
+	*repository.Repository
+	RemoteProvider remote.ProviderSettings           `json:"remoteProvider"`
+	Auth           auth.Settings                     `json:"auth,omitempty"`
+	Commits        repository.CommitSettings         `json:"commits,omitempty"`
+	WorkStates     map[int]repository.IssueWorkState `json:"workStates"`
+	SyncReport     *repository.SyncReport            `json:"syncReport,omitempty"`
+}
+
+func newRepositoryView(repo *repository.Repository) repositoryView {
+	view := repositoryView{
+		Repository: repo,
+		WorkStates: repo.WorkStates(),
+		SyncReport: repo.LastSyncReport(),
+	}
+	repo.Mu.RLock()
+	view.RemoteProvider = repo.RemoteProvider
+	view.Auth = repo.Auth
+	view.Commits = repo.Commits
+	repo.Mu.RUnlock()
+
+	view.RemoteProvider.Token = ""
+	if view.RemoteProvider.App != nil {
+		app := *view.RemoteProvider.App
+		app.PrivateKey = ""
+		view.RemoteProvider.App = &app
+	}
+	view.Auth.Passphrase = ""
+	view.Auth.Token = ""
+	view.Commits.SigningKey = ""
+	return view
 }
 
 func HandleListRepositories(w http.ResponseWriter, r *http.Request) {
 	state.State.Mu.RLock()
 	defer state.State.Mu.RUnlock()
 
-	err := json.NewEncoder(w).Encode(state.State.Repositories)
+	repositories := make(map[string]repositoryView, len(state.State.Repositories))
+	for path, repo := range state.State.Repositories {
+		repositories[path] = newRepositoryView(repo)
+	}
+	err := json.NewEncoder(w).Encode(repositories)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -93,6 +194,7 @@ func HandleAddRepository(w http.ResponseWriter, r *http.Request) {
 	}
 
 	repoName := strings.TrimPrefix(req.RepoURL, "https://github.com/")
+	repoName = strings.TrimPrefix(repoName, "git@github.com:")
 	repoName = strings.TrimSuffix(repoName, ".git")
 	repoPath := filepath.Join(req.BasePath, repoName)
 	absPath, err := filepath.Abs(repoPath)
@@ -103,6 +205,11 @@ func HandleAddRepository(w http.ResponseWriter, r *http.Request) {
 	repo := repository.NewRepository(absPath)
 	repo.Schedule = req.Schedule
 	repo.RemotePath = repoName
+	repo.BaseBranch = req.BaseBranch
+	repo.Auth = req.Auth
+	repo.RAG = state.State.RAG
+	repo.WorkState = state.State.WorkState
+	repo.Workflows = state.State.Workflows
 
 	_, err = git.PlainOpen(repo.Path)
 	if err != nil {
@@ -110,6 +217,13 @@ func HandleAddRepository(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if repo.BaseBranch == "" {
+		repo.BaseBranch, err = repo.DetectBaseBranch()
+		if err != nil {
+			log.Printf("Error detecting base branch, using %s: %v", repository.DefaultBaseBranch, err)
+		}
+	}
+
 	log.Printf("Getting repo status for %s", repo.Path)
 
 	updateRepo(repo)
@@ -149,6 +263,150 @@ func HandleAddRepository(w http.ResponseWriter, r *http.Request) {
 	log.Printf("Repository added successfully")
 }
 
+func HandleEditRepository(w http.ResponseWriter, r *http.Request) {
+	var req RepoEditRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	repo, err := getRepository(req.Path)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	if req.MaxConcurrency != nil && *req.MaxConcurrency < 0 {
+		http.Error(w, "maxConcurrency must not be negative", http.StatusBadRequest)
+		return
+	}
+	var commentMode repository.CommentMode
+	if req.CommentMode != nil {
+		commentMode, err = repository.ParseCommentMode(*req.CommentMode)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	var branchUpdate repository.BranchUpdate
+	if req.BranchUpdate != nil {
+		branchUpdate, err = repository.ParseBranchUpdate(*req.BranchUpdate)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	var workflow string
+	if req.Workflow != nil {
+		workflow = strings.TrimSpace(*req.Workflow)
+		state.State.Mu.RLock()
+		_, ok := state.State.Workflows[workflow]
+		state.State.Mu.RUnlock()
+		if workflow != "" && !ok {
+			http.Error(w, fmt.Sprintf("unknown workflow: %s", workflow), http.StatusBadRequest)
+			return
+		}
+	}
+	var authorization repository.AuthorizationRules
+	if req.Authorization != nil {
+		authorization, err = req.Authorization.Normalize()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	var commits repository.CommitSettings
+	if req.Commits != nil {
+		commits, err = req.Commits.Normalize()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	var authSettings auth.Settings
+	if req.Auth != nil {
+		authSettings, err = req.Auth.Normalize()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		repo.Mu.RLock()
+		if authSettings.Passphrase == "" {
+			authSettings.Passphrase = repo.Auth.Passphrase
+		}
+		if authSettings.Token == "" {
+			authSettings.Token = repo.Auth.Token
+		}
+		repo.Mu.RUnlock()
+	}
+
+	repo.Mu.Lock()
+	if req.BaseBranch != nil {
+		repo.BaseBranch = strings.TrimSpace(*req.BaseBranch)
+	}
+	if req.MaxConcurrency != nil {
+		repo.MaxConcurrency = *req.MaxConcurrency
+	}
+	if req.CommentMode != nil {
+		repo.CommentMode = commentMode
+	}
+	if req.ReplyToComments != nil {
+		repo.ReplyToComments = *req.ReplyToComments
+	}
+	if req.Authorization != nil {
+		repo.Authorization = authorization
+	}
+	if req.Labels != nil {
+		repo.Labels = req.Labels.Normalize()
+	}
+	if req.BranchUpdate != nil {
+		repo.BranchUpdate = branchUpdate
+	}
+	if req.ConflictWorkflow != nil {
+		repo.ConflictWorkflow = strings.TrimSpace(*req.ConflictWorkflow)
+	}
+	if req.DeleteBranches != nil {
+		repo.DeleteBranches = *req.DeleteBranches
+	}
+	if req.Workflow != nil {
+		repo.Workflow = workflow
+	}
+	if req.DryRun != nil {
+		repo.DryRun = *req.DryRun
+	}
+	if req.Commits != nil {
+		repo.Commits = commits
+	}
+	repo.Mu.Unlock()
+
+	if req.Auth != nil {
+		if err := repo.SetAuth(authSettings); err != nil {
+			http.Error(w, fmt.Sprintf("Error changing the auth settings: %v", err), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	// Save config
+	configPath, err := config.GetHomeConfigPath()
+	if err != nil {
+		log.Printf("Error getting config path: %v", err)
+		http.Error(w, fmt.Sprintf("Error getting config path: %v", err), http.StatusInternalServerError)
+		return
+	}
+	err = config.SaveConfig(configPath)
+	if err != nil {
+		log.Printf("Error saving config: %v", err)
+		http.Error(w, fmt.Sprintf("Error saving config: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	err = json.NewEncoder(w).Encode(newRepositoryView(repo))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
 func HandleUpdateRepository(w http.ResponseWriter, r *http.Request) {
 	var req struct {
 		Path string `json:"path"`
@@ -181,8 +439,10 @@ func HandleUpdateRepository(w http.ResponseWriter, r *http.Request) {
 
 func HandleCloneRepository(w http.ResponseWriter, r *http.Request) {
 	var req struct {
-		RepoURL  string `json:"repoUrl"`
-		BasePath string `json:"basePath"`
+		RepoURL    string        `json:"repoUrl"`
+		BasePath   string        `json:"basePath"`
+		BaseBranch string        `json:"baseBranch"`
+		Auth       auth.Settings `json:"auth"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
@@ -202,9 +462,18 @@ func HandleCloneRepository(w http.ResponseWriter, r *http.Request) {
 
 	// Clone the repository
 	repoName := strings.TrimPrefix(req.RepoURL, "https://github.com/")
+	repoName = strings.TrimPrefix(repoName, "git@github.com:")
 	repoName = strings.TrimSuffix(repoName, ".git")
 	repoPath := filepath.Join(req.BasePath, repoName)
 	repo := repository.NewRepository(repoPath)
+	repo.BaseBranch = req.BaseBranch
+	repo.Auth = req.Auth
+	if strings.Contains(req.RepoURL, "github.com") {
+		// clone with the GitHub credentials when https auth has no token
+		state.State.Mu.RLock()
+		repo.Remote = state.State.Remote.GitHub
+		state.State.Mu.RUnlock()
+	}
 	if err := repo.Upsert(req.RepoURL); err != nil {
 		http.Error(w, fmt.Sprintf("Error cloning repository: %v", err), http.StatusInternalServerError)
 		return
@@ -232,6 +501,12 @@ func HandleDeleteRepository(w http.ResponseWriter, r *http.Request) {
 	state.State.Scheduler.RemoveTask(repo.Path)
 	state.State.Mu.Unlock()
 
+	if repo.WorkState != nil {
+		if err := repo.WorkState.RemoveRepository(repo.Path); err != nil {
+			log.Printf("Error removing work state: %v", err)
+		}
+	}
+
 	// Create config path
 	configPath, err := config.GetHomeConfigPath()
 	if err != nil {
@@ -262,13 +537,145 @@ func HandleSyncRepository(w http.ResponseWriter, r *http.Request) {
 	}
 
 	defaultWorkflow := state.State.Workflows["default"]
-	err = repo.Sync(state.State.Agents, defaultWorkflow)
+	if r.URL.Query().Get("dryRun") == "true" {
+		err = repo.SyncDryRun(state.State.Agents, defaultWorkflow)
+	} else {
+		err = repo.Sync(state.State.Agents, defaultWorkflow)
+	}
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
+	// failing issues don't fail the sync, they are listed in the report
+	err = json.NewEncoder(w).Encode(repo.LastSyncReport())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func HandleGetDryRuns(w http.ResponseWriter, r *http.Request) {
+	path := r.URL.Query().Get("path")
+	if path == "" {
+		http.Error(w, "Repository path is required", http.StatusBadRequest)
+		return
+	}
+
+	repo, err := getRepository(path)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	dryRuns, err := repo.DryRuns()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	err = json.NewEncoder(w).Encode(dryRuns)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func HandleGetIssueQueue(w http.ResponseWriter, r *http.Request) {
+	path := r.URL.Query().Get("path")
+	if path == "" {
+		http.Error(w, "Repository path is required", http.StatusBadRequest)
+		return
+	}
+
+	repo, err := getRepository(path)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	err = json.NewEncoder(w).Encode(newIssueQueueView(repo))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func HandleSetIssueQueue(w http.ResponseWriter, r *http.Request) {
+	var req RepoQueueRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	repo, err := getRepository(req.Path)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	var policy repository.SchedulingPolicy
+	if req.Policy != nil {
+		policy, err = repository.ParseSchedulingPolicy(*req.Policy)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	seen := make(map[int]bool, len(req.Queue))
+	for _, number := range req.Queue {
+		if number <= 0 || seen[number] {
+			http.Error(w, fmt.Sprintf("invalid or duplicate issue number in queue: %d", number), http.StatusBadRequest)
+			return
+		}
+		seen[number] = true
+	}
+
+	repo.Mu.Lock()
+	if req.Policy != nil {
+		repo.SchedulingPolicy = policy
+	}
+	if req.Queue != nil {
+		repo.Queue = req.Queue
+	}
+	repo.Mu.Unlock()
+
+	// Save config
+	configPath, err := config.GetHomeConfigPath()
+	if err != nil {
+		log.Printf("Error getting config path: %v", err)
+		http.Error(w, fmt.Sprintf("Error getting config path: %v", err), http.StatusInternalServerError)
+		return
+	}
+	err = config.SaveConfig(configPath)
+	if err != nil {
+		log.Printf("Error saving config: %v", err)
+		http.Error(w, fmt.Sprintf("Error saving config: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	err = json.NewEncoder(w).Encode(newIssueQueueView(repo))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func newIssueQueueView(repo *repository.Repository) issueQueueView {
+	repo.Mu.RLock()
+	policy := repo.SchedulingPolicy
+	queue := slices.Clone(repo.Queue)
+	repo.Mu.RUnlock()
+	if policy == "" {
+		policy = repository.DefaultSchedulingPolicy
+	}
+	if queue == nil {
+		queue = []int{}
+	}
+	return issueQueueView{
+		Policy: policy,
+		Queue:  queue,
+		Issues: repo.IssueQueue(),
+	}
 }
 
 func HandleSwitchProvider(w http.ResponseWriter, r *http.Request) {
@@ -290,7 +697,29 @@ func HandleSwitchProvider(w http.ResponseWriter, r *http.Request) {
 	// Update the provider
 	repo.RemoteProvider.Provider = req.Provider
 	repo.RemoteProvider.Path = req.Path
-	repo.RemoteProvider.Token = state.State.Settings.GitHubToken
+	repo.RemoteProvider.Server = ""
+	repo.RemoteProvider.App = nil
+	state.State.Mu.RLock()
+	switch req.Provider {
+	case remote.ProviderTypeToString(remote.GITHUB):
+		// remote providers address the repository by its remote path
+		repo.RemoteProvider.Path = repo.RemotePath
+		repo.RemoteProvider.Token = state.State.Settings.GitHubToken
+		if app := state.State.Settings.GitHubApp; app.Enabled() {
+			repo.RemoteProvider.App = &app
+		}
+	case remote.ProviderTypeToString(remote.GITLAB):
+		repo.RemoteProvider.Path = repo.RemotePath
+		repo.RemoteProvider.Token = state.State.Settings.GitLabToken
+		repo.RemoteProvider.Server = state.State.Settings.GitLabURL
+	case remote.ProviderTypeToString(remote.GITEA):
+		repo.RemoteProvider.Path = repo.RemotePath
+		repo.RemoteProvider.Token = state.State.Settings.GiteaToken
+		repo.RemoteProvider.Server = state.State.Settings.GiteaURL
+	default:
+		repo.RemoteProvider.Token = ""
+	}
+	state.State.Mu.RUnlock()
 
 	// Create new remote provider
 	options, err := remote.SettingsToOptions(repo.RemoteProvider)
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mule-ai/mule/internal/state"
+	"github.com/mule-ai/mule/pkg/remote/github"
+	"github.com/mule-ai/mule/pkg/repository"
+)
+
+var secrets = []string{"provider-token", "private-key", "key-passphrase", "https-token", "signing-key"}
+
+// withSecretRepository tracks a repository with every secret set
+func withSecretRepository(t *testing.T) *repository.Repository {
+	t.Helper()
+	repo := repository.NewRepository("/repos/mule")
+	repo.RemoteProvider.Token = "provider-token"
+	repo.RemoteProvider.App = &github.AppCredentials{AppID: 42, InstallationID: 99, PrivateKey: "private-key"}
+	repo.Auth.Passphrase = "key-passphrase"
+	repo.Auth.Token = "https-token"
+	repo.Commits.SigningKey = "signing-key"
+
+	previous := state.State
+	state.State = &state.AppState{Repositories: map[string]*repository.Repository{repo.Path: repo}}
+	t.Cleanup(func() { state.State = previous })
+	return repo
+}
+
+func TestListRepositoriesLeavesSecretsOut(t *testing.T) {
+	repo := withSecretRepository(t)
+
+	w := httptest.NewRecorder()
+	HandleListRepositories(w, httptest.NewRequest(http.MethodGet, "/api/repositories", nil))
+	body := w.Body.String()
+	for _, secret := range secrets {
+		if strings.Contains(body, secret) {
+			t.Errorf("expected %s to be left out of the list", secret)
+		}
+	}
+	if !strings.Contains(body, `"appId":42`) {
+		t.Errorf("expected the other settings to be listed, got %s", body)
+	}
+	if repo.RemoteProvider.App.PrivateKey != "private-key" || repo.Auth.Token != "https-token" {
+		t.Errorf("expected the settings of the repository to be left alone")
+	}
+}
+
+func TestEditRepositoryLeavesSecretsOut(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	repo := withSecretRepository(t)
+
+	w := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodPut, "/api/repositories", strings.NewReader(`{"path": "/repos/mule", "maxConcurrency": 2}`))
+	HandleEditRepository(w, request)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected the edit to succeed, got %d: %s", w.Code, w.Body.String())
+	}
+	body := w.Body.String()
+	for _, secret := range secrets {
+		if strings.Contains(body, secret) {
+			t.Errorf("expected %s to be left out of the edit response", secret)
+		}
+	}
+	if !strings.Contains(body, `"maxConcurrency":2`) || repo.Auth.Token != "https-token" {
+		t.Errorf("expected the edited settings in the response and the secrets kept, got %s", body)
+	}
+}
@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/google/go-github/v60/github"
+
+	"github.com/mule-ai/mule/internal/state"
+	"github.com/mule-ai/mule/internal/webhook"
+	"github.com/mule-ai/mule/pkg/remote"
+	"github.com/mule-ai/mule/pkg/repository"
+)
+
+/*
+HandleGitHubWebhook receives GitHub webhook deliveries. The signature of every
+delivery is checked against the configured webhook secret before the event is
+parsed. Events that touch an issue or a pull request of a tracked repository
+queue a targeted sync of just that issue or pull request, the scheduled sync
+stays in place to reconcile anything a delivery missed.
+*/
+
+var webhookQueue *webhook.Queue
+
+func InitWebhooks(l logr.Logger) {
+	webhookQueue = webhook.NewQueue(l, 100, syncWebhookTarget)
+	webhookQueue.Start()
+}
+
+func HandleGitHubWebhook(w http.ResponseWriter, r *http.Request) {
+	state.State.Mu.RLock()
+	secret := state.State.Settings.GitHubWebhookSecret
+	state.State.Mu.RUnlock()
+
+	// never accept unsigned deliveries
+	if secret == "" {
+		http.Error(w, "GitHub webhook secret is not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	payload, err := github.ValidatePayload(r, []byte(secret))
+	if err != nil {
+		log.Printf("Rejected webhook delivery: %v", err)
+		http.Error(w, "Invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	event, err := github.ParseWebHook(github.WebHookType(r), payload)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error parsing event: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	fullName, target, ok := webhookTarget(event)
+	if !ok {
+		// pings and events mule does not act on
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	var repo *repository.Repository
+	target.RepoPath, repo, ok = repositoryForRemotePath(fullName)
+	if !ok {
+		log.Printf("Ignoring webhook for untracked repository %s", fullName)
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	// the comments and labels mule adds would otherwise queue the next sync
+	if causedByMule(event, repo) {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if webhookQueue == nil {
+		http.Error(w, "Webhook queue is not running", http.StatusServiceUnavailable)
+		return
+	}
+	webhookQueue.Enqueue(target)
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// webhookTarget returns the repository and the issue or pull request an event
+// is about, ok is false for events that do not need a sync
+func webhookTarget(event any) (string, webhook.Target, bool) {
+	switch e := event.(type) {
+	case *github.IssuesEvent:
+		switch e.GetAction() {
+		case "opened", "edited", "reopened", "labeled", "unlabeled":
+			return e.GetRepo().GetFullName(), webhook.Target{Issue: e.GetIssue().GetNumber()}, true
+		}
+	case *github.IssueCommentEvent:
+		switch e.GetAction() {
+		case "created", "edited":
+			// conversation comments on pull requests arrive as issue comments
+			if e.GetIssue().IsPullRequest() {
+				return e.GetRepo().GetFullName(), webhook.Target{PullRequest: e.GetIssue().GetNumber()}, true
+			}
+			return e.GetRepo().GetFullName(), webhook.Target{Issue: e.GetIssue().GetNumber()}, true
+		}
+	case *github.PullRequestReviewCommentEvent:
+		switch e.GetAction() {
+		case "created", "edited":
+			return e.GetRepo().GetFullName(), webhook.Target{PullRequest: e.GetPullRequest().GetNumber()}, true
+		}
+	case *github.PullRequestEvent:
+		switch e.GetAction() {
+		case "opened", "edited", "reopened", "synchronize", "ready_for_review":
+			return e.GetRepo().GetFullName(), webhook.Target{PullRequest: e.GetPullRequest().GetNumber()}, true
+		}
+	}
+	return "", webhook.Target{}, false
+}
+
+// causedByMule reports whether mule's own account sent the event, or whether
+// it is about a label mule sets itself
+func causedByMule(event any, repo *repository.Repository) bool {
+	if e, ok := event.(*github.IssuesEvent); ok && repo.MuleLabel(e.GetLabel().GetName()) {
+		return true
+	}
+	if e, ok := event.(interface{ GetSender() *github.User }); ok {
+		return repo.IsMule(e.GetSender().GetLogin())
+	}
+	return false
+}
+
+// repositoryForRemotePath finds the tracked GitHub repository with the given
+// owner/name and returns it with its local path
+func repositoryForRemotePath(fullName string) (string, *repository.Repository, bool) {
+	state.State.Mu.RLock()
+	defer state.State.Mu.RUnlock()
+
+	for path, repo := range state.State.Repositories {
+		if repo.RemoteProvider.Provider != remote.ProviderTypeToString(remote.GITHUB) {
+			continue
+		}
+		if strings.EqualFold(repo.RemotePath, fullName) {
+			return path, repo, true
+		}
+	}
+	return "", nil, false
+}
+
+func syncWebhookTarget(target webhook.Target) error {
+	repo, err := getRepository(target.RepoPath)
+	if err != nil {
+		return err
+	}
+
+	state.State.Mu.RLock()
+	agents := state.State.Agents
+	defaultWorkflow := state.State.Workflows["default"]
+	state.State.Mu.RUnlock()
+
+	if target.PullRequest != 0 {
+		return repo.SyncPullRequest(agents, defaultWorkflow, target.PullRequest)
+	}
+	return repo.SyncIssue(agents, defaultWorkflow, target.Issue)
+}
@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/mule-ai/mule/internal/settings"
+	"github.com/mule-ai/mule/internal/state"
+	"github.com/mule-ai/mule/internal/webhook"
+	"github.com/mule-ai/mule/pkg/remote"
+	"github.com/mule-ai/mule/pkg/remote/local"
+	"github.com/mule-ai/mule/pkg/repository"
+)
+
+const testWebhookSecret = "secret"
+
+func setupWebhookTest(t *testing.T) chan webhook.Target {
+	t.Helper()
+	repo := repository.NewRepository("/repos/mule")
+	repo.RemotePath = "mule-ai/mule"
+	repo.RemoteProvider.Provider = remote.ProviderTypeToString(remote.GITHUB)
+
+	previous := state.State
+	state.State = &state.AppState{
+		Repositories: map[string]*repository.Repository{repo.Path: repo},
+		Settings:     settings.Settings{GitHubWebhookSecret: testWebhookSecret},
+	}
+
+	synced := make(chan webhook.Target, 10)
+	webhookQueue = webhook.NewQueue(logr.Discard(), 10, func(target webhook.Target) error {
+		synced <- target
+		return nil
+	})
+	webhookQueue.Start()
+
+	t.Cleanup(func() {
+		state.State = previous
+		webhookQueue = nil
+	})
+	return synced
+}
+
+func webhookRequest(event, payload, secret string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/api/webhooks/github", strings.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-GitHub-Event", event)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(payload))
+	req.Header.Set("X-Hub-Signature-256", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	return req
+}
+
+func TestGitHubWebhookQueuesTargetedSync(t *testing.T) {
+	tests := []struct {
+		name    string
+		event   string
+		payload string
+		want    webhook.Target
+	}{
+		{
+			name:    "issue opened",
+			event:   "issues",
+			payload: `{"action": "opened", "issue": {"number": 4}, "repository": {"full_name": "mule-ai/mule"}}`,
+			want:    webhook.Target{RepoPath: "/repos/mule", Issue: 4},
+		},
+		{
+			name:    "comment on a pull request",
+			event:   "issue_comment",
+			payload: `{"action": "created", "issue": {"number": 5, "pull_request": {"url": "x"}}, "repository": {"full_name": "Mule-AI/Mule"}}`,
+			want:    webhook.Target{RepoPath: "/repos/mule", PullRequest: 5},
+		},
+		{
+			name:    "review comment",
+			event:   "pull_request_review_comment",
+			payload: `{"action": "created", "pull_request": {"number": 6}, "repository": {"full_name": "mule-ai/mule"}}`,
+			want:    webhook.Target{RepoPath: "/repos/mule", PullRequest: 6},
+		},
+		{
+			name:    "pull request pushed",
+			event:   "pull_request",
+			payload: `{"action": "synchronize", "pull_request": {"number": 7}, "repository": {"full_name": "mule-ai/mule"}}`,
+			want:    webhook.Target{RepoPath: "/repos/mule", PullRequest: 7},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			synced := setupWebhookTest(t)
+
+			w := httptest.NewRecorder()
+			HandleGitHubWebhook(w, webhookRequest(tt.event, tt.payload, testWebhookSecret))
+			if w.Code != http.StatusAccepted {
+				t.Fatalf("expected status %d, got %d: %s", http.StatusAccepted, w.Code, w.Body.String())
+			}
+
+			select {
+			case got := <-synced:
+				if got != tt.want {
+					t.Errorf("expected target %+v, got %+v", tt.want, got)
+				}
+			case <-time.After(time.Second):
+				t.Fatalf("expected a targeted sync to be queued")
+			}
+		})
+	}
+}
+
+func TestGitHubWebhookRejectsInvalidSignature(t *testing.T) {
+	setupWebhookTest(t)
+
+	payload := `{"action": "opened", "issue": {"number": 4}, "repository": {"full_name": "mule-ai/mule"}}`
+	w := httptest.NewRecorder()
+	HandleGitHubWebhook(w, webhookRequest("issues", payload, "wrong"))
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("expected status %d, got %d", http.StatusUnauthorized, w.Code)
+	}
+}
+
+func TestGitHubWebhookRequiresSecret(t *testing.T) {
+	setupWebhookTest(t)
+	state.State.Settings.GitHubWebhookSecret = ""
+
+	payload := `{"zen": "hello"}`
+	w := httptest.NewRecorder()
+	HandleGitHubWebhook(w, webhookRequest("ping", payload, ""))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected status %d, got %d", http.StatusServiceUnavailable, w.Code)
+	}
+}
+
+func TestGitHubWebhookIgnoresUnhandledEvents(t *testing.T) {
+	synced := setupWebhookTest(t)
+
+	for event, payload := range map[string]string{
+		"ping":         `{"zen": "hello"}`,
+		"issues":       `{"action": "closed", "issue": {"number": 4}, "repository": {"full_name": "mule-ai/mule"}}`,
+		"pull_request": `{"action": "opened", "pull_request": {"number": 7}, "repository": {"full_name": "other/repo"}}`,
+	} {
+		w := httptest.NewRecorder()
+		HandleGitHubWebhook(w, webhookRequest(event, payload, testWebhookSecret))
+		if w.Code != http.StatusOK {
+			t.Errorf("expected status %d for %s, got %d", http.StatusOK, event, w.Code)
+		}
+	}
+
+	select {
+	case got := <-synced:
+		t.Errorf("expected no sync to be queued, got %+v", got)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestGitHubWebhookIgnoresEventsOfMule(t *testing.T) {
+	synced := setupWebhookTest(t)
+	repo := state.State.Repositories["/repos/mule"]
+	repo.Remote = &local.Provider{}
+	repo.Labels.InProgress = "mule:working"
+
+	for event, payload := range map[string]string{
+		"issue_comment": `{"action": "created", "issue": {"number": 4}, "sender": {"login": "mule"}, "repository": {"full_name": "mule-ai/mule"}}`,
+		"issues":        `{"action": "labeled", "issue": {"number": 4}, "label": {"name": "mule:working"}, "sender": {"login": "alice"}, "repository": {"full_name": "mule-ai/mule"}}`,
+	} {
+		w := httptest.NewRecorder()
+		HandleGitHubWebhook(w, webhookRequest(event, payload, testWebhookSecret))
+		if w.Code != http.StatusOK {
+			t.Errorf("expected status %d for %s, got %d", http.StatusOK, event, w.Code)
+		}
+	}
+	select {
+	case got := <-synced:
+		t.Fatalf("expected no sync to be queued, got %+v", got)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	payload := `{"action": "labeled", "issue": {"number": 4}, "label": {"name": "mule"}, "sender": {"login": "alice"}, "repository": {"full_name": "mule-ai/mule"}}`
+	w := httptest.NewRecorder()
+	HandleGitHubWebhook(w, webhookRequest("issues", payload, testWebhookSecret))
+	if w.Code != http.StatusAccepted {
+		t.Errorf("expected the trigger label to queue a sync, got status %d", w.Code)
+	}
+}
@@ -3,6 +3,7 @@ package settings
 import (
 	"github.com/mule-ai/mule/pkg/agent"
 	"github.com/mule-ai/mule/pkg/integration"
+	"github.com/mule-ai/mule/pkg/remote/github"
 )
 
 // Understood 
@@ -15,12 +16,18 @@ const (
 )
 
 type Settings struct {
-	GitHubToken string                   `json:"githubToken"`
-	AIProviders []AIProviderSettings     `json:"aiProviders"`
-	Agents      []agent.AgentOptions     `json:"agents"`
-	SystemAgent SystemAgentSettings      `json:"systemAgent"`
-	Workflows   []agent.WorkflowSettings `json:"workflows"`
-	Integration integration.Settings     `json:"integration"`
+	GitHubToken         string                   `json:"githubToken"`
+	GitHubApp           github.AppCredentials    `json:"githubApp"`
+	GitHubWebhookSecret string                   `json:"githubWebhookSecret"`
+	GitLabToken         string                   `json:"gitlabToken"`
+	GitLabURL           string                   `json:"gitlabUrl"`
+	GiteaToken          string                   `json:"giteaToken"`
+	GiteaURL            string                   `json:"giteaUrl"`
+	AIProviders         []AIProviderSettings     `json:"aiProviders"`
+	Agents              []agent.AgentOptions     `json:"agents"`
+	SystemAgent         SystemAgentSettings      `json:"systemAgent"`
+	Workflows           []agent.WorkflowSettings `json:"workflows"`
+	Integration         integration.Settings     `json:"integration"`
 }
 
 type TriggerSettings struct {
@@ -27,6 +27,7 @@ type AppState struct {
 	Remote       *RemoteProviders
 	Agents       map[int]*agent.Agent
 	RAG          *rag.Store
+	WorkState    *repository.WorkStateStore
 	Workflows    map[string]*agent.Workflow
 	Integrations map[string]integration.Integration
 }
@@ -56,6 +57,7 @@ func NewState(logger logr.Logger, settings settings.Settings) *AppState {
 			GitHub: remote.New(remote.ProviderOptions{
 				Type:        remote.GITHUB,
 				GitHubToken: settings.GitHubToken,
+				GitHubApp:   settings.GitHubApp,
 			}),
 			Local: remote.New(remote.ProviderOptions{
 				Type: remote.LOCAL,
@@ -64,6 +66,7 @@ func NewState(logger logr.Logger, settings settings.Settings) *AppState {
 		},
 		Agents:       agents,
 		RAG:          rag,
+		WorkState:    repository.NewWorkStateStore(""),
 		Workflows:    workflows,
 		Integrations: integrations,
 	}
@@ -206,6 +209,9 @@ func (s *AppState) UpdateWorkflows() error {
 	// Update the scheduler with the new workflows.
 	for repoPath, repo := range s.Repositories {
 		s.Scheduler.RemoveTask(repoPath)
+		repo.Mu.Lock()
+		repo.Workflows = workflows
+		repo.Mu.Unlock()
 
 		defaultWorkflow := s.Workflows["default"]
 		err := s.Scheduler.AddTask(repoPath, repo.Schedule, func() {
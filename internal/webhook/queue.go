@@ -0,0 +1,88 @@
+package webhook
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/mule-ai/mule/pkg/repository"
+)
+
+// Target is the part of a repository a webhook event asks to be synced. Only
+// one of Issue and PullRequest is set.
+type Target struct {
+	RepoPath    string
+	Issue       int
+	PullRequest int
+}
+
+// SyncFunc runs a targeted sync
+type SyncFunc func(target Target) error
+
+// Queue runs targeted syncs one at a time in the background, so webhook
+// deliveries can be acknowledged straight away. A target that is already
+// waiting is not queued twice, and a target whose repository is busy with
+// another sync is retried after RetryDelay.
+type Queue struct {
+	RetryDelay time.Duration
+	Logger     logr.Logger
+	targets    chan Target
+	pending    map[Target]struct{}
+	mu         sync.Mutex
+	sync       SyncFunc
+}
+
+func NewQueue(l logr.Logger, size int, sync SyncFunc) *Queue {
+	return &Queue{
+		RetryDelay: 30 * time.Second,
+		Logger:     l,
+		targets:    make(chan Target, size),
+		pending:    make(map[Target]struct{}),
+		sync:       sync,
+	}
+}
+
+func (q *Queue) Start() {
+	go q.run()
+}
+
+// Enqueue adds a target to the queue and reports whether it was added. When
+// the queue is full the target is dropped, the scheduled sync of the
+// repository picks the change up later.
+func (q *Queue) Enqueue(target Target) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if _, exists := q.pending[target]; exists {
+		return false
+	}
+	select {
+	case q.targets <- target:
+		q.pending[target] = struct{}{}
+		return true
+	default:
+		q.Logger.Info("Sync queue is full, dropping target", "path", target.RepoPath, "issue", target.Issue, "pullRequest", target.PullRequest)
+		return false
+	}
+}
+
+func (q *Queue) run() {
+	for target := range q.targets {
+		q.mu.Lock()
+		delete(q.pending, target)
+		q.mu.Unlock()
+
+		err := q.sync(target)
+		if errors.Is(err, repository.ErrRepositoryLocked) {
+			q.Logger.Info("Repository is busy, retrying later", "path", target.RepoPath)
+			time.AfterFunc(q.RetryDelay, func() {
+				q.Enqueue(target)
+			})
+			continue
+		}
+		if err != nil {
+			q.Logger.Error(err, "Error running targeted sync", "path", target.RepoPath, "issue", target.Issue, "pullRequest", target.PullRequest)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package webhook
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/mule-ai/mule/pkg/repository"
+)
+
+func TestQueueDeduplicatesPendingTargets(t *testing.T) {
+	q := NewQueue(logr.Discard(), 10, func(target Target) error { return nil })
+
+	target := Target{RepoPath: "/repo", Issue: 1}
+	if !q.Enqueue(target) {
+		t.Fatalf("expected the first target to be queued")
+	}
+	if q.Enqueue(target) {
+		t.Errorf("expected a pending target not to be queued twice")
+	}
+	if !q.Enqueue(Target{RepoPath: "/repo", PullRequest: 1}) {
+		t.Errorf("expected a different target to be queued")
+	}
+}
+
+func TestQueueDropsWhenFull(t *testing.T) {
+	q := NewQueue(logr.Discard(), 1, func(target Target) error { return nil })
+
+	if !q.Enqueue(Target{RepoPath: "/repo", Issue: 1}) {
+		t.Fatalf("expected the first target to be queued")
+	}
+	if q.Enqueue(Target{RepoPath: "/repo", Issue: 2}) {
+		t.Errorf("expected the target to be dropped when the queue is full")
+	}
+}
+
+func TestQueueRetriesLockedRepository(t *testing.T) {
+	synced := make(chan Target, 10)
+	attempts := 0
+	q := NewQueue(logr.Discard(), 10, func(target Target) error {
+		attempts++
+		if attempts == 1 {
+			return repository.ErrRepositoryLocked
+		}
+		synced <- target
+		return nil
+	})
+	q.RetryDelay = 10 * time.Millisecond
+	q.Start()
+
+	target := Target{RepoPath: "/repo", Issue: 3}
+	q.Enqueue(target)
+
+	select {
+	case got := <-synced:
+		if got != target {
+			t.Errorf("expected %v to be synced, got %v", target, got)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("expected the locked target to be retried")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"html/template"
+	"maps"
 	"reflect"
 	"slices"
 	"strings"
@@ -32,6 +33,8 @@ type Agent struct {
 	path           string
 	rag            *rag.Store
 	udiffSettings  UDiffSettings
+	// instructions of the repository, put in front of every prompt
+	instructions string
 }
 
 type AgentOptions struct {
@@ -52,12 +55,35 @@ type AgentOptions struct {
 type PromptInput struct {
 	IssueTitle        string `json:"issueTitle"`
 	IssueBody         string `json:"issueBody"`
+	IssueComments     string `json:"issueComments"`
 	Commits           string `json:"commits"`
 	Diff              string `json:"diff"`
 	IsPRComment       bool   `json:"isPRComment"`
 	PRComment         string `json:"prComment"`
 	PRCommentDiffHunk string `json:"prCommentDiffHunk"`
-	Message           string `json:"message"`
+	// PRCommentKind is empty for inline review comments, "conversation" or
+	// "review" for general feedback on the pull request
+	PRCommentKind string `json:"prCommentKind"`
+	// ReviewComments are all unresolved review comments of the pull request
+	// when they are addressed in one pass, PRComment then lists them
+	ReviewComments []ReviewComment `json:"reviewComments"`
+	Message        string          `json:"message"`
+	// Conflicts are the conflicting parts of the files when a pull request
+	// is updated with its base branch, PRComment then asks to resolve them
+	Conflicts string `json:"conflicts"`
+}
+
+// ReviewComment is a review comment on a line of a pull request
+type ReviewComment struct {
+	ID       int64  `json:"id"`
+	Path     string `json:"path"`
+	Line     int    `json:"line"`
+	DiffHunk string `json:"diffHunk"`
+	Body     string `json:"body"`
+	// Kind and ReviewState are set for general feedback, see
+	// PromptInput.PRCommentKind
+	Kind        string `json:"kind"`
+	ReviewState string `json:"reviewState"`
 }
 
 func NewAgent(opts AgentOptions) *Agent {
@@ -81,6 +107,28 @@ func NewAgent(opts AgentOptions) *Agent {
 	return agent
 }
 
+// Clone returns a copy of the agent with its own tool options, so copies can
+// work in different paths at the same time
+func (a *Agent) Clone() *Agent {
+	clone := *a
+	clone.tools = make([]*tools.Tool, len(a.tools))
+	for i, tool := range a.tools {
+		toolCopy := *tool
+		toolCopy.Options = maps.Clone(tool.Options)
+		clone.tools[i] = &toolCopy
+	}
+	return &clone
+}
+
+// CloneAgents clones every agent of the map, see Clone
+func CloneAgents(agents map[int]*Agent) map[int]*Agent {
+	clones := make(map[int]*Agent, len(agents))
+	for id, a := range agents {
+		clones[id] = a.Clone()
+	}
+	return clones
+}
+
 func (a *Agent) GetID() int {
 	return a.id
 }
@@ -117,6 +165,11 @@ func (a *Agent) SetSystemPrompt(systemPrompt string) {
 	a.systemPrompt = systemPrompt
 }
 
+// SetInstructions sets what the repository asks of every agent working on it
+func (a *Agent) SetInstructions(instructions string) {
+	a.instructions = instructions
+}
+
 func (a *Agent) SetUDiffSettings(settings UDiffSettings) {
 	a.udiffSettings = settings
 }
@@ -287,6 +340,9 @@ func (a *Agent) renderPromptTemplate(input PromptInput) (string, error) {
 	if err != nil {
 		return "", err
 	}
+	if a.instructions != "" {
+		return a.instructions + "\n\n" + rendered.String(), nil
+	}
 	return rendered.String(), nil
 }
 
@@ -86,6 +86,11 @@ func NewWorkflow(settings WorkflowSettings, agentMap map[int]*Agent, logger logr
 	return w
 }
 
+// Name returns the name the workflow was configured with
+func (w *Workflow) Name() string {
+	return w.settings.Name
+}
+
 func (w *Workflow) RegisterTriggers(integrations map[string]integration.Integration) error {
 	for _, trigger := range w.settings.Triggers {
 		integration, ok := integrations[trigger.Integration]
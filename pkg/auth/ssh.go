@@ -51,19 +51,27 @@ import (
 )
 
 func GetSSHAuth() (*ssh.PublicKeys, error) {
+	sshPath, err := defaultKeyPath()
+	if err != nil {
+		return nil, err
+	}
+
+	publicKeys, err := ssh.NewPublicKeysFromFile("git", sshPath, "")
+	if err != nil {
+		return nil, fmt.Errorf("error loading SSH key: %v", err)
+	}
+	return publicKeys, nil
+}
+
+func defaultKeyPath() (string, error) {
 	sshPath := os.Getenv("SSH_KEY_PATH")
 	if sshPath == "" {
 		// Default to standard SSH key location
 		homeDir, err := os.UserHomeDir()
 		if err != nil {
-			return nil, err
+			return "", err
 		}
 		sshPath = filepath.Join(homeDir, ".ssh", "id_rsa")
 	}
-
-	publicKeys, err := ssh.NewPublicKeysFromFile("git", sshPath, "")
-	if err != nil {
-		return nil, fmt.Errorf("error loading SSH key: %v", err)
-	}
-	return publicKeys, nil
+	return sshPath, nil
 }
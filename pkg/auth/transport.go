@@ -0,0 +1,198 @@
+package auth
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
+)
+
+/*
+Settings select how git authenticates against the remote of a repository.
+
+Methods:
+ssh: a private key file, SSH_KEY_PATH or ~/.ssh/id_rsa unless KeyPath is set.
+The key may be protected by Passphrase or the SSH_KEY_PASSPHRASE variable.
+ssh-agent: the keys loaded in the agent listening on SSH_AUTH_SOCK.
+https: basic auth with Token, or with the credentials of the remote provider
+when no token is set. Nothing is needed for public repositories.
+
+SSH host keys are always verified, against KnownHostsPath when set and
+otherwise against SSH_KNOWN_HOSTS or the default known_hosts files.
+An empty method keeps the old behaviour and uses ssh, except for HTTPS remotes
+of a provider with its own credentials, like a GitHub App.
+*/
+const (
+	MethodSSH      = "ssh"
+	MethodSSHAgent = "ssh-agent"
+	MethodHTTPS    = "https"
+)
+
+type Settings struct {
+	Method         string `json:"method,omitempty"`
+	KeyPath        string `json:"keyPath,omitempty"`
+	Passphrase     string `json:"passphrase,omitempty"`
+	KnownHostsPath string `json:"knownHostsPath,omitempty"`
+	Username       string `json:"username,omitempty"`
+	Token          string `json:"token,omitempty"`
+}
+
+// CredentialsFunc returns the HTTPS username and password of a remote
+// provider
+type CredentialsFunc func() (username, password string, err error)
+
+// Normalize trims the settings and checks the method
+func (s Settings) Normalize() (Settings, error) {
+	normalized := Settings{
+		Method:         strings.ToLower(strings.TrimSpace(s.Method)),
+		KeyPath:        strings.TrimSpace(s.KeyPath),
+		Passphrase:     s.Passphrase,
+		KnownHostsPath: strings.TrimSpace(s.KnownHostsPath),
+		Username:       strings.TrimSpace(s.Username),
+		Token:          strings.TrimSpace(s.Token),
+	}
+	switch normalized.Method {
+	case "", MethodSSH, MethodSSHAgent, MethodHTTPS:
+		return normalized, nil
+	}
+	return Settings{}, fmt.Errorf("unsupported auth method: %s", s.Method)
+}
+
+// ResolveMethod returns the method used for the remote url, which is only
+// open for an empty method
+func (s Settings) ResolveMethod(remoteURL string, credentials CredentialsFunc) string {
+	if s.Method == "" {
+		if credentials != nil && strings.HasPrefix(remoteURL, "https://") {
+			return MethodHTTPS
+		}
+		return MethodSSH
+	}
+	return s.Method
+}
+
+// RemoteURL converts the remote url to the form the method needs, an SSH url
+// for SSH and an HTTPS url for HTTPS
+func (s Settings) RemoteURL(remoteURL string, credentials CredentialsFunc) string {
+	if s.ResolveMethod(remoteURL, credentials) == MethodHTTPS {
+		return ToHTTPS(remoteURL)
+	}
+	return ToSSH(remoteURL)
+}
+
+// GetAuth returns the transport authentication for the settings and remote
+// url. credentials is used for HTTPS when no token is configured and may be
+// nil.
+func GetAuth(s Settings, remoteURL string, credentials CredentialsFunc) (transport.AuthMethod, error) {
+	switch s.ResolveMethod(remoteURL, credentials) {
+	case MethodSSH:
+		return getSSHKeyAuth(s)
+	case MethodSSHAgent:
+		return getSSHAgentAuth(s)
+	case MethodHTTPS:
+		return getHTTPSAuth(s, credentials)
+	}
+	return nil, fmt.Errorf("unsupported auth method: %s", s.Method)
+}
+
+func getSSHKeyAuth(s Settings) (transport.AuthMethod, error) {
+	keyPath := s.KeyPath
+	if keyPath == "" {
+		var err error
+		keyPath, err = defaultKeyPath()
+		if err != nil {
+			return nil, err
+		}
+	}
+	passphrase := s.Passphrase
+	if passphrase == "" {
+		passphrase = os.Getenv("SSH_KEY_PASSPHRASE")
+	}
+
+	publicKeys, err := ssh.NewPublicKeysFromFile("git", keyPath, passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("error loading SSH key: %v", err)
+	}
+	if err := setKnownHosts(&publicKeys.HostKeyCallbackHelper, s.KnownHostsPath); err != nil {
+		return nil, err
+	}
+	return publicKeys, nil
+}
+
+func getSSHAgentAuth(s Settings) (transport.AuthMethod, error) {
+	agentAuth, err := ssh.NewSSHAgentAuth("git")
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to SSH agent: %v", err)
+	}
+	if err := setKnownHosts(&agentAuth.HostKeyCallbackHelper, s.KnownHostsPath); err != nil {
+		return nil, err
+	}
+	return agentAuth, nil
+}
+
+// setKnownHosts verifies host keys against the given file, without one
+// go-git falls back to the default known_hosts files
+func setKnownHosts(helper *ssh.HostKeyCallbackHelper, knownHostsPath string) error {
+	if knownHostsPath == "" {
+		return nil
+	}
+	callback, err := ssh.NewKnownHostsCallback(knownHostsPath)
+	if err != nil {
+		return fmt.Errorf("error loading known hosts: %v", err)
+	}
+	helper.HostKeyCallback = callback
+	return nil
+}
+
+func getHTTPSAuth(s Settings, credentials CredentialsFunc) (transport.AuthMethod, error) {
+	if s.Token != "" {
+		username := s.Username
+		if username == "" {
+			// the username is ignored by most forges when a token is used
+			username = "git"
+		}
+		return &http.BasicAuth{Username: username, Password: s.Token}, nil
+	}
+	if credentials == nil {
+		// anonymous access, enough for public repositories
+		return nil, nil
+	}
+	username, password, err := credentials()
+	if err != nil {
+		return nil, fmt.Errorf("HTTPS authentication error: %v", err)
+	}
+	if s.Username != "" {
+		username = s.Username
+	}
+	return &http.BasicAuth{Username: username, Password: password}, nil
+}
+
+// ToHTTPS converts an scp-like SSH url (git@host:owner/repo.git) to HTTPS,
+// other urls are returned unchanged
+func ToHTTPS(repoURL string) string {
+	if strings.Contains(repoURL, "://") {
+		return strings.Replace(repoURL, "ssh://git@", "https://", 1)
+	}
+	if user, rest, found := strings.Cut(repoURL, "@"); found && !strings.Contains(user, "/") {
+		host, path, _ := strings.Cut(rest, ":")
+		return "https://" + host + "/" + path
+	}
+	return repoURL
+}
+
+// ToSSH converts an HTTPS url to the scp-like SSH form, other urls are
+// returned unchanged
+func ToSSH(repoURL string) string {
+	rest, found := strings.CutPrefix(repoURL, "https://")
+	if !found {
+		return repoURL
+	}
+	// drop any credentials embedded in the url
+	if _, after, hasUser := strings.Cut(rest, "@"); hasUser {
+		rest = after
+	}
+	host, path, _ := strings.Cut(rest, "/")
+	return "git@" + host + ":" + path
+}
@@ -0,0 +1,205 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	gitssh "github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"golang.org/x/crypto/ssh"
+)
+
+func writeKey(t *testing.T, passphrase string) (string, ssh.PublicKey) {
+	t.Helper()
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("error generating key: %v", err)
+	}
+
+	var block *pem.Block
+	if passphrase == "" {
+		block, err = ssh.MarshalPrivateKey(private, "")
+	} else {
+		block, err = ssh.MarshalPrivateKeyWithPassphrase(private, "", []byte(passphrase))
+	}
+	if err != nil {
+		t.Fatalf("error encoding key: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "id_ed25519")
+	if err := os.WriteFile(path, pem.EncodeToMemory(block), 0600); err != nil {
+		t.Fatalf("error writing key: %v", err)
+	}
+	sshPublic, err := ssh.NewPublicKey(public)
+	if err != nil {
+		t.Fatalf("error converting public key: %v", err)
+	}
+	return path, sshPublic
+}
+
+func TestGetAuthSSHKeyWithPassphrase(t *testing.T) {
+	keyPath, hostKey := writeKey(t, "secret")
+
+	knownHosts := filepath.Join(t.TempDir(), "known_hosts")
+	line := "github.com " + string(ssh.MarshalAuthorizedKey(hostKey))
+	if err := os.WriteFile(knownHosts, []byte(line), 0600); err != nil {
+		t.Fatalf("error writing known hosts: %v", err)
+	}
+
+	method, err := GetAuth(Settings{
+		Method:         MethodSSH,
+		KeyPath:        keyPath,
+		Passphrase:     "secret",
+		KnownHostsPath: knownHosts,
+	}, "git@github.com:mule-ai/mule.git", nil)
+	if err != nil {
+		t.Fatalf("GetAuth returned error: %v", err)
+	}
+	publicKeys, ok := method.(*gitssh.PublicKeys)
+	if !ok {
+		t.Fatalf("expected public key auth, got %T", method)
+	}
+	if publicKeys.HostKeyCallback == nil {
+		t.Fatalf("expected host keys to be verified against the known hosts file")
+	}
+	if err := publicKeys.HostKeyCallback("github.com:22", &fakeAddr{}, hostKey); err != nil {
+		t.Errorf("expected the known host key to be accepted: %v", err)
+	}
+	_, otherKey := writeKey(t, "")
+	if err := publicKeys.HostKeyCallback("github.com:22", &fakeAddr{}, otherKey); err == nil {
+		t.Errorf("expected an unknown host key to be rejected")
+	}
+
+	if _, err := GetAuth(Settings{Method: MethodSSH, KeyPath: keyPath, Passphrase: "wrong"}, "", nil); err == nil {
+		t.Errorf("expected an error for a wrong passphrase")
+	}
+}
+
+func TestGetAuthHTTPS(t *testing.T) {
+	providerCredentials := func() (string, string, error) {
+		return "x-access-token", "provider-token", nil
+	}
+
+	tests := []struct {
+		name        string
+		settings    Settings
+		remoteURL   string
+		credentials CredentialsFunc
+		want        *http.BasicAuth
+	}{
+		{
+			name:      "configured token",
+			settings:  Settings{Method: MethodHTTPS, Token: "repo-token"},
+			remoteURL: "https://github.com/mule-ai/mule.git",
+			want:      &http.BasicAuth{Username: "git", Password: "repo-token"},
+		},
+		{
+			name:        "provider token",
+			settings:    Settings{Method: MethodHTTPS},
+			remoteURL:   "https://github.com/mule-ai/mule.git",
+			credentials: providerCredentials,
+			want:        &http.BasicAuth{Username: "x-access-token", Password: "provider-token"},
+		},
+		{
+			name:        "default method with an https remote",
+			settings:    Settings{},
+			remoteURL:   "https://github.com/mule-ai/mule.git",
+			credentials: providerCredentials,
+			want:        &http.BasicAuth{Username: "x-access-token", Password: "provider-token"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			method, err := GetAuth(tt.settings, tt.remoteURL, tt.credentials)
+			if err != nil {
+				t.Fatalf("GetAuth returned error: %v", err)
+			}
+			basicAuth, ok := method.(*http.BasicAuth)
+			if !ok {
+				t.Fatalf("expected basic auth, got %T", method)
+			}
+			if *basicAuth != *tt.want {
+				t.Errorf("expected %+v, got %+v", tt.want, basicAuth)
+			}
+		})
+	}
+
+	// without any credentials public repositories are accessed anonymously
+	method, err := GetAuth(Settings{Method: MethodHTTPS}, "https://github.com/mule-ai/mule.git", nil)
+	if err != nil || method != nil {
+		t.Errorf("expected anonymous access, got %v, %v", method, err)
+	}
+
+	if _, err := GetAuth(Settings{Method: "ftp"}, "", nil); err == nil {
+		t.Errorf("expected an error for an unsupported method")
+	}
+}
+
+func TestURLConversion(t *testing.T) {
+	tests := []struct {
+		https string
+		ssh   string
+	}{
+		{"https://github.com/mule-ai/mule.git", "git@github.com:mule-ai/mule.git"},
+		{"https://gitlab.example.com/group/sub/project.git", "git@gitlab.example.com:group/sub/project.git"},
+	}
+	for _, tt := range tests {
+		if got := ToSSH(tt.https); got != tt.ssh {
+			t.Errorf("ToSSH(%q) = %q, want %q", tt.https, got, tt.ssh)
+		}
+		if got := ToHTTPS(tt.ssh); got != tt.https {
+			t.Errorf("ToHTTPS(%q) = %q, want %q", tt.ssh, got, tt.https)
+		}
+		// already converted urls are left alone
+		if got := ToSSH(tt.ssh); got != tt.ssh {
+			t.Errorf("ToSSH(%q) = %q, want it unchanged", tt.ssh, got)
+		}
+		if got := ToHTTPS(tt.https); got != tt.https {
+			t.Errorf("ToHTTPS(%q) = %q, want it unchanged", tt.https, got)
+		}
+	}
+}
+
+type fakeAddr struct{}
+
+func (fakeAddr) Network() string { return "tcp" }
+func (fakeAddr) String() string  { return "140.82.112.3:22" }
+
+func TestRemoteURL(t *testing.T) {
+	credentials := func() (string, string, error) { return "x-access-token", "token", nil }
+	httpsURL := "https://github.com/mule-ai/mule.git"
+	sshURL := "git@github.com:mule-ai/mule.git"
+
+	tests := []struct {
+		name        string
+		settings    Settings
+		remoteURL   string
+		credentials CredentialsFunc
+		want        string
+	}{
+		{"default method with provider credentials", Settings{}, httpsURL, credentials, httpsURL},
+		{"default method without provider credentials", Settings{}, httpsURL, nil, sshURL},
+		{"default method with an ssh remote", Settings{}, sshURL, credentials, sshURL},
+		{"ssh", Settings{Method: MethodSSHAgent}, httpsURL, credentials, sshURL},
+		{"https", Settings{Method: MethodHTTPS}, sshURL, nil, httpsURL},
+	}
+	for _, tt := range tests {
+		if got := tt.settings.RemoteURL(tt.remoteURL, tt.credentials); got != tt.want {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	settings, err := Settings{Method: " HTTPS ", Token: " token "}.Normalize()
+	if err != nil || settings.Method != MethodHTTPS || settings.Token != "token" {
+		t.Errorf("unexpected settings: %+v, %v", settings, err)
+	}
+	if _, err := (Settings{Method: "ftp"}).Normalize(); err == nil {
+		t.Errorf("expected an error for an unsupported method")
+	}
+}
@@ -85,18 +85,20 @@ func (s *Store) NewCollection(name string) error {
 	if err != nil {
 		return err
 	}
+	s.mu.Lock()
 	s.Collections[name] = collection
+	s.mu.Unlock()
 	return nil
 }
 
 // creates a new collection in the database for a repo
 func (s *Store) AddRepository(path string) error {
-	collection, ok := s.Collections[path]
+	collection, ok := s.collection(path)
 	if !ok {
 		if err := s.NewCollection(path); err != nil {
 			return fmt.Errorf("error creating collection: %w", err)
 		}
-		collection = s.Collections[path]
+		collection, _ = s.collection(path)
 	}
 	// get all files in the repository
 	files, err := getFiles(path)
@@ -110,17 +112,51 @@ func (s *Store) AddRepository(path string) error {
 		if err != nil {
 			return fmt.Errorf("error adding file to watcher: %w", err)
 		}
+		s.mu.Lock()
 		s.watchedFiles[file] = true
+		s.mu.Unlock()
 	}
 	// chunks files and adds their embeddings to the collection
 	return s.addDocumentsToCollection(s.ctx, collection, files)
 }
 
+// RemoveRepository drops the collection of a repository and stops watching
+// its files, used for short lived checkouts like issue worktrees
+func (s *Store) RemoveRepository(path string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.Collections[path]; !ok {
+		return nil
+	}
+	delete(s.Collections, path)
+	for file := range s.watchedFiles {
+		if strings.HasPrefix(file, path+string(filepath.Separator)) {
+			// the file may already be gone with the checkout
+			_ = s.watcher.Remove(file)
+			delete(s.watchedFiles, file)
+		}
+	}
+	return s.DB.DeleteCollection(path)
+}
+
+// HasRepository reports whether a collection exists for the repository
+func (s *Store) HasRepository(path string) bool {
+	_, ok := s.collection(path)
+	return ok
+}
+
+func (s *Store) collection(path string) (*chromem.Collection, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	collection, ok := s.Collections[path]
+	return collection, ok
+}
+
 func (s *Store) Query(path string, query string, nResults int) (string, error) {
 	if s.Collections == nil {
 		return "", fmt.Errorf("collections not initialized")
 	}
-	collection, ok := s.Collections[path]
+	collection, ok := s.collection(path)
 	if !ok {
 		return "", fmt.Errorf("collection %s not found", path)
 	}
@@ -181,7 +217,7 @@ func (s *Store) GetNResults(path string, query string, nResults int) (Aggregated
 	if s.Collections == nil {
 		return AggregatedResults{}, fmt.Errorf("collections not initialized")
 	}
-	collection, ok := s.Collections[path]
+	collection, ok := s.collection(path)
 	if !ok {
 		return AggregatedResults{}, fmt.Errorf("collection %s not found", path)
 	}
@@ -320,6 +356,8 @@ func (s *Store) checkFiles(path string, collection *chromem.Collection) {
 }
 
 func (s *Store) getCollection(path string) (*chromem.Collection, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 	for name, collection := range s.Collections {
 		if strings.HasPrefix(path, name) {
 			return collection, nil
@@ -0,0 +1,186 @@
+package gitea
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/mule-ai/mule/pkg/remote/types"
+)
+
+type giteaReview struct {
+	ID            int64     `json:"id"`
+	Body          string    `json:"body"`
+	State         string    `json:"state"`
+	User          giteaUser `json:"user"`
+	CommentsCount int       `json:"comments_count"`
+	HTMLURL       string    `json:"html_url"`
+	SubmittedAt   string    `json:"submitted_at"`
+}
+
+type giteaReviewComment struct {
+	ID       int64     `json:"id"`
+	Body     string    `json:"body"`
+	Path     string    `json:"path"`
+	DiffHunk string    `json:"diff_hunk"`
+	Position int       `json:"position"`
+	HTMLURL  string    `json:"html_url"`
+	User     giteaUser `json:"user"`
+}
+
+type giteaReaction struct {
+	User    giteaUser `json:"user"`
+	Content string    `json:"content"`
+}
+
+/*
+FetchComments: Retrieves the review comments of a pull request and their reactions, followed by the comments in its conversation and the review summaries.
+AddCommentReaction: Adds a reaction (like "+1" or "heart") to a comment, review comments included.
+CreatePRComment: Comments on a pull request, as a review comment when a path is given.
+ReplyToComment: Gitea can't answer review comments in their thread, the reply is posted on the pull request.
+ResolveComment: Not supported by the Gitea API.
+*/
+func (p *Provider) FetchComments(owner, repo string, prNumber int) ([]*types.Comment, error) {
+	if owner == "" || repo == "" {
+		owner, repo = p.owner, p.repo
+	}
+	reviews, err := getAll[giteaReview](p, repoEndpoint(owner, repo, "pulls", strconv.Itoa(prNumber), "reviews"), nil)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching reviews: %v", err)
+	}
+
+	var comments, summaries []*types.Comment
+	for _, review := range reviews {
+		// reviews without a summary only carry inline comments
+		if strings.TrimSpace(review.Body) != "" {
+			summaries = append(summaries, &types.Comment{
+				ID:          review.ID,
+				Body:        review.Body,
+				HTMLURL:     review.HTMLURL,
+				UserID:      review.User.ID,
+				Author:      review.User.Login,
+				CreatedAt:   review.SubmittedAt,
+				Kind:        types.CommentKindReview,
+				ReviewState: review.State,
+			})
+		}
+		if review.CommentsCount == 0 {
+			continue
+		}
+		// review comments are not paginated
+		var reviewComments []giteaReviewComment
+		endpoint := repoEndpoint(owner, repo, "pulls", strconv.Itoa(prNumber), "reviews", strconv.FormatInt(review.ID, 10), "comments")
+		if err := p.do(http.MethodGet, endpoint, nil, nil, &reviewComments); err != nil {
+			return nil, fmt.Errorf("error fetching comments: %v", err)
+		}
+
+		for _, comment := range reviewComments {
+			c := &types.Comment{
+				ID:       comment.ID,
+				Body:     comment.Body,
+				DiffHunk: comment.DiffHunk,
+				Path:     comment.Path,
+				Line:     comment.Position,
+				HTMLURL:  comment.HTMLURL,
+				UserID:   comment.User.ID,
+				Author:   comment.User.Login,
+			}
+			reactions, err := p.fetchCommentReactions(owner, repo, comment.ID)
+			if err != nil {
+				return nil, fmt.Errorf("error fetching reactions: %v", err)
+			}
+			c.Reactions = reactions
+			comments = append(comments, c)
+		}
+	}
+
+	// general feedback, the conversation of a pull request is that of its issue
+	conversation, err := p.fetchIssueComments(owner, repo, prNumber)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range conversation {
+		c.Kind = types.CommentKindConversation
+		comments = append(comments, c)
+	}
+	return append(comments, summaries...), nil
+}
+
+func (p *Provider) fetchCommentReactions(owner, repo string, commentID int64) (types.Reactions, error) {
+	var giteaReactions []giteaReaction
+	endpoint := repoEndpoint(owner, repo, "issues", "comments", strconv.FormatInt(commentID, 10), "reactions")
+	if err := p.do(http.MethodGet, endpoint, nil, nil, &giteaReactions); err != nil {
+		return types.Reactions{}, err
+	}
+
+	reactions := types.Reactions{}
+	for _, reaction := range giteaReactions {
+		reactions.TotalCount++
+		switch reaction.Content {
+		case "+1":
+			reactions.PlusOne++
+		case "-1":
+			reactions.MinusOne++
+		case "laugh":
+			reactions.Laugh++
+		case "confused":
+			reactions.Confused++
+		case "heart":
+			reactions.Heart++
+		case "hooray":
+			reactions.Hooray++
+		case "rocket":
+			reactions.Rocket++
+		case "eyes":
+			reactions.Eyes++
+		}
+	}
+	return reactions, nil
+}
+
+func (p *Provider) AddCommentReaction(repoPath, reaction string, commentID int64) error {
+	owner, repo := p.ownerRepo(repoPath)
+	endpoint := repoEndpoint(owner, repo, "issues", "comments", strconv.FormatInt(commentID, 10), "reactions")
+	err := p.do(http.MethodPost, endpoint, nil, map[string]string{"content": reaction}, nil)
+	if err != nil {
+		return fmt.Errorf("error adding reaction: %v", err)
+	}
+	return nil
+}
+
+func (p *Provider) CreatePRComment(remotePath string, prNumber int, comment types.Comment) error {
+	owner, repo := p.ownerRepo(remotePath)
+
+	// without a file position this is a regular comment on the pull request
+	if comment.Path == "" || comment.Line == 0 {
+		endpoint := repoEndpoint(owner, repo, "issues", strconv.Itoa(prNumber), "comments")
+		err := p.do(http.MethodPost, endpoint, nil, map[string]string{"body": comment.Body}, nil)
+		if err != nil {
+			return fmt.Errorf("error creating PR comment: %v", err)
+		}
+		return nil
+	}
+
+	review := map[string]any{
+		"event": "COMMENT",
+		"comments": []map[string]any{{
+			"path":         comment.Path,
+			"body":         comment.Body,
+			"new_position": comment.Line,
+		}},
+	}
+	endpoint := repoEndpoint(owner, repo, "pulls", strconv.Itoa(prNumber), "reviews")
+	if err := p.do(http.MethodPost, endpoint, nil, review, nil); err != nil {
+		return fmt.Errorf("error creating PR review comment: %v", err)
+	}
+	return nil
+}
+
+func (p *Provider) ReplyToComment(remotePath string, prNumber int, commentID int64, body string) error {
+	return p.CreatePRComment(remotePath, prNumber, types.Comment{Body: body})
+}
+
+func (p *Provider) ResolveComment(remotePath string, prNumber int, commentID int64) error {
+	return fmt.Errorf("resolving review comments is not supported by gitea")
+}
@@ -0,0 +1,388 @@
+package gitea
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/mule-ai/mule/pkg/remote/types"
+)
+
+// Gitea caps the page size at 50 by default
+const pageSize = 50
+
+// Gitea has no native draft pull requests, a WIP prefix marks them instead
+const draftPrefix = "WIP: "
+
+var re = regexp.MustCompile(`<!--(.*?)-->`)
+
+type giteaUser struct {
+	ID    int64  `json:"id"`
+	Login string `json:"login"`
+	// Email is a no-reply address when the user keeps theirs private
+	Email string `json:"email"`
+}
+
+type giteaLabel struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+type giteaBranch struct {
+	Ref string `json:"ref"`
+}
+
+type giteaPullRequest struct {
+	Number    int          `json:"number"`
+	Title     string       `json:"title"`
+	Body      string       `json:"body"`
+	State     string       `json:"state"`
+	HTMLURL   string       `json:"html_url"`
+	Labels    []giteaLabel `json:"labels"`
+	CreatedAt string       `json:"created_at"`
+	UpdatedAt string       `json:"updated_at"`
+	Head      giteaBranch  `json:"head"`
+	Base      giteaBranch  `json:"base"`
+	Merged    bool         `json:"merged"`
+}
+
+type giteaRepository struct {
+	Name        string `json:"name"`
+	FullName    string `json:"full_name"`
+	Description string `json:"description"`
+	CloneURL    string `json:"clone_url"`
+	SSHURL      string `json:"ssh_url"`
+}
+
+// apiError is returned when Gitea answers with a non 2xx status
+type apiError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("gitea api returned %d: %s", e.StatusCode, e.Body)
+}
+
+func repoEndpoint(owner, repo string, parts ...string) string {
+	endpoint := "repos/" + url.PathEscape(owner) + "/" + url.PathEscape(repo)
+	if len(parts) > 0 {
+		endpoint += "/" + strings.Join(parts, "/")
+	}
+	return endpoint
+}
+
+// raw sends a request to the Gitea API and returns the response body
+func (p *Provider) raw(method, endpoint string, query url.Values, body any) ([]byte, error) {
+	u := p.baseURL + "/" + strings.TrimPrefix(endpoint, "/")
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("error encoding request: %v", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(p.ctx, method, u, reader)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if p.token != "" {
+		req.Header.Set("Authorization", "token "+p.token)
+	}
+
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading response: %v", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, &apiError{StatusCode: resp.StatusCode, Body: string(data)}
+	}
+	return data, nil
+}
+
+// do sends a request to the Gitea API and decodes the JSON response into out
+func (p *Provider) do(method, endpoint string, query url.Values, body, out any) error {
+	data, err := p.raw(method, endpoint, query, body)
+	if err != nil {
+		return err
+	}
+	if out != nil && len(data) > 0 {
+		if err := json.Unmarshal(data, out); err != nil {
+			return fmt.Errorf("error decoding response: %v", err)
+		}
+	}
+	return nil
+}
+
+// getAll requests pages until one comes back short
+func getAll[T any](p *Provider, endpoint string, query url.Values) ([]T, error) {
+	if query == nil {
+		query = url.Values{}
+	}
+	query.Set("limit", strconv.Itoa(pageSize))
+
+	var all []T
+	for page := 1; ; page++ {
+		query.Set("page", strconv.Itoa(page))
+		var items []T
+		if err := p.do(http.MethodGet, endpoint, query, nil, &items); err != nil {
+			return nil, err
+		}
+		all = append(all, items...)
+		if len(items) < pageSize {
+			return all, nil
+		}
+	}
+}
+
+func (p *Provider) CreateDraftPR(path string, input types.PullRequestInput) (int, error) {
+	owner, repoName, err := ownerRepoFromLocalRepository(path)
+	if err != nil {
+		log.Printf("Could not determine repository from %s, using %s/%s: %v", path, p.owner, p.repo, err)
+		owner, repoName = p.owner, p.repo
+	}
+
+	title := input.Title
+	if input.Draft && !strings.HasPrefix(title, draftPrefix) {
+		title = draftPrefix + title
+	}
+
+	var pr giteaPullRequest
+	err = p.do(http.MethodPost, repoEndpoint(owner, repoName, "pulls"), nil, map[string]string{
+		"head":  input.Branch,
+		"base":  input.Base,
+		"title": title,
+		"body":  input.Description,
+	}, &pr)
+	if err != nil {
+		return 0, fmt.Errorf("error creating PR: %v", err)
+	}
+
+	log.Printf("PR created successfully: %s", pr.HTMLURL)
+
+	if len(input.Labels) > 0 {
+		endpoint := repoEndpoint(owner, repoName, "issues", strconv.Itoa(pr.Number), "labels")
+		err = p.do(http.MethodPost, endpoint, nil, map[string][]string{"labels": input.Labels}, nil)
+		if err != nil {
+			// the PR exists, failing now would only open it again
+			log.Printf("Error adding labels to PR %d: %v", pr.Number, err)
+		}
+	}
+	return pr.Number, nil
+}
+
+func (p *Provider) FetchRepositories() ([]types.Repository, error) {
+	repos, err := getAll[giteaRepository](p, "user/repos", nil)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching repositories: %v", err)
+	}
+
+	var result []types.Repository
+	for _, repo := range repos {
+		result = append(result, types.Repository{
+			Name:        repo.Name,
+			FullName:    repo.FullName,
+			Description: repo.Description,
+			CloneURL:    repo.CloneURL,
+			SSHURL:      repo.SSHURL,
+		})
+	}
+	return result, nil
+}
+
+// FetchPullRequests returns the open pull requests of the repository. The
+// label is not used to filter, matching the GitHub provider.
+func (p *Provider) FetchPullRequests(remotePath, label string) ([]types.PullRequest, error) {
+	owner, repo := p.ownerRepo(remotePath)
+	query := url.Values{}
+	query.Set("state", "open")
+
+	giteaPullRequests, err := getAll[giteaPullRequest](p, repoEndpoint(owner, repo, "pulls"), query)
+	if err != nil {
+		log.Printf("Error fetching pull requests: %v, request: %v", err, remotePath)
+		return nil, fmt.Errorf("error fetching pull requests: %v", err)
+	}
+
+	var pullRequests []types.PullRequest
+	for _, pullRequest := range giteaPullRequests {
+		if label != "" && !slices.Contains(labelNames(pullRequest.Labels), label) {
+			continue
+		}
+		pr := types.PullRequest{
+			Number:          pullRequest.Number,
+			Title:           pullRequest.Title,
+			Body:            pullRequest.Body,
+			State:           pullRequest.State,
+			HTMLURL:         pullRequest.HTMLURL,
+			Labels:          labelNames(pullRequest.Labels),
+			CreatedAt:       pullRequest.CreatedAt,
+			UpdatedAt:       pullRequest.UpdatedAt,
+			Branch:          pullRequest.Head.Ref,
+			BaseBranch:      pullRequest.Base.Ref,
+			LinkedIssueURLs: getLinkedIssueURLs(pullRequest.Body),
+			Comments:        make([]*types.Comment, 0),
+		}
+
+		comments, err := p.FetchComments(owner, repo, pullRequest.Number)
+		if err != nil {
+			log.Printf("Error fetching comments for PR %d: %v", pullRequest.Number, err)
+			// Don't return, just log the error and continue
+		}
+		pr.Comments = comments
+
+		diff, err := p.FetchDiffs(owner, repo, pullRequest.Number)
+		if err != nil {
+			log.Printf("Error fetching diffs for PR %d: %v", pullRequest.Number, err)
+			// Don't return, just log the error and continue
+		}
+		pr.Diff = diff
+
+		pullRequests = append(pullRequests, pr)
+	}
+	return pullRequests, nil
+}
+
+// FetchPullRequest returns a pull request in any state, merged pull
+// requests have the state merged
+func (p *Provider) FetchPullRequest(remotePath string, prNumber int) (types.PullRequest, error) {
+	owner, repo := p.ownerRepo(remotePath)
+	var pullRequest giteaPullRequest
+	err := p.do(http.MethodGet, repoEndpoint(owner, repo, "pulls", strconv.Itoa(prNumber)), nil, nil, &pullRequest)
+	if err != nil {
+		return types.PullRequest{}, fmt.Errorf("error fetching pull request: %v", err)
+	}
+	state := pullRequest.State
+	if pullRequest.Merged {
+		state = "merged"
+	}
+	return types.PullRequest{
+		Number:     pullRequest.Number,
+		Title:      pullRequest.Title,
+		Body:       pullRequest.Body,
+		State:      state,
+		HTMLURL:    pullRequest.HTMLURL,
+		Labels:     labelNames(pullRequest.Labels),
+		Branch:     pullRequest.Head.Ref,
+		BaseBranch: pullRequest.Base.Ref,
+	}, nil
+}
+
+func (p *Provider) UpdatePullRequestState(remotePath string, prNumber int, state string) error {
+	owner, repo := p.ownerRepo(remotePath)
+	endpoint := repoEndpoint(owner, repo, "pulls", strconv.Itoa(prNumber))
+
+	var err error
+	switch state {
+	case "open", "closed":
+		err = p.do(http.MethodPatch, endpoint, nil, map[string]string{"state": state}, nil)
+	case "merged":
+		err = p.do(http.MethodPost, endpoint+"/merge", nil, map[string]string{"Do": "merge"}, nil)
+	default:
+		return fmt.Errorf("unsupported pull request state: %s", state)
+	}
+	if err != nil {
+		return fmt.Errorf("error updating pull request state: %v", err)
+	}
+	return nil
+}
+
+// DeletePullRequest closes the pull request, Gitea does not allow deleting them
+func (p *Provider) DeletePullRequest(repoPath string, prNumber int) error {
+	return p.UpdatePullRequestState(repoPath, prNumber, "closed")
+}
+
+func (p *Provider) FetchDiffs(owner, repo string, resourceID int) (string, error) {
+	if owner == "" || repo == "" {
+		owner, repo = p.owner, p.repo
+	}
+	diff, err := p.raw(http.MethodGet, repoEndpoint(owner, repo, "pulls", strconv.Itoa(resourceID)+".diff"), nil, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to get pull request diff: %w", err)
+	}
+	return string(diff), nil
+}
+
+// ownerRepoFromLocalRepository reads owner and repository from the origin
+// remote of the repository checked out at path
+func ownerRepoFromLocalRepository(path string) (string, string, error) {
+	// issue worktrees share the remotes of the main repository
+	repo, err := git.PlainOpenWithOptions(path, &git.PlainOpenOptions{EnableDotGitCommonDir: true})
+	if err != nil {
+		return "", "", err
+	}
+	remote, err := repo.Remote("origin")
+	if err != nil {
+		return "", "", fmt.Errorf("error getting remote: %v", err)
+	}
+
+	remoteURL := remote.Config().URLs[0]
+	var repoPath string
+	if _, after, found := strings.Cut(remoteURL, "@"); found && !strings.Contains(remoteURL, "://") {
+		// scp-like ssh url, git@host:owner/repo.git
+		_, repoPath, _ = strings.Cut(after, ":")
+	} else {
+		u, err := url.Parse(remoteURL)
+		if err != nil {
+			return "", "", fmt.Errorf("invalid remote url: %v", err)
+		}
+		repoPath = u.Path
+	}
+
+	owner, repoName := splitPath(strings.TrimSuffix(repoPath, ".git"))
+	if owner == "" || repoName == "" {
+		return "", "", fmt.Errorf("invalid remote url: %s", remoteURL)
+	}
+	return owner, repoName, nil
+}
+
+func labelNames(labels []giteaLabel) []string {
+	names := make([]string, 0, len(labels))
+	for _, label := range labels {
+		names = append(names, label.Name)
+	}
+	return names
+}
+
+func getLinkedIssueURLs(body string) []string {
+	// URLs are in HTML comments, other comments such as the hints of a pull
+	// request template are left out
+	var urls []string
+	for _, match := range re.FindAllStringSubmatch(body, -1) {
+		link := strings.TrimSpace(match[1])
+		parsed, err := url.Parse(link)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			continue
+		}
+		urls = append(urls, link)
+	}
+	return urls
+}
+
+func (p *Provider) IsTeamMember(team, user string) (bool, error) {
+	return false, fmt.Errorf("teams are not supported by gitea")
+}
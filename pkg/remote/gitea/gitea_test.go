@@ -0,0 +1,225 @@
+package gitea
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"slices"
+	"strconv"
+	"testing"
+
+	"github.com/mule-ai/mule/pkg/remote/types"
+)
+
+func newTestProvider(t *testing.T, mux *http.ServeMux) *Provider {
+	t.Helper()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return NewProvider("owner/repo", server.URL, "token")
+}
+
+func TestFetchIssuesWithLabelFilter(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/repos/owner/repo/issues", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "token token" {
+			t.Errorf("expected token authorization, got %q", r.Header.Get("Authorization"))
+		}
+		query := r.URL.Query()
+		if query.Get("labels") != "mule" || query.Get("state") != "open" || query.Get("type") != "issues" {
+			t.Errorf("unexpected filter: %s", r.URL.RawQuery)
+		}
+
+		// a full first page forces a request for the second one
+		var issues []giteaIssue
+		if query.Get("page") == "1" {
+			for i := 1; i <= pageSize; i++ {
+				issues = append(issues, giteaIssue{Number: i, State: "open", Labels: []giteaLabel{{Name: "mule"}}})
+			}
+		} else {
+			issues = []giteaIssue{
+				{Number: pageSize + 1, State: "open"},
+				{Number: pageSize + 2, State: "open", PullRequest: &struct{}{}},
+			}
+		}
+		_ = json.NewEncoder(w).Encode(issues)
+	})
+	p := newTestProvider(t, mux)
+
+	issues, err := p.FetchIssues("owner/repo", types.IssueFilterOptions{Label: "mule"})
+	if err != nil {
+		t.Fatalf("FetchIssues returned error: %v", err)
+	}
+	if len(issues) != pageSize+1 {
+		t.Fatalf("expected %d issues, got %d", pageSize+1, len(issues))
+	}
+	if issues[0].Labels[0] != "mule" {
+		t.Errorf("expected labels to be converted, got %v", issues[0].Labels)
+	}
+}
+
+func TestFetchIssuesWithComments(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/repos/owner/repo/issues", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode([]giteaIssue{
+			{Number: 1, State: "open", Comments: 2},
+			{Number: 2, State: "open"},
+		})
+	})
+	mux.HandleFunc("GET /api/v1/repos/owner/repo/issues/1/comments", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode([]giteaComment{
+			{ID: 5, Body: "which endpoint?", User: giteaUser{ID: 3, Login: "alice"}, CreatedAt: "2024-01-02T10:00:00Z"},
+			{ID: 6, Body: "the sync one", User: giteaUser{ID: 4, Login: "bob"}, CreatedAt: "2024-01-02T11:00:00Z"},
+		})
+	})
+	p := newTestProvider(t, mux)
+
+	issues, err := p.FetchIssues("owner/repo", types.IssueFilterOptions{})
+	if err != nil {
+		t.Fatalf("FetchIssues returned error: %v", err)
+	}
+	if len(issues[0].Comments) != 2 || len(issues[1].Comments) != 0 {
+		t.Fatalf("expected comments only on the first issue, got %d and %d", len(issues[0].Comments), len(issues[1].Comments))
+	}
+	comment := issues[0].Comments[1]
+	if comment.Author != "bob" || comment.CreatedAt != "2024-01-02T11:00:00Z" || comment.Body != "the sync one" {
+		t.Errorf("unexpected comment: %+v", comment)
+	}
+}
+
+func TestCreateDraftPRUsesWIPPrefix(t *testing.T) {
+	path := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q", path},
+		{"-C", path, "remote", "add", "origin", "git@gitea.example.com:team/project.git"},
+	} {
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v: %s", args, err, out)
+		}
+	}
+
+	var created map[string]string
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v1/repos/team/project/pulls", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&created)
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(giteaPullRequest{Number: 7})
+	})
+	p := newTestProvider(t, mux)
+
+	number, err := p.CreateDraftPR(path, types.PullRequestInput{
+		Title:  "Add feature",
+		Branch: "add-feature",
+		Base:   "main",
+		Draft:  true,
+	})
+	if err != nil {
+		t.Fatalf("CreateDraftPR returned error: %v", err)
+	}
+	if number != 7 {
+		t.Errorf("expected PR number 7, got %d", number)
+	}
+	if created["title"] != "WIP: Add feature" {
+		t.Errorf("expected WIP prefix, got %q", created["title"])
+	}
+	if created["head"] != "add-feature" || created["base"] != "main" {
+		t.Errorf("unexpected branches: %v", created)
+	}
+}
+
+func TestReviewCommentsAndReactions(t *testing.T) {
+	reactions := map[int64][]giteaReaction{21: {{Content: "+1"}}}
+	var review, reply map[string]any
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/repos/owner/repo/pulls/3/reviews", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode([]giteaReview{
+			{ID: 1, CommentsCount: 2},
+			{ID: 2, Body: "Looks good apart from the naming", State: "REQUEST_CHANGES"},
+		})
+	})
+	mux.HandleFunc("POST /api/v1/repos/owner/repo/pulls/3/reviews", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&review)
+		_ = json.NewEncoder(w).Encode(giteaReview{ID: 3})
+	})
+	mux.HandleFunc("POST /api/v1/repos/owner/repo/issues/3/comments", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&reply)
+		w.WriteHeader(http.StatusCreated)
+	})
+	mux.HandleFunc("GET /api/v1/repos/owner/repo/issues/3/comments", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode([]giteaComment{{ID: 31, Body: "please also add tests"}})
+	})
+	mux.HandleFunc("GET /api/v1/repos/owner/repo/pulls/3/reviews/1/comments", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode([]giteaReviewComment{
+			{ID: 21, Body: "done already", Path: "main.go", Position: 4},
+			{ID: 22, Body: "please rename", Path: "main.go", Position: 8, DiffHunk: "@@ -1 +1 @@"},
+		})
+	})
+	mux.HandleFunc("/api/v1/repos/owner/repo/issues/comments/{id}/reactions", func(w http.ResponseWriter, r *http.Request) {
+		id, _ := strconv.ParseInt(r.PathValue("id"), 10, 64)
+		if r.Method == http.MethodPost {
+			var body map[string]string
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			reactions[id] = append(reactions[id], giteaReaction{Content: body["content"]})
+			w.WriteHeader(http.StatusCreated)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(reactions[id])
+	})
+	p := newTestProvider(t, mux)
+
+	comments, err := p.FetchComments("owner", "repo", 3)
+	if err != nil {
+		t.Fatalf("FetchComments returned error: %v", err)
+	}
+	if len(comments) != 4 {
+		t.Fatalf("expected 2 review comments, a conversation comment and a review summary, got %d", len(comments))
+	}
+	if comments[2].ID != 31 || comments[2].Kind != types.CommentKindConversation {
+		t.Errorf("unexpected conversation comment: %+v", comments[2])
+	}
+	if comments[3].ID != 2 || comments[3].Kind != types.CommentKindReview || comments[3].ReviewState != "REQUEST_CHANGES" {
+		t.Errorf("unexpected review summary: %+v", comments[3])
+	}
+	if comments[0].Reactions.PlusOne != 1 || comments[1].Reactions.PlusOne != 0 {
+		t.Errorf("unexpected reactions: %+v, %+v", comments[0].Reactions, comments[1].Reactions)
+	}
+	if comments[1].Path != "main.go" || comments[1].Line != 8 || comments[1].DiffHunk == "" {
+		t.Errorf("unexpected review comment: %+v", comments[1])
+	}
+
+	if err := p.AddCommentReaction("owner/repo", "+1", 22); err != nil {
+		t.Fatalf("AddCommentReaction returned error: %v", err)
+	}
+	if len(reactions[22]) != 1 || reactions[22][0].Content != "+1" {
+		t.Errorf("expected +1 reaction on comment 22, got %v", reactions[22])
+	}
+
+	err = p.CreatePRComment("owner/repo", 3, types.Comment{Body: "fixed", Path: "main.go", Line: 8})
+	if err != nil {
+		t.Fatalf("CreatePRComment returned error: %v", err)
+	}
+	if review["event"] != "COMMENT" {
+		t.Errorf("expected a COMMENT review, got %v", review["event"])
+	}
+	if fmt.Sprint(review["comments"]) != "[map[body:fixed new_position:8 path:main.go]]" {
+		t.Errorf("unexpected review comments: %v", review["comments"])
+	}
+
+	// gitea has no review threads, replies go to the pull request
+	if err := p.ReplyToComment("owner/repo", 3, 22, "renamed"); err != nil {
+		t.Fatalf("ReplyToComment returned error: %v", err)
+	}
+	if reply["body"] != "renamed" {
+		t.Errorf("expected the reply on the pull request, got %v", reply)
+	}
+}
+
+func TestLinkedIssueURLsOfTemplate(t *testing.T) {
+	body := "## Description\n<!-- Describe your change -->\n\n## Checklist\n<!-- see https://example.com/contributing -->\n\nCloses #7\n<!--https://gitea.example.com/owner/repo/issues/7-->"
+	urls := getLinkedIssueURLs(body)
+	if !slices.Equal(urls, []string{"https://gitea.example.com/owner/repo/issues/7"}) {
+		t.Errorf("expected only the linked issue, got %q", urls)
+	}
+}
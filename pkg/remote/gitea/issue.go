@@ -0,0 +1,194 @@
+package gitea
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/mule-ai/mule/pkg/remote/types"
+)
+
+type giteaIssue struct {
+	Number      int          `json:"number"`
+	Title       string       `json:"title"`
+	Body        string       `json:"body"`
+	State       string       `json:"state"`
+	HTMLURL     string       `json:"html_url"`
+	Labels      []giteaLabel `json:"labels"`
+	CreatedAt   string       `json:"created_at"`
+	UpdatedAt   string       `json:"updated_at"`
+	Comments    int          `json:"comments"`
+	PullRequest *struct{}    `json:"pull_request"`
+	User        giteaUser    `json:"user"`
+}
+
+type giteaComment struct {
+	ID        int64     `json:"id"`
+	Body      string    `json:"body"`
+	HTMLURL   string    `json:"html_url"`
+	User      giteaUser `json:"user"`
+	CreatedAt string    `json:"created_at"`
+}
+
+func (p *Provider) CreateIssue(issue types.Issue) (int, error) {
+	var created giteaIssue
+	err := p.do(http.MethodPost, repoEndpoint(p.owner, p.repo, "issues"), nil, map[string]string{
+		"title": issue.Title,
+		"body":  issue.Body,
+	}, &created)
+	if err != nil {
+		return 0, fmt.Errorf("error creating issue: %v", err)
+	}
+
+	for _, label := range issue.Labels {
+		if err := p.AddLabelToIssue(created.Number, label); err != nil {
+			return created.Number, err
+		}
+	}
+	return created.Number, nil
+}
+
+func (p *Provider) FetchIssues(remotePath string, options types.IssueFilterOptions) ([]types.Issue, error) {
+	owner, repo := p.ownerRepo(remotePath)
+
+	stateFilter := "open"
+	if options.State != "" {
+		stateFilter = options.State
+	}
+	query := url.Values{}
+	query.Set("state", stateFilter)
+	query.Set("type", "issues")
+	if options.Label != "" {
+		query.Set("labels", options.Label)
+	}
+
+	giteaIssues, err := getAll[giteaIssue](p, repoEndpoint(owner, repo, "issues"), query)
+	if err != nil {
+		log.Printf("Error fetching issues: %v, request: %v", err, remotePath)
+		return nil, fmt.Errorf("error fetching issues: %v", err)
+	}
+
+	var issues []types.Issue
+	for _, issue := range giteaIssues {
+		// older servers ignore the type filter
+		if issue.PullRequest != nil {
+			continue
+		}
+		i := types.Issue{
+			Number:    issue.Number,
+			Title:     issue.Title,
+			Body:      issue.Body,
+			State:     issue.State,
+			HTMLURL:   issue.HTMLURL,
+			SourceURL: issue.HTMLURL,
+			Labels:    labelNames(issue.Labels),
+			CreatedAt: issue.CreatedAt,
+			UpdatedAt: issue.UpdatedAt,
+			Author:    issue.User.Login,
+		}
+		// credits the author, gitea hides private addresses itself
+		i.AuthorEmail = issue.User.Email
+		if issue.Comments > 0 {
+			i.Comments, err = p.fetchIssueComments(owner, repo, issue.Number)
+			if err != nil {
+				log.Printf("Error fetching comments for issue %d: %v", i.Number, err)
+				// Don't return, just log the error and continue
+			}
+		}
+		issues = append(issues, i)
+	}
+	return issues, nil
+}
+
+// fetchIssueComments returns the discussion of an issue, oldest first
+func (p *Provider) fetchIssueComments(owner, repo string, issueNumber int) ([]*types.Comment, error) {
+	giteaComments, err := getAll[giteaComment](p, repoEndpoint(owner, repo, "issues", strconv.Itoa(issueNumber), "comments"), nil)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching issue comments: %v", err)
+	}
+
+	comments := make([]*types.Comment, 0, len(giteaComments))
+	for _, comment := range giteaComments {
+		comments = append(comments, &types.Comment{
+			ID:        comment.ID,
+			Body:      comment.Body,
+			HTMLURL:   comment.HTMLURL,
+			UserID:    comment.User.ID,
+			Author:    comment.User.Login,
+			CreatedAt: comment.CreatedAt,
+		})
+	}
+	return comments, nil
+}
+
+func (p *Provider) AddLabelToIssue(issueNumber int, label string) error {
+	endpoint := repoEndpoint(p.owner, p.repo, "issues", strconv.Itoa(issueNumber), "labels")
+	err := p.do(http.MethodPost, endpoint, nil, map[string][]string{"labels": {label}}, nil)
+	if err != nil {
+		return fmt.Errorf("error adding label: %v", err)
+	}
+	return nil
+}
+
+// RemoveLabelFromIssue removes the label by its ID, an issue without it is
+// left as is
+func (p *Provider) RemoveLabelFromIssue(issueNumber int, label string) error {
+	endpoint := repoEndpoint(p.owner, p.repo, "issues", strconv.Itoa(issueNumber), "labels")
+	var labels []giteaLabel
+	if err := p.do(http.MethodGet, endpoint, nil, nil, &labels); err != nil {
+		return fmt.Errorf("error fetching labels: %v", err)
+	}
+	for _, l := range labels {
+		if l.Name != label {
+			continue
+		}
+		err := p.do(http.MethodDelete, endpoint+"/"+strconv.FormatInt(l.ID, 10), nil, nil, nil)
+		if err != nil {
+			return fmt.Errorf("error removing label: %v", err)
+		}
+	}
+	return nil
+}
+
+func (p *Provider) UpdateIssueState(issueNumber int, state string) error {
+	if state != "open" && state != "closed" {
+		return fmt.Errorf("unsupported issue state: %s", state)
+	}
+	return p.editIssue(issueNumber, map[string]string{"state": state})
+}
+
+func (p *Provider) UpdateIssue(issueNumber int, title, body string) error {
+	return p.editIssue(issueNumber, map[string]string{
+		"title": title,
+		"body":  body,
+	})
+}
+
+func (p *Provider) editIssue(issueNumber int, request map[string]string) error {
+	err := p.do(http.MethodPatch, repoEndpoint(p.owner, p.repo, "issues", strconv.Itoa(issueNumber)), nil, request, nil)
+	if err != nil {
+		return fmt.Errorf("error updating issue: %v", err)
+	}
+	return nil
+}
+
+func (p *Provider) CreateIssueComment(remotePath string, issueNumber int, comment types.Comment) error {
+	owner, repo := p.ownerRepo(remotePath)
+	endpoint := repoEndpoint(owner, repo, "issues", strconv.Itoa(issueNumber), "comments")
+	err := p.do(http.MethodPost, endpoint, nil, map[string]string{"body": comment.Body}, nil)
+	if err != nil {
+		return fmt.Errorf("error creating issue comment: %v", err)
+	}
+	return nil
+}
+
+func (p *Provider) DeleteIssue(repoPath string, issueNumber int) error {
+	owner, repo := p.ownerRepo(repoPath)
+	err := p.do(http.MethodDelete, repoEndpoint(owner, repo, "issues", strconv.Itoa(issueNumber)), nil, nil, nil)
+	if err != nil {
+		return fmt.Errorf("error deleting issue: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package gitea
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+type Provider struct {
+	Client  *http.Client
+	ctx     context.Context
+	baseURL string
+	token   string
+	owner   string
+	repo    string
+	// currentUser caches the login the token belongs to
+	userMu      sync.Mutex
+	currentUser string
+}
+
+// creates a new gitea provider for the repository at path (owner/repo) on
+// the Gitea or Forgejo instance served from baseURL
+func NewProvider(path, baseURL, token string) *Provider {
+	baseURL = strings.TrimSuffix(baseURL, "/")
+	if !strings.HasSuffix(baseURL, "/api/v1") {
+		baseURL += "/api/v1"
+	}
+	owner, repo := splitPath(path)
+	return &Provider{
+		Client:  http.DefaultClient,
+		ctx:     context.Background(),
+		baseURL: baseURL,
+		token:   token,
+		owner:   owner,
+		repo:    repo,
+	}
+}
+
+// ownerRepo resolves the repository to address, preferring the remote path
+// passed by the caller over the one the provider was created with
+func (p *Provider) ownerRepo(remotePath string) (string, string) {
+	if remotePath != "" && !strings.HasPrefix(remotePath, "/") {
+		owner, repo := splitPath(remotePath)
+		if owner != "" && repo != "" {
+			return owner, repo
+		}
+	}
+	return p.owner, p.repo
+}
+
+func splitPath(path string) (string, string) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) < 2 {
+		return "", ""
+	}
+	return parts[0], parts[1]
+}
+
+// GitCredentials returns the basic auth credentials for git over HTTPS
+func (p *Provider) GitCredentials() (string, string, error) {
+	if p.token == "" {
+		return "", "", fmt.Errorf("gitea token is not set")
+	}
+	return "git", p.token, nil
+}
+
+// CurrentUser returns the login the token belongs to
+func (p *Provider) CurrentUser() (string, error) {
+	p.userMu.Lock()
+	defer p.userMu.Unlock()
+	if p.currentUser != "" {
+		return p.currentUser, nil
+	}
+	var user giteaUser
+	if err := p.do(http.MethodGet, "user", nil, nil, &user); err != nil {
+		return "", fmt.Errorf("error fetching current user: %v", err)
+	}
+	p.currentUser = user.Login
+	return user.Login, nil
+}
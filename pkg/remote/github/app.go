@@ -0,0 +1,199 @@
+package github
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+const defaultAPIURL = "https://api.github.com/"
+
+// AppCredentials identify a GitHub App installation. When set, the provider
+// authenticates as the app's bot account instead of with a personal token.
+type AppCredentials struct {
+	AppID          int64  `json:"appId,omitempty"`
+	InstallationID int64  `json:"installationId,omitempty"`
+	PrivateKey     string `json:"privateKey,omitempty"`
+}
+
+// Enabled reports whether all app credentials are configured
+func (c AppCredentials) Enabled() bool {
+	return c.AppID != 0 && c.InstallationID != 0 && c.PrivateKey != ""
+}
+
+// installationTokenSource mints installation access tokens for a GitHub App,
+// signing its requests with a short lived JWT
+type installationTokenSource struct {
+	appID          int64
+	installationID int64
+	key            *rsa.PrivateKey
+	baseURL        string
+	client         *http.Client
+	now            func() time.Time
+}
+
+// NewAppProvider creates a provider authenticated as a GitHub App
+// installation. Installation tokens are minted on first use and refreshed
+// shortly before they expire.
+func NewAppProvider(path string, credentials AppCredentials) (*Provider, error) {
+	ts, err := newInstallationTokenSource(credentials, defaultAPIURL)
+	if err != nil {
+		return nil, err
+	}
+	p := newProviderWithTokenSource(path, oauth2.ReuseTokenSource(nil, ts))
+	p.app = ts
+	return p, nil
+}
+
+func newInstallationTokenSource(credentials AppCredentials, baseURL string) (*installationTokenSource, error) {
+	if !credentials.Enabled() {
+		return nil, fmt.Errorf("github app id, installation id and private key are required")
+	}
+	key, err := parsePrivateKey(credentials.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	return &installationTokenSource{
+		appID:          credentials.AppID,
+		installationID: credentials.InstallationID,
+		key:            key,
+		baseURL:        baseURL,
+		client:         &http.Client{Timeout: 30 * time.Second},
+		now:            time.Now,
+	}, nil
+}
+
+func parsePrivateKey(data string) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(data))
+	if block == nil {
+		return nil, fmt.Errorf("error decoding github app private key: no PEM data found")
+	}
+	// GitHub hands out PKCS#1 keys, converted keys are often PKCS#8
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing github app private key: %v", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("github app private key is not an RSA key")
+	}
+	return key, nil
+}
+
+// jwt creates the RS256 signed token GitHub expects when acting as the app.
+// It is backdated a minute to allow for clock drift and lives for nine
+// minutes, GitHub rejects anything valid for more than ten.
+func (s *installationTokenSource) jwt() (string, error) {
+	now := s.now()
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]any{
+		"iat": now.Add(-time.Minute).Unix(),
+		"exp": now.Add(9 * time.Minute).Unix(),
+		"iss": strconv.FormatInt(s.appID, 10),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, s.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("error signing github app jwt: %v", err)
+	}
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+func (s *installationTokenSource) Token() (*oauth2.Token, error) {
+	status, body, err := s.request(http.MethodPost, fmt.Sprintf("/app/installations/%d/access_tokens", s.installationID))
+	if err != nil {
+		return nil, fmt.Errorf("error requesting installation token: %v", err)
+	}
+	if status != http.StatusCreated {
+		return nil, fmt.Errorf("error requesting installation token: github returned %d: %s", status, body)
+	}
+
+	var token struct {
+		Token     string    `json:"token"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+	if err := json.Unmarshal(body, &token); err != nil {
+		return nil, fmt.Errorf("error decoding installation token: %v", err)
+	}
+	return &oauth2.Token{
+		AccessToken: token.Token,
+		Expiry:      token.ExpiresAt,
+	}, nil
+}
+
+// botLogin returns the login of the bot account the installation acts as,
+// which is the slug of the app followed by [bot]
+func (s *installationTokenSource) botLogin() (string, error) {
+	status, body, err := s.request(http.MethodGet, "/app")
+	if err != nil {
+		return "", fmt.Errorf("error fetching github app: %v", err)
+	}
+	if status != http.StatusOK {
+		return "", fmt.Errorf("error fetching github app: github returned %d: %s", status, body)
+	}
+
+	var app struct {
+		Slug string `json:"slug"`
+	}
+	if err := json.Unmarshal(body, &app); err != nil {
+		return "", fmt.Errorf("error decoding github app: %v", err)
+	}
+	if app.Slug == "" {
+		return "", fmt.Errorf("github app has no slug")
+	}
+	return app.Slug + "[bot]", nil
+}
+
+// request calls the API as the app and returns the status and the body of
+// the response
+func (s *installationTokenSource) request(method, path string) (int, []byte, error) {
+	jwt, err := s.jwt()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	u := strings.TrimSuffix(s.baseURL, "/") + path
+	req, err := http.NewRequestWithContext(context.Background(), method, u, nil)
+	if err != nil {
+		return 0, nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+jwt)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, nil, fmt.Errorf("error reading response: %v", err)
+	}
+	return resp.StatusCode, body, nil
+}
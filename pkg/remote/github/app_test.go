@@ -0,0 +1,187 @@
+package github
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mule-ai/mule/pkg/remote/types"
+	"golang.org/x/oauth2"
+)
+
+func testPrivateKey(t *testing.T) (*rsa.PrivateKey, string) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("error generating key: %v", err)
+	}
+	block := &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}
+	return key, string(pem.EncodeToMemory(block))
+}
+
+// verifyJWT checks the signature of an app JWT and returns its claims
+func verifyJWT(t *testing.T, key *rsa.PrivateKey, token string) map[string]any {
+	t.Helper()
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		t.Fatalf("expected a JWT, got %q", token)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		t.Fatalf("error decoding signature: %v", err)
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, digest[:], signature); err != nil {
+		t.Fatalf("invalid JWT signature: %v", err)
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		t.Fatalf("error decoding claims: %v", err)
+	}
+	var claims map[string]any
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		t.Fatalf("error decoding claims: %v", err)
+	}
+	return claims
+}
+
+func TestInstallationTokensAreMintedAndRefreshed(t *testing.T) {
+	key, pemKey := testPrivateKey(t)
+
+	minted := 0
+	// the first token is about to expire and must be replaced on next use
+	expiries := []time.Duration{5 * time.Second, time.Hour}
+	var apiAuthorizations []string
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /app/installations/99/access_tokens", func(w http.ResponseWriter, r *http.Request) {
+		claims := verifyJWT(t, key, strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "))
+		if claims["iss"] != "42" {
+			t.Errorf("expected the app id as issuer, got %v", claims["iss"])
+		}
+		if exp, iat := claims["exp"].(float64), claims["iat"].(float64); exp-iat > 600 {
+			t.Errorf("expected the JWT to live at most ten minutes, got %v seconds", exp-iat)
+		}
+
+		expiry := expiries[minted]
+		minted++
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"token":      fmt.Sprintf("installation-token-%d", minted),
+			"expires_at": time.Now().Add(expiry),
+		})
+	})
+	mux.HandleFunc("GET /repos/owner/repo/issues", func(w http.ResponseWriter, r *http.Request) {
+		apiAuthorizations = append(apiAuthorizations, r.Header.Get("Authorization"))
+		_, _ = w.Write([]byte(`[]`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	ts, err := newInstallationTokenSource(AppCredentials{AppID: 42, InstallationID: 99, PrivateKey: pemKey}, server.URL+"/")
+	if err != nil {
+		t.Fatalf("newInstallationTokenSource returned error: %v", err)
+	}
+	p := newProviderWithTokenSource("owner/repo", oauth2.ReuseTokenSource(nil, ts))
+	baseURL, _ := url.Parse(server.URL + "/")
+	p.Client.BaseURL = baseURL
+
+	for i := 0; i < 3; i++ {
+		if _, err := p.FetchIssues("owner/repo", types.IssueFilterOptions{}); err != nil {
+			t.Fatalf("FetchIssues returned error: %v", err)
+		}
+	}
+
+	want := []string{
+		"Bearer installation-token-1",
+		"Bearer installation-token-2",
+		"Bearer installation-token-2",
+	}
+	if strings.Join(apiAuthorizations, ",") != strings.Join(want, ",") {
+		t.Errorf("expected API calls with %v, got %v", want, apiAuthorizations)
+	}
+	if minted != 2 {
+		t.Errorf("expected 2 tokens to be minted, got %d", minted)
+	}
+
+	username, password, err := p.GitCredentials()
+	if err != nil {
+		t.Fatalf("GitCredentials returned error: %v", err)
+	}
+	if username != "x-access-token" || password != "installation-token-2" {
+		t.Errorf("unexpected git credentials: %s:%s", username, password)
+	}
+}
+
+func TestAppCurrentUser(t *testing.T) {
+	key, pemKey := testPrivateKey(t)
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /app", func(w http.ResponseWriter, r *http.Request) {
+		verifyJWT(t, key, strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "))
+		_, _ = w.Write([]byte(`{"id": 42, "slug": "mule-ai"}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	ts, err := newInstallationTokenSource(AppCredentials{AppID: 42, InstallationID: 99, PrivateKey: pemKey}, server.URL+"/")
+	if err != nil {
+		t.Fatalf("newInstallationTokenSource returned error: %v", err)
+	}
+	p := newProviderWithTokenSource("owner/repo", oauth2.ReuseTokenSource(nil, ts))
+	p.app = ts
+
+	login, err := p.CurrentUser()
+	if err != nil {
+		t.Fatalf("CurrentUser returned error: %v", err)
+	}
+	if login != "mule-ai[bot]" {
+		t.Errorf("expected the bot account of the app, got %q", login)
+	}
+}
+
+func TestParsePrivateKey(t *testing.T) {
+	key, pkcs1 := testPrivateKey(t)
+
+	pkcs8Bytes, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("error encoding key: %v", err)
+	}
+	pkcs8 := string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8Bytes}))
+
+	for name, data := range map[string]string{"pkcs1": pkcs1, "pkcs8": pkcs8} {
+		parsed, err := parsePrivateKey(data)
+		if err != nil {
+			t.Errorf("%s: parsePrivateKey returned error: %v", name, err)
+			continue
+		}
+		if !parsed.Equal(key) {
+			t.Errorf("%s: parsed key does not match", name)
+		}
+	}
+
+	if _, err := parsePrivateKey("not a key"); err == nil {
+		t.Errorf("expected an error for invalid PEM data")
+	}
+}
+
+func TestAppCredentialsEnabled(t *testing.T) {
+	if (AppCredentials{AppID: 1, InstallationID: 2}).Enabled() {
+		t.Errorf("expected credentials without a private key to be disabled")
+	}
+	if !(AppCredentials{AppID: 1, InstallationID: 2, PrivateKey: "key"}).Enabled() {
+		t.Errorf("expected complete credentials to be enabled")
+	}
+}
@@ -1,8 +1,12 @@
 package github
 
 import (
+	"context"
 	"fmt"
+	"log"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/google/go-github/v60/github"
 	"github.com/mule-ai/mule/pkg/remote/types"
@@ -11,52 +15,144 @@ import (
 /*
 Here is a brief explanation of each function:
 
-WorkspaceComments: Retrieves comments and their associated reactions for a given GitHub pull request.
+WorkspaceComments: Retrieves comments and their associated reactions and review threads for a given GitHub pull request, followed by the comments in its conversation and the review summaries, leaving out those of bots other than mule.
 WorkspacePullRequestCommentReactions: Fetches just the reactions for a specific comment ID on a GitHub pull request.
-AddCommentReaction: Adds a particular reaction (like "+1" or "heart") to a specified comment on GitHub.
+AddCommentReaction: Adds a particular reaction (like "+1" or "heart") to a specified review comment on GitHub.
+ReplyToComment: Answers a review comment in its thread.
 */
 func (p *Provider) FetchComments(owner, repo string, prNumber int) ([]*types.Comment, error) {
-	opt := &github.PullRequestListCommentsOptions{
-		ListOptions: github.ListOptions{
-			PerPage: 100,
-		},
-	}
-
-	ghComments, _, err := p.Client.PullRequests.ListComments(p.ctx, owner, repo, prNumber, opt)
+	ghComments, err := paginate(p, func(ctx context.Context, lo github.ListOptions) ([]*github.PullRequestComment, *github.Response, error) {
+		return p.Client.PullRequests.ListComments(ctx, owner, repo, prNumber, &github.PullRequestListCommentsOptions{
+			ListOptions: lo,
+		})
+	})
 	if err != nil {
 		return nil, fmt.Errorf("error fetching comments: %v", err)
 	}
 
+	// without threads the comments are acknowledged by reactions, which are
+	// only fetched then
+	var threads map[int64]reviewThread
+	threaded := false
+	if len(ghComments) > 0 {
+		threads, err = p.fetchReviewThreads(owner, repo, prNumber)
+		if err != nil {
+			log.Printf("Error fetching review threads for PR %d: %v", prNumber, err)
+		} else {
+			threaded = true
+		}
+	}
+
 	var comments []*types.Comment
 	for _, comment := range ghComments {
 		c := &types.Comment{
-			ID:       comment.GetID(),
-			Body:     comment.GetBody(),
-			DiffHunk: comment.GetDiffHunk(),
-			HTMLURL:  comment.GetHTMLURL(),
-			URL:      comment.GetURL(),
-			UserID:   comment.GetUser().GetID(),
+			ID:                comment.GetID(),
+			Body:              comment.GetBody(),
+			DiffHunk:          comment.GetDiffHunk(),
+			Path:              comment.GetPath(),
+			Line:              comment.GetLine(),
+			HTMLURL:           comment.GetHTMLURL(),
+			URL:               comment.GetURL(),
+			UserID:            comment.GetUser().GetID(),
+			Author:            comment.GetUser().GetLogin(),
+			CreatedAt:         comment.GetCreatedAt().Format(time.RFC3339),
+			InReplyTo:         comment.GetInReplyTo(),
+			AuthorAssociation: comment.GetAuthorAssociation(),
 		}
-		reactions, err := p.FetchPullRequestCommentReactions(owner, repo, comment.GetID())
-		if err != nil {
-			return nil, fmt.Errorf("error fetching reactions: %v", err)
+		if thread, ok := threads[comment.GetID()]; ok {
+			c.ThreadID = thread.ID
+			c.Resolved = thread.IsResolved
+			c.Outdated = thread.IsOutdated
+		}
+		if !threaded {
+			reactions, err := p.FetchPullRequestCommentReactions(owner, repo, comment.GetID())
+			if err != nil {
+				return nil, fmt.Errorf("error fetching reactions: %v", err)
+			}
+			c.Reactions = reactions
+		}
+		comments = append(comments, c)
+	}
+
+	// general feedback, the conversation of a pull request is that of its issue
+	conversation, err := p.fetchIssueComments(owner, repo, prNumber)
+	if err != nil {
+		return nil, err
+	}
+	reviews, err := p.fetchReviews(owner, repo, prNumber)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range conversation {
+		c.Kind = types.CommentKindConversation
+	}
+	// general feedback is addressed once mule answered it with a reply
+	// marker, so mule's own replies are kept even when it is a bot
+	for _, c := range slices.Concat(conversation, reviews) {
+		if isBot(c.Author) {
+			mule, err := p.isMule(c.Author)
+			if err != nil {
+				return nil, err
+			}
+			if !mule {
+				continue
+			}
 		}
-		c.Reactions = reactions
 		comments = append(comments, c)
 	}
 
 	return comments, nil
 }
 
+// isBot reports whether the login is that of a GitHub App, the feedback of
+// bots is not worked on
+func isBot(login string) bool {
+	return strings.HasSuffix(login, "[bot]")
+}
+
+// isMule reports whether login is the account the provider acts as
+func (p *Provider) isMule(login string) (bool, error) {
+	account, err := p.CurrentUser()
+	if err != nil {
+		return false, err
+	}
+	return strings.EqualFold(account, login), nil
+}
+
+// fetchReviews returns the reviews of a pull request that have a summary
+func (p *Provider) fetchReviews(owner, repo string, prNumber int) ([]*types.Comment, error) {
+	ghReviews, err := paginate(p, func(ctx context.Context, lo github.ListOptions) ([]*github.PullRequestReview, *github.Response, error) {
+		return p.Client.PullRequests.ListReviews(ctx, owner, repo, prNumber, &lo)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error fetching reviews: %v", err)
+	}
+
+	var reviews []*types.Comment
+	for _, review := range ghReviews {
+		// reviews without a summary only carry inline comments
+		if strings.TrimSpace(review.GetBody()) == "" {
+			continue
+		}
+		reviews = append(reviews, &types.Comment{
+			ID:                review.GetID(),
+			Body:              review.GetBody(),
+			HTMLURL:           review.GetHTMLURL(),
+			UserID:            review.GetUser().GetID(),
+			Author:            review.GetUser().GetLogin(),
+			CreatedAt:         review.GetSubmittedAt().Format(time.RFC3339),
+			Kind:              types.CommentKindReview,
+			ReviewState:       review.GetState(),
+			AuthorAssociation: review.GetAuthorAssociation(),
+		})
+	}
+	return reviews, nil
+}
+
 func (p *Provider) FetchPullRequestCommentReactions(owner, repo string, commentID int64) (types.Reactions, error) {
-	opt := &github.ListCommentReactionOptions{
-		ListOptions: github.ListOptions{
-			PerPage: 100,
-		},
-	}
-	// add pulls to repo for proper URL
-	repo = repo + "/pulls"
-	ghReactions, _, err := p.Client.Reactions.ListCommentReactions(p.ctx, owner, repo, commentID, opt)
+	ghReactions, err := paginate(p, func(ctx context.Context, lo github.ListOptions) ([]*github.Reaction, *github.Response, error) {
+		return p.Client.Reactions.ListPullRequestCommentReactions(ctx, owner, repo, commentID, &lo)
+	})
 	if err != nil {
 		return types.Reactions{}, fmt.Errorf("error fetching reactions: %v", err)
 	}
@@ -87,16 +183,28 @@ func (p *Provider) FetchPullRequestCommentReactions(owner, repo string, commentI
 }
 
 func (p *Provider) AddCommentReaction(repoPath, reaction string, commentID int64) error {
-	parts := strings.Split(repoPath, "/")
-	if len(parts) < 2 {
-		return fmt.Errorf("invalid repo path format")
+	owner, repo, err := p.ownerRepo(repoPath)
+	if err != nil {
+		return err
 	}
-	owner := parts[0]
-	repo := parts[1]
 
-	_, _, err := p.Client.Reactions.CreateCommentReaction(p.ctx, owner, repo, commentID, reaction)
+	// the comments mule reacts to are pull request review comments
+	_, _, err = p.Client.Reactions.CreatePullRequestCommentReaction(p.ctx, owner, repo, commentID, reaction)
 	if err != nil {
 		return fmt.Errorf("error adding reaction: %v", err)
 	}
 	return nil
 }
+
+func (p *Provider) ReplyToComment(remotePath string, prNumber int, commentID int64, body string) error {
+	owner, repo, err := p.ownerRepo(remotePath)
+	if err != nil {
+		return err
+	}
+
+	_, _, err = p.Client.PullRequests.CreateCommentInReplyTo(p.ctx, owner, repo, prNumber, body, commentID)
+	if err != nil {
+		return fmt.Errorf("error replying to comment: %v", err)
+	}
+	return nil
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"net/http"
+	"net/url"
 	"regexp"
 	"strings"
 
@@ -19,23 +21,21 @@ type GitHubPRResponse struct {
 
 var re = regexp.MustCompile(`<!--(.*?)-->`)
 
-func newGitHubClient(ctx context.Context, token string) *github.Client {
-	ts := oauth2.StaticTokenSource(
-		&oauth2.Token{AccessToken: token},
-	)
+func newGitHubClient(ctx context.Context, ts oauth2.TokenSource) *github.Client {
 	tc := oauth2.NewClient(ctx, ts)
 	return github.NewClient(tc)
 }
 
-func (p *Provider) CreateDraftPR(path string, input types.PullRequestInput) error {
-	repo, err := git.PlainOpen(path)
+func (p *Provider) CreateDraftPR(path string, input types.PullRequestInput) (int, error) {
+	// issue worktrees share the remotes of the main repository
+	repo, err := git.PlainOpenWithOptions(path, &git.PlainOpenOptions{EnableDotGitCommonDir: true})
 	if err != nil {
-		return err
+		return 0, err
 	}
 
 	remote, err := repo.Remote("origin")
 	if err != nil {
-		return fmt.Errorf("error getting remote: %v", err)
+		return 0, fmt.Errorf("error getting remote: %v", err)
 	}
 
 	remoteURL := remote.Config().URLs[0]
@@ -61,24 +61,38 @@ func (p *Provider) CreateDraftPR(path string, input types.PullRequestInput) erro
 
 	pr, _, err := p.Client.PullRequests.Create(p.ctx, owner, repoName, newPR)
 	if err != nil {
-		return fmt.Errorf("error creating PR: %v", err)
+		return 0, fmt.Errorf("error creating PR: %v", err)
 	}
 
 	prLink := pr.GetHTMLURL()
 	log.Printf("PR created successfully: %s", prLink)
 
-	return nil
+	if len(input.Labels) > 0 {
+		_, _, err = p.Client.Issues.AddLabelsToIssue(p.ctx, owner, repoName, pr.GetNumber(), input.Labels)
+		if err != nil {
+			// the PR exists, failing now would only open it again
+			log.Printf("Error adding labels to PR %d: %v", pr.GetNumber(), err)
+		}
+	}
+
+	return pr.GetNumber(), nil
 }
 
 func (p *Provider) FetchRepositories() ([]types.Repository, error) {
-	opt := &github.RepositoryListByAuthenticatedUserOptions{
-		Sort: "updated",
-		ListOptions: github.ListOptions{
-			PerPage: 100,
-		},
-	}
-
-	repos, _, err := p.Client.Repositories.ListByAuthenticatedUser(p.ctx, opt)
+	repos, err := paginate(p, func(ctx context.Context, lo github.ListOptions) ([]*github.Repository, *github.Response, error) {
+		// an app installation has no user, list the repositories it was granted
+		if p.app != nil {
+			list, resp, err := p.Client.Apps.ListRepos(ctx, &lo)
+			if err != nil {
+				return nil, resp, err
+			}
+			return list.Repositories, resp, nil
+		}
+		return p.Client.Repositories.ListByAuthenticatedUser(ctx, &github.RepositoryListByAuthenticatedUserOptions{
+			Sort:        "updated",
+			ListOptions: lo,
+		})
+	})
 	if err != nil {
 		return nil, fmt.Errorf("error fetching repositories: %v", err)
 	}
@@ -105,14 +119,12 @@ func (p *Provider) FetchPullRequests(remotePath, label string) ([]types.PullRequ
 	owner := parts[0]
 	repo := parts[1]
 
-	opt := &github.PullRequestListOptions{
-		State: "open",
-		ListOptions: github.ListOptions{
-			PerPage: 100,
-		},
-	}
-
-	ghPullRequests, _, err := p.Client.PullRequests.List(p.ctx, owner, repo, opt)
+	ghPullRequests, err := paginate(p, func(ctx context.Context, lo github.ListOptions) ([]*github.PullRequest, *github.Response, error) {
+		return p.Client.PullRequests.List(ctx, owner, repo, &github.PullRequestListOptions{
+			State:       "open",
+			ListOptions: lo,
+		})
+	})
 	if err != nil {
 		log.Printf("Error fetching pull requests: %v, request: %v", err, remotePath)
 		return nil, fmt.Errorf("error fetching pull requests: %v", err)
@@ -120,6 +132,9 @@ func (p *Provider) FetchPullRequests(remotePath, label string) ([]types.PullRequ
 
 	var pullRequests []types.PullRequest
 	for _, pullRequest := range ghPullRequests {
+		if label != "" && !hasLabel(pullRequest.Labels, label) {
+			continue
+		}
 		pr := types.PullRequest{
 			Number:          pullRequest.GetNumber(),
 			Title:           pullRequest.GetTitle(),
@@ -130,11 +145,13 @@ func (p *Provider) FetchPullRequests(remotePath, label string) ([]types.PullRequ
 			IssueURL:        pullRequest.GetIssueURL(),
 			CreatedAt:       pullRequest.GetCreatedAt().String(),
 			UpdatedAt:       pullRequest.GetUpdatedAt().String(),
+			Branch:          pullRequest.GetHead().GetRef(),
+			BaseBranch:      pullRequest.GetBase().GetRef(),
 			LinkedIssueURLs: getLinkedIssueURLs(pullRequest.GetBody()),
 			Comments:        make([]*types.Comment, 0),
 		}
-		for i, label := range pullRequest.Labels {
-			pr.Labels[i] = label.GetName()
+		for _, prLabel := range pullRequest.Labels {
+			pr.Labels = append(pr.Labels, prLabel.GetName())
 		}
 
 		// Fetch comments for the pull request
@@ -158,11 +175,86 @@ func (p *Provider) FetchPullRequests(remotePath, label string) ([]types.PullRequ
 	return pullRequests, nil
 }
 
+// FetchPullRequest returns a pull request in any state, merged pull
+// requests have the state merged
+func (p *Provider) FetchPullRequest(remotePath string, prNumber int) (types.PullRequest, error) {
+	owner, repo, err := p.ownerRepo(remotePath)
+	if err != nil {
+		return types.PullRequest{}, err
+	}
+	pullRequest, _, err := p.Client.PullRequests.Get(p.ctx, owner, repo, prNumber)
+	if err != nil {
+		return types.PullRequest{}, fmt.Errorf("error fetching pull request: %v", err)
+	}
+	state := pullRequest.GetState()
+	if pullRequest.GetMerged() {
+		state = "merged"
+	}
+	return types.PullRequest{
+		Number:     pullRequest.GetNumber(),
+		Title:      pullRequest.GetTitle(),
+		Body:       pullRequest.GetBody(),
+		State:      state,
+		HTMLURL:    pullRequest.GetHTMLURL(),
+		Branch:     pullRequest.GetHead().GetRef(),
+		BaseBranch: pullRequest.GetBase().GetRef(),
+	}, nil
+}
+
 func (p *Provider) UpdatePullRequestState(remotePath string, prNumber int, state string) error {
+	owner, repo, err := p.ownerRepo(remotePath)
+	if err != nil {
+		return err
+	}
+
+	switch state {
+	case "open", "closed":
+		_, _, err = p.Client.PullRequests.Edit(p.ctx, owner, repo, prNumber, &github.PullRequest{
+			State: github.String(state),
+		})
+	case "merged":
+		_, _, err = p.Client.PullRequests.Merge(p.ctx, owner, repo, prNumber, "", nil)
+	default:
+		return fmt.Errorf("unsupported pull request state: %s", state)
+	}
+	if err != nil {
+		return fmt.Errorf("error updating pull request state: %v", err)
+	}
 	return nil
 }
 
 func (p *Provider) CreatePRComment(remotePath string, prNumber int, comment types.Comment) error {
+	owner, repo, err := p.ownerRepo(remotePath)
+	if err != nil {
+		return err
+	}
+
+	// without a file position this is a regular comment on the pull request
+	if comment.Path == "" || comment.Line == 0 {
+		_, _, err = p.Client.Issues.CreateComment(p.ctx, owner, repo, prNumber, &github.IssueComment{
+			Body: github.String(comment.Body),
+		})
+		if err != nil {
+			return fmt.Errorf("error creating PR comment: %v", err)
+		}
+		return nil
+	}
+
+	// review comments are anchored to a commit, use the head of the pull request
+	pr, _, err := p.Client.PullRequests.Get(p.ctx, owner, repo, prNumber)
+	if err != nil {
+		return fmt.Errorf("error fetching pull request: %v", err)
+	}
+	_, _, err = p.Client.PullRequests.CreateComment(p.ctx, owner, repo, prNumber, &github.PullRequestComment{
+		Body:     github.String(comment.Body),
+		CommitID: github.String(pr.GetHead().GetSHA()),
+		Path:     github.String(comment.Path),
+		Line:     github.Int(comment.Line),
+		Side:     github.String("RIGHT"),
+	})
+	if err != nil {
+		return fmt.Errorf("error creating PR review comment: %v", err)
+	}
 	return nil
 }
 
@@ -174,14 +266,44 @@ func (p *Provider) FetchDiffs(owner, repo string, resourceID int) (string, error
 	return diff, nil
 }
 
+func hasLabel(labels []*github.Label, name string) bool {
+	for _, label := range labels {
+		if label.GetName() == name {
+			return true
+		}
+	}
+	return false
+}
+
 func getLinkedIssueURLs(body string) []string {
-	// URLs are in HTML comments
-	matches := re.FindAllString(body, -1)
-	urls := make([]string, len(matches))
-	for i, match := range matches {
-		match = strings.TrimPrefix(match, "<!--")
-		match = strings.TrimSuffix(match, "-->")
-		urls[i] = match
+	// URLs are in HTML comments, other comments such as the hints of a pull
+	// request template are left out
+	var urls []string
+	for _, match := range re.FindAllStringSubmatch(body, -1) {
+		link := strings.TrimSpace(match[1])
+		parsed, err := url.Parse(link)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			continue
+		}
+		urls = append(urls, link)
 	}
 	return urls
 }
+
+// IsTeamMember checks whether user is an active member of team, given as
+// org/team-slug
+func (p *Provider) IsTeamMember(team, user string) (bool, error) {
+	org, slug, ok := strings.Cut(team, "/")
+	if !ok || org == "" || slug == "" {
+		return false, fmt.Errorf("invalid team %q, expected org/team-slug", team)
+	}
+
+	membership, resp, err := p.Client.Teams.GetTeamMembershipBySlug(p.ctx, org, slug, user)
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("error fetching team membership: %v", err)
+	}
+	return membership.GetState() == "active", nil
+}
@@ -0,0 +1,535 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v60/github"
+	"github.com/mule-ai/mule/pkg/remote/types"
+)
+
+func newTestProvider(t *testing.T, mux *http.ServeMux) *Provider {
+	t.Helper()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	client := github.NewClient(nil)
+	baseURL, _ := url.Parse(server.URL + "/")
+	client.BaseURL = baseURL
+	return &Provider{
+		Client: client,
+		ctx:    context.Background(),
+		owner:  "owner",
+		repo:   "repo",
+	}
+}
+
+func decode(t *testing.T, r *http.Request) map[string]any {
+	t.Helper()
+	var body map[string]any
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		t.Fatalf("error decoding request: %v", err)
+	}
+	return body
+}
+
+func TestIssueWriteOperations(t *testing.T) {
+	var created, edits, comment map[string]any
+	var labelsAdded []string
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /repos/owner/repo/issues", func(w http.ResponseWriter, r *http.Request) {
+		created = decode(t, r)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"number": 12}`))
+	})
+	mux.HandleFunc("PATCH /repos/owner/repo/issues/12", func(w http.ResponseWriter, r *http.Request) {
+		edits = decode(t, r)
+		_, _ = w.Write([]byte(`{"number": 12}`))
+	})
+	mux.HandleFunc("POST /repos/owner/repo/issues/12/labels", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&labelsAdded)
+		_, _ = w.Write([]byte(`[]`))
+	})
+	mux.HandleFunc("POST /repos/other/project/issues/12/comments", func(w http.ResponseWriter, r *http.Request) {
+		comment = decode(t, r)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id": 1}`))
+	})
+	p := newTestProvider(t, mux)
+
+	number, err := p.CreateIssue(types.Issue{Title: "Bug", Body: "Broken", Labels: []string{"mule"}})
+	if err != nil {
+		t.Fatalf("CreateIssue returned error: %v", err)
+	}
+	if number != 12 || created["title"] != "Bug" || created["body"] != "Broken" {
+		t.Errorf("unexpected issue created: %d %v", number, created)
+	}
+	if labels, ok := created["labels"].([]any); !ok || len(labels) != 1 || labels[0] != "mule" {
+		t.Errorf("expected labels on the created issue, got %v", created["labels"])
+	}
+
+	if err := p.AddLabelToIssue(12, "in-progress"); err != nil {
+		t.Fatalf("AddLabelToIssue returned error: %v", err)
+	}
+	if len(labelsAdded) != 1 || labelsAdded[0] != "in-progress" {
+		t.Errorf("unexpected labels added: %v", labelsAdded)
+	}
+
+	if err := p.UpdateIssueState(12, "closed"); err != nil {
+		t.Fatalf("UpdateIssueState returned error: %v", err)
+	}
+	if edits["state"] != "closed" {
+		t.Errorf("expected state to be closed, got %v", edits)
+	}
+	if err := p.UpdateIssueState(12, "merged"); err == nil {
+		t.Errorf("expected an error for an unsupported issue state")
+	}
+
+	if err := p.UpdateIssue(12, "New title", "New body"); err != nil {
+		t.Fatalf("UpdateIssue returned error: %v", err)
+	}
+	if edits["title"] != "New title" || edits["body"] != "New body" {
+		t.Errorf("unexpected issue edit: %v", edits)
+	}
+
+	// the remote path takes precedence over the provider repository
+	if err := p.CreateIssueComment("other/project", 12, types.Comment{Body: "Working on it"}); err != nil {
+		t.Fatalf("CreateIssueComment returned error: %v", err)
+	}
+	if comment["body"] != "Working on it" {
+		t.Errorf("unexpected comment: %v", comment)
+	}
+}
+
+func TestDeleteIssueUsesGraphQL(t *testing.T) {
+	var query map[string]any
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/owner/repo/issues/5", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"number": 5, "node_id": "I_kwDO5"}`))
+	})
+	mux.HandleFunc("POST /graphql", func(w http.ResponseWriter, r *http.Request) {
+		query = decode(t, r)
+		_, _ = w.Write([]byte(`{"data": {"deleteIssue": {"clientMutationId": null}}}`))
+	})
+	p := newTestProvider(t, mux)
+
+	// a local path falls back to the provider repository
+	if err := p.DeleteIssue("/home/user/repo", 5); err != nil {
+		t.Fatalf("DeleteIssue returned error: %v", err)
+	}
+	variables, _ := query["variables"].(map[string]any)
+	if variables["id"] != "I_kwDO5" {
+		t.Errorf("expected the issue node id to be deleted, got %v", query)
+	}
+}
+
+func TestGraphQLErrors(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /graphql", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"errors": [{"message": "not allowed"}]}`))
+	})
+	p := newTestProvider(t, mux)
+
+	err := p.graphQL("query { viewer { login } }", nil, nil)
+	if err == nil || err.Error() != "graphql error: not allowed" {
+		t.Errorf("expected the graphql error to be returned, got %v", err)
+	}
+}
+
+func TestPullRequestWriteOperations(t *testing.T) {
+	var edits, merge, issueComment, reviewComment, reaction map[string]any
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("PATCH /repos/owner/repo/pulls/3", func(w http.ResponseWriter, r *http.Request) {
+		edits = decode(t, r)
+		_, _ = w.Write([]byte(`{"number": 3}`))
+	})
+	mux.HandleFunc("PUT /repos/owner/repo/pulls/3/merge", func(w http.ResponseWriter, r *http.Request) {
+		merge = decode(t, r)
+		_, _ = w.Write([]byte(`{"merged": true}`))
+	})
+	mux.HandleFunc("GET /repos/owner/repo/pulls/3", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"number": 3, "head": {"sha": "abc123"}}`))
+	})
+	mux.HandleFunc("POST /repos/owner/repo/issues/3/comments", func(w http.ResponseWriter, r *http.Request) {
+		issueComment = decode(t, r)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id": 1}`))
+	})
+	mux.HandleFunc("POST /repos/owner/repo/pulls/3/comments", func(w http.ResponseWriter, r *http.Request) {
+		reviewComment = decode(t, r)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id": 2}`))
+	})
+	mux.HandleFunc("POST /repos/owner/repo/pulls/comments/2/reactions", func(w http.ResponseWriter, r *http.Request) {
+		reaction = decode(t, r)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id": 9, "content": "+1"}`))
+	})
+	p := newTestProvider(t, mux)
+
+	if err := p.UpdatePullRequestState("owner/repo", 3, "closed"); err != nil {
+		t.Fatalf("UpdatePullRequestState returned error: %v", err)
+	}
+	if edits["state"] != "closed" {
+		t.Errorf("expected state to be closed, got %v", edits)
+	}
+
+	edits = nil
+	if err := p.DeletePullRequest("owner/repo", 3); err != nil {
+		t.Fatalf("DeletePullRequest returned error: %v", err)
+	}
+	if edits["state"] != "closed" {
+		t.Errorf("expected DeletePullRequest to close the pull request, got %v", edits)
+	}
+
+	if err := p.UpdatePullRequestState("owner/repo", 3, "merged"); err != nil {
+		t.Fatalf("UpdatePullRequestState returned error: %v", err)
+	}
+	if merge == nil {
+		t.Errorf("expected the pull request to be merged")
+	}
+	if err := p.UpdatePullRequestState("owner/repo", 3, "draft"); err == nil {
+		t.Errorf("expected an error for an unsupported pull request state")
+	}
+
+	if err := p.CreatePRComment("owner/repo", 3, types.Comment{Body: "Looks good"}); err != nil {
+		t.Fatalf("CreatePRComment returned error: %v", err)
+	}
+	if issueComment["body"] != "Looks good" {
+		t.Errorf("unexpected PR comment: %v", issueComment)
+	}
+
+	err := p.CreatePRComment("owner/repo", 3, types.Comment{Body: "Rename this", Path: "main.go", Line: 10})
+	if err != nil {
+		t.Fatalf("CreatePRComment returned error: %v", err)
+	}
+	if reviewComment["commit_id"] != "abc123" || reviewComment["path"] != "main.go" ||
+		reviewComment["line"] != float64(10) || reviewComment["side"] != "RIGHT" {
+		t.Errorf("unexpected review comment: %v", reviewComment)
+	}
+
+	if err := p.AddCommentReaction("owner/repo", "+1", 2); err != nil {
+		t.Fatalf("AddCommentReaction returned error: %v", err)
+	}
+	if reaction["content"] != "+1" {
+		t.Errorf("unexpected reaction: %v", reaction)
+	}
+
+	if err := p.ReplyToComment("owner/repo", 3, 2, "Renamed it"); err != nil {
+		t.Fatalf("ReplyToComment returned error: %v", err)
+	}
+	if reviewComment["in_reply_to"] != float64(2) || reviewComment["body"] != "Renamed it" {
+		t.Errorf("unexpected reply: %v", reviewComment)
+	}
+}
+
+func TestOwnerRepo(t *testing.T) {
+	p := &Provider{owner: "owner", repo: "repo"}
+	tests := []struct {
+		remotePath string
+		want       string
+	}{
+		{"other/project", "other/project"},
+		{"", "owner/repo"},
+		{"/home/user/repo", "owner/repo"},
+		{"invalid", "owner/repo"},
+	}
+	for _, tt := range tests {
+		owner, repo, err := p.ownerRepo(tt.remotePath)
+		if err != nil {
+			t.Fatalf("ownerRepo(%q) returned error: %v", tt.remotePath, err)
+		}
+		if owner+"/"+repo != tt.want {
+			t.Errorf("ownerRepo(%q) = %s/%s, want %s", tt.remotePath, owner, repo, tt.want)
+		}
+	}
+
+	if _, _, err := (&Provider{}).ownerRepo(""); err == nil {
+		t.Errorf("expected an error without any repository")
+	}
+}
+
+func TestReviewThreads(t *testing.T) {
+	var resolved map[string]any
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/owner/repo/pulls/3/comments", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[{"id": 11, "body": "rename this"}, {"id": 12, "body": "done", "in_reply_to_id": 11}, {"id": 13, "body": "old"}]`))
+	})
+	mux.HandleFunc("GET /repos/owner/repo/pulls/comments/{id}/reactions", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[{"id": 1, "content": "+1"}]`))
+	})
+	mux.HandleFunc("GET /repos/owner/repo/issues/3/comments", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[]`))
+	})
+	mux.HandleFunc("GET /repos/owner/repo/pulls/3/reviews", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[]`))
+	})
+	mux.HandleFunc("POST /graphql", func(w http.ResponseWriter, r *http.Request) {
+		body := decode(t, r)
+		if strings.HasPrefix(body["query"].(string), "mutation") {
+			resolved = body
+			_, _ = w.Write([]byte(`{"data": {"resolveReviewThread": {"thread": {"id": "T1"}}}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"data": {"repository": {"pullRequest": {"reviewThreads": {
+			"pageInfo": {"hasNextPage": false},
+			"nodes": [
+				{"id": "T1", "isResolved": false, "comments": {"nodes": [{"databaseId": 11}, {"databaseId": 12}]}},
+				{"id": "T2", "isOutdated": true, "comments": {"nodes": [{"databaseId": 13}]}}
+			]}}}}}`))
+	})
+	p := newTestProvider(t, mux)
+
+	comments, err := p.FetchComments("owner", "repo", 3)
+	if err != nil {
+		t.Fatalf("FetchComments returned error: %v", err)
+	}
+	if len(comments) != 3 {
+		t.Fatalf("expected 3 comments, got %d", len(comments))
+	}
+	if comments[0].ThreadID != "T1" || comments[1].ThreadID != "T1" || comments[0].Resolved {
+		t.Errorf("expected the first two comments in the open thread T1, got %+v, %+v", comments[0], comments[1])
+	}
+	if comments[1].InReplyTo != 11 {
+		t.Errorf("expected the second comment to answer the first, got %d", comments[1].InReplyTo)
+	}
+	if comments[2].ThreadID != "T2" || !comments[2].Outdated {
+		t.Errorf("expected the last comment in the outdated thread T2, got %+v", comments[2])
+	}
+
+	if err := p.ResolveComment("owner/repo", 3, 12); err != nil {
+		t.Fatalf("ResolveComment returned error: %v", err)
+	}
+	variables, _ := resolved["variables"].(map[string]any)
+	if variables["id"] != "T1" {
+		t.Errorf("expected thread T1 to be resolved, got %v", resolved)
+	}
+	if err := p.ResolveComment("owner/repo", 3, 99); err == nil {
+		t.Errorf("expected an error for a comment without thread")
+	}
+}
+
+func TestFetchCommentsIncludesGeneralFeedback(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/owner/repo/pulls/3/comments", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[]`))
+	})
+	mux.HandleFunc("GET /repos/owner/repo/issues/3/comments", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[{"id": 21, "body": "please also add tests", "user": {"login": "alice"}, "author_association": "MEMBER"}]`))
+	})
+	mux.HandleFunc("GET /repos/owner/repo/pulls/3/reviews", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[
+			{"id": 31, "body": "The error handling needs work", "state": "CHANGES_REQUESTED", "user": {"login": "bob"}},
+			{"id": 32, "body": "", "state": "COMMENTED"}
+		]`))
+	})
+	p := newTestProvider(t, mux)
+
+	comments, err := p.FetchComments("owner", "repo", 3)
+	if err != nil {
+		t.Fatalf("FetchComments returned error: %v", err)
+	}
+	if len(comments) != 2 {
+		t.Fatalf("expected the conversation comment and the review summary, got %d comments", len(comments))
+	}
+	if comments[0].ID != 21 || comments[0].Kind != types.CommentKindConversation || comments[0].Author != "alice" || comments[0].AuthorAssociation != "MEMBER" {
+		t.Errorf("unexpected conversation comment: %+v", comments[0])
+	}
+	if comments[1].ID != 31 || comments[1].Kind != types.CommentKindReview || comments[1].ReviewState != "CHANGES_REQUESTED" {
+		t.Errorf("unexpected review summary: %+v", comments[1])
+	}
+}
+
+func TestFetchCommentsLeavesOutBots(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /user", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"login": "mule-app[bot]"}`))
+	})
+	mux.HandleFunc("GET /repos/owner/repo/pulls/3/comments", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[]`))
+	})
+	mux.HandleFunc("GET /repos/owner/repo/issues/3/comments", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[
+			{"id": 21, "body": "please also add tests", "user": {"login": "alice"}},
+			{"id": 22, "body": "and update the docs", "user": {"login": "alice"}},
+			{"id": 23, "body": "Coverage dropped", "user": {"login": "codecov[bot]", "type": "Bot"}},
+			{"id": 24, "body": "<!-- mule:reply 21 -->\nAdded tests.", "user": {"login": "mule-app[bot]", "type": "Bot"}}
+		]`))
+	})
+	mux.HandleFunc("GET /repos/owner/repo/pulls/3/reviews", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[{"id": 31, "body": "The error handling needs work", "state": "CHANGES_REQUESTED", "user": {"login": "bob"}}]`))
+	})
+	p := newTestProvider(t, mux)
+
+	comments, err := p.FetchComments("owner", "repo", 3)
+	if err != nil {
+		t.Fatalf("FetchComments returned error: %v", err)
+	}
+	var ids []int64
+	for _, comment := range comments {
+		ids = append(ids, comment.ID)
+	}
+	// the replies of mule are needed to tell what it answered
+	if !slices.Equal(ids, []int64{21, 22, 24, 31}) {
+		t.Errorf("expected the feedback without the other bot, got %v", ids)
+	}
+}
+
+func TestIsTeamMember(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /orgs/mule-ai/teams/maintainers/memberships/alice", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"state": "active", "role": "member"}`))
+	})
+	mux.HandleFunc("GET /orgs/mule-ai/teams/maintainers/memberships/bob", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"state": "pending", "role": "member"}`))
+	})
+	mux.HandleFunc("GET /orgs/mule-ai/teams/maintainers/memberships/mallory", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"message": "Not Found"}`, http.StatusNotFound)
+	})
+	p := newTestProvider(t, mux)
+
+	for user, want := range map[string]bool{"alice": true, "bob": false, "mallory": false} {
+		member, err := p.IsTeamMember("mule-ai/maintainers", user)
+		if err != nil {
+			t.Fatalf("IsTeamMember(%s) returned error: %v", user, err)
+		}
+		if member != want {
+			t.Errorf("IsTeamMember(%s): expected %v, got %v", user, want, member)
+		}
+	}
+	if _, err := p.IsTeamMember("maintainers", "alice"); err == nil {
+		t.Errorf("expected an error for a team without org")
+	}
+}
+
+func TestFetchPullRequestsByLabel(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/owner/repo/pulls", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[
+			{"number": 1, "labels": [{"name": "bug"}, {"name": "mule-pr"}]},
+			{"number": 2, "labels": [{"name": "bug"}]}
+		]`))
+	})
+	for _, endpoint := range []string{"pulls/1/comments", "issues/1/comments", "pulls/1/reviews"} {
+		mux.HandleFunc("GET /repos/owner/repo/"+endpoint, func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`[]`))
+		})
+	}
+	mux.HandleFunc("GET /repos/owner/repo/pulls/1", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("diff --git a/main.go b/main.go"))
+	})
+	p := newTestProvider(t, mux)
+
+	pullRequests, err := p.FetchPullRequests("owner/repo", "mule-pr")
+	if err != nil {
+		t.Fatalf("FetchPullRequests returned error: %v", err)
+	}
+	if len(pullRequests) != 1 || pullRequests[0].Number != 1 {
+		t.Fatalf("expected only the labeled pull request, got %+v", pullRequests)
+	}
+	if labels := pullRequests[0].Labels; len(labels) != 2 || labels[0] != "bug" || labels[1] != "mule-pr" {
+		t.Errorf("unexpected labels: %v", labels)
+	}
+}
+
+func TestRemoveLabelFromIssue(t *testing.T) {
+	mux := http.NewServeMux()
+	var removed []string
+	mux.HandleFunc("DELETE /repos/owner/repo/issues/{number}/labels/{label}", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("number") == "2" {
+			http.Error(w, `{"message": "Label does not exist"}`, http.StatusNotFound)
+			return
+		}
+		removed = append(removed, r.PathValue("label"))
+		_, _ = w.Write([]byte(`[]`))
+	})
+	p := newTestProvider(t, mux)
+
+	if err := p.RemoveLabelFromIssue(1, "mule:in-progress"); err != nil {
+		t.Fatalf("RemoveLabelFromIssue returned error: %v", err)
+	}
+	if err := p.RemoveLabelFromIssue(2, "mule:in-progress"); err != nil {
+		t.Errorf("expected a missing label to be ignored, got %v", err)
+	}
+	if len(removed) != 1 || removed[0] != "mule:in-progress" {
+		t.Errorf("expected the label to be removed from issue 1, got %v", removed)
+	}
+}
+
+func TestFetchPullRequest(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/owner/repo/pulls/{number}", func(w http.ResponseWriter, r *http.Request) {
+		merged := r.PathValue("number") == "1"
+		fmt.Fprintf(w, `{"number": %s, "state": "closed", "merged": %t, "head": {"ref": "fix"}}`, r.PathValue("number"), merged)
+	})
+	p := newTestProvider(t, mux)
+
+	for number, expected := range map[int]string{1: "merged", 2: "closed"} {
+		pr, err := p.FetchPullRequest("owner/repo", number)
+		if err != nil {
+			t.Fatalf("FetchPullRequest returned error: %v", err)
+		}
+		if pr.State != expected || pr.Branch != "fix" {
+			t.Errorf("expected pull request %d to be %s on fix, got %q on %q", number, expected, pr.State, pr.Branch)
+		}
+	}
+}
+
+func TestCurrentUser(t *testing.T) {
+	requests := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /user", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		_, _ = w.Write([]byte(`{"login": "mule-bot"}`))
+	})
+	p := newTestProvider(t, mux)
+
+	for i := 0; i < 2; i++ {
+		login, err := p.CurrentUser()
+		if err != nil {
+			t.Fatalf("CurrentUser returned error: %v", err)
+		}
+		if login != "mule-bot" {
+			t.Errorf("expected mule-bot, got %q", login)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("expected the user to be fetched once, got %d requests", requests)
+	}
+}
+
+// a pull request opened from a typical template, followed by the link mule
+// adds
+const templatePRBody = `## Description
+<!-- Describe your change and why it is needed -->
+Fixes the build.
+
+## Type of change
+<!--
+Please delete options that are not relevant.
+-->
+- [x] Bug fix
+
+## Checklist
+<!-- see https://example.com/contributing for the guidelines -->
+- [x] I have added tests
+
+Closes #7
+<!--https://github.com/owner/repo/issues/7-->`
+
+func TestLinkedIssueURLsOfTemplate(t *testing.T) {
+	urls := getLinkedIssueURLs(templatePRBody)
+	if !slices.Equal(urls, []string{"https://github.com/owner/repo/issues/7"}) {
+		t.Errorf("expected only the linked issue, got %q", urls)
+	}
+}
@@ -0,0 +1,52 @@
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+type graphQLRequest struct {
+	Query     string         `json:"query"`
+	Variables map[string]any `json:"variables,omitempty"`
+}
+
+type graphQLResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// graphQL runs a query against the GitHub GraphQL API and decodes its data
+// into out. It is used for operations the REST API does not offer.
+func (p *Provider) graphQL(query string, variables map[string]any, out any) error {
+	req, err := p.Client.NewRequest("POST", "graphql", &graphQLRequest{
+		Query:     query,
+		Variables: variables,
+	})
+	if err != nil {
+		return fmt.Errorf("error creating graphql request: %v", err)
+	}
+
+	var resp graphQLResponse
+	if _, err := p.Client.Do(p.ctx, req, &resp); err != nil {
+		return err
+	}
+
+	// GraphQL reports errors with a 200 status
+	if len(resp.Errors) > 0 {
+		messages := make([]string, len(resp.Errors))
+		for i, e := range resp.Errors {
+			messages[i] = e.Message
+		}
+		return fmt.Errorf("graphql error: %s", strings.Join(messages, "; "))
+	}
+
+	if out != nil && len(resp.Data) > 0 {
+		if err := json.Unmarshal(resp.Data, out); err != nil {
+			return fmt.Errorf("error decoding graphql response: %v", err)
+		}
+	}
+	return nil
+}
@@ -1,16 +1,36 @@
 package github
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"net/http"
 	"strings"
+	"time"
 
 	"github.com/google/go-github/v60/github"
 	"github.com/mule-ai/mule/pkg/remote/types"
 )
 
 func (p *Provider) CreateIssue(issue types.Issue) (int, error) {
-	return 0, nil
+	owner, repo, err := p.ownerRepo("")
+	if err != nil {
+		return 0, err
+	}
+
+	request := &github.IssueRequest{
+		Title: github.String(issue.Title),
+		Body:  github.String(issue.Body),
+	}
+	if len(issue.Labels) > 0 {
+		request.Labels = &issue.Labels
+	}
+
+	created, _, err := p.Client.Issues.Create(p.ctx, owner, repo, request)
+	if err != nil {
+		return 0, fmt.Errorf("error creating issue: %v", err)
+	}
+	return created.GetNumber(), nil
 }
 
 func (p *Provider) FetchIssues(remotePath string, options types.IssueFilterOptions) ([]types.Issue, error) {
@@ -31,15 +51,13 @@ func (p *Provider) FetchIssues(remotePath string, options types.IssueFilterOptio
 		stateFilter = options.State
 	}
 
-	opt := &github.IssueListByRepoOptions{
-		Labels: labelsFilter,
-		State:  stateFilter,
-		ListOptions: github.ListOptions{
-			PerPage: 100,
-		},
-	}
-
-	ghIssues, _, err := p.Client.Issues.ListByRepo(p.ctx, owner, repo, opt)
+	ghIssues, err := paginate(p, func(ctx context.Context, lo github.ListOptions) ([]*github.Issue, *github.Response, error) {
+		return p.Client.Issues.ListByRepo(ctx, owner, repo, &github.IssueListByRepoOptions{
+			Labels:      labelsFilter,
+			State:       stateFilter,
+			ListOptions: lo,
+		})
+	})
 	if err != nil {
 		log.Printf("Error fetching issues: %v, request: %v", err, remotePath)
 		return nil, fmt.Errorf("error fetching issues: %v", err)
@@ -48,47 +66,167 @@ func (p *Provider) FetchIssues(remotePath string, options types.IssueFilterOptio
 	var issues []types.Issue
 	for _, issue := range ghIssues {
 		i := types.Issue{
-			Number:    issue.GetNumber(),
-			Title:     issue.GetTitle(),
-			Body:      issue.GetBody(),
-			State:     issue.GetState(),
-			HTMLURL:   issue.GetHTMLURL(),
-			SourceURL: issue.GetHTMLURL(),
-			Labels:    []string{},
-			CreatedAt: issue.GetCreatedAt().String(),
-			UpdatedAt: issue.GetUpdatedAt().String(),
+			Number:            issue.GetNumber(),
+			Title:             issue.GetTitle(),
+			Body:              issue.GetBody(),
+			State:             issue.GetState(),
+			HTMLURL:           issue.GetHTMLURL(),
+			SourceURL:         issue.GetHTMLURL(),
+			Labels:            []string{},
+			CreatedAt:         issue.GetCreatedAt().String(),
+			UpdatedAt:         issue.GetUpdatedAt().String(),
+			Author:            issue.GetUser().GetLogin(),
+			AuthorAssociation: issue.GetAuthorAssociation(),
+			AuthorEmail:       noreplyEmail(issue.GetUser()),
 		}
 		for _, label := range issue.Labels {
 			i.Labels = append(i.Labels, label.GetName())
 		}
+		if issue.GetComments() > 0 {
+			i.Comments, err = p.fetchIssueComments(owner, repo, i.Number)
+			if err != nil {
+				log.Printf("Error fetching comments for issue %d: %v", i.Number, err)
+				// Don't return, just log the error and continue
+			}
+		}
 		issues = append(issues, i)
 	}
 
 	return issues, nil
 }
 
+// noreplyEmail is the address GitHub attributes commits to the user with
+// without revealing their email
+func noreplyEmail(user *github.User) string {
+	if user.GetLogin() == "" {
+		return ""
+	}
+	return fmt.Sprintf("%d+%s@users.noreply.github.com", user.GetID(), user.GetLogin())
+}
+
+// fetchIssueComments returns the discussion of an issue, oldest first
+func (p *Provider) fetchIssueComments(owner, repo string, issueNumber int) ([]*types.Comment, error) {
+	ghComments, err := paginate(p, func(ctx context.Context, lo github.ListOptions) ([]*github.IssueComment, *github.Response, error) {
+		return p.Client.Issues.ListComments(ctx, owner, repo, issueNumber, &github.IssueListCommentsOptions{
+			Sort:        github.String("created"),
+			Direction:   github.String("asc"),
+			ListOptions: lo,
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error fetching issue comments: %v", err)
+	}
+
+	comments := make([]*types.Comment, 0, len(ghComments))
+	for _, comment := range ghComments {
+		comments = append(comments, &types.Comment{
+			ID:                comment.GetID(),
+			Body:              comment.GetBody(),
+			HTMLURL:           comment.GetHTMLURL(),
+			URL:               comment.GetURL(),
+			UserID:            comment.GetUser().GetID(),
+			Author:            comment.GetUser().GetLogin(),
+			CreatedAt:         comment.GetCreatedAt().Format(time.RFC3339),
+			AuthorAssociation: comment.GetAuthorAssociation(),
+		})
+	}
+	return comments, nil
+}
+
 func (p *Provider) AddLabelToIssue(issueNumber int, label string) error {
+	owner, repo, err := p.ownerRepo("")
+	if err != nil {
+		return err
+	}
+
+	_, _, err = p.Client.Issues.AddLabelsToIssue(p.ctx, owner, repo, issueNumber, []string{label})
+	if err != nil {
+		return fmt.Errorf("error adding label: %v", err)
+	}
 	return nil
 }
 
-func (p *Provider) UpdateIssueState(issueNumber int, state string) error {
+// RemoveLabelFromIssue removes the label, an issue without it is left as is
+func (p *Provider) RemoveLabelFromIssue(issueNumber int, label string) error {
+	owner, repo, err := p.ownerRepo("")
+	if err != nil {
+		return err
+	}
+
+	resp, err := p.Client.Issues.RemoveLabelForIssue(p.ctx, owner, repo, issueNumber, label)
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error removing label: %v", err)
+	}
 	return nil
 }
 
+func (p *Provider) UpdateIssueState(issueNumber int, state string) error {
+	if state != "open" && state != "closed" {
+		return fmt.Errorf("unsupported issue state: %s", state)
+	}
+	return p.editIssue(issueNumber, &github.IssueRequest{State: github.String(state)})
+}
+
 func (p *Provider) UpdateIssue(issueNumber int, title, body string) error {
-	// For now, we'll implement a stub that returns nil
-	// When GitHub integration is needed, implement the actual update logic
+	return p.editIssue(issueNumber, &github.IssueRequest{
+		Title: github.String(title),
+		Body:  github.String(body),
+	})
+}
+
+func (p *Provider) editIssue(issueNumber int, request *github.IssueRequest) error {
+	owner, repo, err := p.ownerRepo("")
+	if err != nil {
+		return err
+	}
+
+	_, _, err = p.Client.Issues.Edit(p.ctx, owner, repo, issueNumber, request)
+	if err != nil {
+		return fmt.Errorf("error updating issue: %v", err)
+	}
 	return nil
 }
 
 func (p *Provider) CreateIssueComment(remotePath string, issueNumber int, comment types.Comment) error {
+	owner, repo, err := p.ownerRepo(remotePath)
+	if err != nil {
+		return err
+	}
+
+	_, _, err = p.Client.Issues.CreateComment(p.ctx, owner, repo, issueNumber, &github.IssueComment{
+		Body: github.String(comment.Body),
+	})
+	if err != nil {
+		return fmt.Errorf("error creating issue comment: %v", err)
+	}
 	return nil
 }
 
+// DeleteIssue deletes the issue through the GraphQL API, the REST API has no
+// endpoint for it. The token needs admin rights on the repository.
 func (p *Provider) DeleteIssue(repoPath string, issueNumber int) error {
+	owner, repo, err := p.ownerRepo(repoPath)
+	if err != nil {
+		return err
+	}
+
+	issue, _, err := p.Client.Issues.Get(p.ctx, owner, repo, issueNumber)
+	if err != nil {
+		return fmt.Errorf("error fetching issue: %v", err)
+	}
+
+	mutation := `mutation($id: ID!) { deleteIssue(input: {issueId: $id}) { clientMutationId } }`
+	err = p.graphQL(mutation, map[string]any{"id": issue.GetNodeID()}, nil)
+	if err != nil {
+		return fmt.Errorf("error deleting issue: %v", err)
+	}
 	return nil
 }
 
+// DeletePullRequest closes the pull request, GitHub does not allow deleting them
 func (p *Provider) DeletePullRequest(repoPath string, prNumber int) error {
-	return nil
+	return p.UpdatePullRequestState(repoPath, prNumber, "closed")
 }
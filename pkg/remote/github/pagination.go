@@ -0,0 +1,62 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/go-github/v60/github"
+)
+
+// the largest page size GitHub allows
+const perPage = 100
+
+// PaginationOptions bounds how much of a list endpoint is walked. A zero
+// MaxPages walks every page and a zero Timeout sets no deadline.
+type PaginationOptions struct {
+	MaxPages int
+	Timeout  time.Duration
+}
+
+// DefaultPaginationOptions walks every page but gives up on a list call that
+// takes longer than a couple of minutes
+var DefaultPaginationOptions = PaginationOptions{
+	Timeout: 2 * time.Minute,
+}
+
+// listFunc fetches a single page of a list endpoint
+type listFunc[T any] func(ctx context.Context, opt github.ListOptions) ([]T, *github.Response, error)
+
+// paginate follows the next page links of a list endpoint until the last
+// page, the page cap or the deadline is reached
+func paginate[T any](p *Provider, list listFunc[T]) ([]T, error) {
+	ctx := p.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if p.Pagination.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.Pagination.Timeout)
+		defer cancel()
+	}
+
+	var all []T
+	opt := github.ListOptions{PerPage: perPage}
+	for pages := 1; ; pages++ {
+		items, resp, err := list(ctx, opt)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching page %d: %v", pages, err)
+		}
+		all = append(all, items...)
+
+		if resp == nil || resp.NextPage == 0 {
+			return all, nil
+		}
+		if p.Pagination.MaxPages > 0 && pages >= p.Pagination.MaxPages {
+			log.Printf("Stopping after %d pages, results are truncated", pages)
+			return all, nil
+		}
+		opt.Page = resp.NextPage
+	}
+}
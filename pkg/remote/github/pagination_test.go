@@ -0,0 +1,290 @@
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mule-ai/mule/pkg/remote/types"
+)
+
+// paginatingHandler serves total items numbered from 1, size per page, and
+// links to the next page the way the GitHub API does
+func paginatingHandler(t *testing.T, total, size int, item func(n int) map[string]any) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("per_page") != strconv.Itoa(perPage) {
+			t.Errorf("expected per_page=%d, got %q", perPage, r.URL.RawQuery)
+		}
+		page := 1
+		if p := r.URL.Query().Get("page"); p != "" {
+			page, _ = strconv.Atoi(p)
+		}
+
+		var items []map[string]any
+		for n := (page-1)*size + 1; n <= page*size && n <= total; n++ {
+			items = append(items, item(n))
+		}
+		if page*size < total {
+			next := *r.URL
+			query := next.Query()
+			query.Set("page", strconv.Itoa(page+1))
+			next.RawQuery = query.Encode()
+			w.Header().Set("Link", fmt.Sprintf(`<http://%s%s>; rel="next"`, r.Host, next.RequestURI()))
+		}
+		_ = json.NewEncoder(w).Encode(items)
+	}
+}
+
+func TestFetchIssuesWalksEveryPage(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/owner/repo/issues", paginatingHandler(t, 250, perPage, func(n int) map[string]any {
+		return map[string]any{"number": n, "state": "open"}
+	}))
+	p := newTestProvider(t, mux)
+
+	issues, err := p.FetchIssues("owner/repo", types.IssueFilterOptions{})
+	if err != nil {
+		t.Fatalf("FetchIssues returned error: %v", err)
+	}
+	if len(issues) != 250 {
+		t.Fatalf("expected 250 issues, got %d", len(issues))
+	}
+	if issues[249].Number != 250 {
+		t.Errorf("expected the last issue to be 250, got %d", issues[249].Number)
+	}
+}
+
+func TestFetchIssueCommentsWalksEveryPage(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/owner/repo/issues", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode([]map[string]any{
+			{"number": 1, "state": "open", "comments": 120},
+			{"number": 2, "state": "open", "comments": 0},
+		})
+	})
+	mux.HandleFunc("GET /repos/owner/repo/issues/1/comments", paginatingHandler(t, 120, perPage, func(n int) map[string]any {
+		return map[string]any{
+			"id":         n,
+			"body":       fmt.Sprintf("comment %d", n),
+			"user":       map[string]any{"id": 9, "login": "alice"},
+			"created_at": "2024-03-01T08:00:00Z",
+		}
+	}))
+	p := newTestProvider(t, mux)
+
+	issues, err := p.FetchIssues("owner/repo", types.IssueFilterOptions{})
+	if err != nil {
+		t.Fatalf("FetchIssues returned error: %v", err)
+	}
+	if len(issues[0].Comments) != 120 || len(issues[1].Comments) != 0 {
+		t.Fatalf("expected 120 comments on the first issue only, got %d and %d", len(issues[0].Comments), len(issues[1].Comments))
+	}
+	last := issues[0].Comments[119]
+	if last.Body != "comment 120" || last.Author != "alice" || last.CreatedAt != "2024-03-01T08:00:00Z" {
+		t.Errorf("unexpected comment: %+v", last)
+	}
+}
+
+func TestFetchIssuesSurvivesFailingComments(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/owner/repo/issues", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode([]map[string]any{
+			{"number": 1, "state": "open", "comments": 1},
+			{"number": 2, "state": "open", "comments": 1},
+		})
+	})
+	mux.HandleFunc("GET /repos/owner/repo/issues/1/comments", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"message": "Server Error"}`, http.StatusInternalServerError)
+	})
+	mux.HandleFunc("GET /repos/owner/repo/issues/2/comments", paginatingHandler(t, 1, perPage, func(n int) map[string]any {
+		return map[string]any{"id": n, "body": "comment", "user": map[string]any{"login": "alice"}}
+	}))
+	p := newTestProvider(t, mux)
+
+	issues, err := p.FetchIssues("owner/repo", types.IssueFilterOptions{})
+	if err != nil {
+		t.Fatalf("FetchIssues returned error: %v", err)
+	}
+	if len(issues) != 2 || len(issues[0].Comments) != 0 || len(issues[1].Comments) != 1 {
+		t.Fatalf("expected both issues with the comments that could be fetched, got %+v", issues)
+	}
+}
+
+func TestFetchCommentsWalksEveryPage(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/owner/repo/pulls/3/comments", paginatingHandler(t, 150, perPage, func(n int) map[string]any {
+		return map[string]any{"id": n, "body": "comment"}
+	}))
+	mux.HandleFunc("GET /repos/owner/repo/pulls/comments/{id}/reactions", func(w http.ResponseWriter, r *http.Request) {
+		// only the first comment has more reactions than fit on a page
+		if r.PathValue("id") != "1" {
+			_, _ = w.Write([]byte(`[]`))
+			return
+		}
+		paginatingHandler(t, 120, perPage, func(n int) map[string]any {
+			return map[string]any{"id": n, "content": "+1"}
+		})(w, r)
+	})
+	mux.HandleFunc("GET /repos/owner/repo/issues/3/comments", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[]`))
+	})
+	mux.HandleFunc("GET /repos/owner/repo/pulls/3/reviews", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[]`))
+	})
+	p := newTestProvider(t, mux)
+
+	comments, err := p.FetchComments("owner", "repo", 3)
+	if err != nil {
+		t.Fatalf("FetchComments returned error: %v", err)
+	}
+	if len(comments) != 150 {
+		t.Fatalf("expected 150 comments, got %d", len(comments))
+	}
+	if comments[0].Reactions.PlusOne != 120 {
+		t.Errorf("expected 120 reactions on the first comment, got %d", comments[0].Reactions.PlusOne)
+	}
+}
+
+func TestFetchRepositoriesWalksEveryPage(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /user/repos", paginatingHandler(t, 101, perPage, func(n int) map[string]any {
+		return map[string]any{"name": fmt.Sprintf("repo-%d", n)}
+	}))
+	p := newTestProvider(t, mux)
+
+	repos, err := p.FetchRepositories()
+	if err != nil {
+		t.Fatalf("FetchRepositories returned error: %v", err)
+	}
+	if len(repos) != 101 {
+		t.Fatalf("expected 101 repositories, got %d", len(repos))
+	}
+}
+
+func TestPaginationMaxPages(t *testing.T) {
+	requests := 0
+	handler := paginatingHandler(t, 500, perPage, func(n int) map[string]any {
+		return map[string]any{"number": n, "state": "open"}
+	})
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/owner/repo/pulls", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		handler(w, r)
+	})
+	mux.HandleFunc("GET /repos/owner/repo/pulls/{number}/comments", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[]`))
+	})
+	mux.HandleFunc("GET /repos/owner/repo/issues/{number}/comments", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[]`))
+	})
+	mux.HandleFunc("GET /repos/owner/repo/pulls/{number}/reviews", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[]`))
+	})
+	mux.HandleFunc("GET /repos/owner/repo/pulls/{number}", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`diff`))
+	})
+	p := newTestProvider(t, mux)
+	p.Pagination.MaxPages = 2
+
+	pullRequests, err := p.FetchPullRequests("owner/repo", "")
+	if err != nil {
+		t.Fatalf("FetchPullRequests returned error: %v", err)
+	}
+	if requests != 2 {
+		t.Errorf("expected 2 page requests, got %d", requests)
+	}
+	if len(pullRequests) != 2*perPage {
+		t.Errorf("expected %d pull requests, got %d", 2*perPage, len(pullRequests))
+	}
+}
+
+func TestPaginationTimeout(t *testing.T) {
+	handler := paginatingHandler(t, 500, perPage, func(n int) map[string]any {
+		return map[string]any{"number": n, "state": "open"}
+	})
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/owner/repo/issues", func(w http.ResponseWriter, r *http.Request) {
+		// later pages are slower than the deadline
+		if r.URL.Query().Get("page") != "" {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(time.Second):
+			}
+		}
+		handler(w, r)
+	})
+	p := newTestProvider(t, mux)
+	p.Pagination.Timeout = 50 * time.Millisecond
+
+	_, err := p.FetchIssues("owner/repo", types.IssueFilterOptions{})
+	if err == nil || !strings.Contains(err.Error(), "deadline exceeded") {
+		t.Errorf("expected a deadline error, got %v", err)
+	}
+}
+
+func TestReviewThreadCommentsWalkEveryPage(t *testing.T) {
+	reactionRequests := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/owner/repo/pulls/3/comments", paginatingHandler(t, 150, perPage, func(n int) map[string]any {
+		return map[string]any{"id": n, "body": "comment"}
+	}))
+	mux.HandleFunc("GET /repos/owner/repo/pulls/comments/{id}/reactions", func(w http.ResponseWriter, r *http.Request) {
+		reactionRequests++
+		_, _ = w.Write([]byte(`[]`))
+	})
+	mux.HandleFunc("GET /repos/owner/repo/issues/3/comments", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[]`))
+	})
+	mux.HandleFunc("GET /repos/owner/repo/pulls/3/reviews", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[]`))
+	})
+	// one resolved thread holds all comments, 100 of them per page
+	page := func(from, to int, next bool) map[string]any {
+		var nodes []map[string]any
+		for n := from; n <= to; n++ {
+			nodes = append(nodes, map[string]any{"databaseId": n})
+		}
+		return map[string]any{"pageInfo": map[string]any{"hasNextPage": next, "endCursor": "c100"}, "nodes": nodes}
+	}
+	mux.HandleFunc("POST /graphql", func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Query     string         `json:"query"`
+			Variables map[string]any `json:"variables"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		var data map[string]any
+		if strings.Contains(body.Query, "node(id: $id)") {
+			if body.Variables["id"] != "T1" || body.Variables["cursor"] != "c100" {
+				t.Errorf("unexpected thread comments request: %v", body.Variables)
+			}
+			data = map[string]any{"node": map[string]any{"comments": page(101, 150, false)}}
+		} else {
+			data = map[string]any{"repository": map[string]any{"pullRequest": map[string]any{"reviewThreads": map[string]any{
+				"pageInfo": map[string]any{"hasNextPage": false},
+				"nodes":    []map[string]any{{"id": "T1", "isResolved": true, "comments": page(1, 100, true)}},
+			}}}}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"data": data})
+	})
+	p := newTestProvider(t, mux)
+
+	comments, err := p.FetchComments("owner", "repo", 3)
+	if err != nil {
+		t.Fatalf("FetchComments returned error: %v", err)
+	}
+	if len(comments) != 150 {
+		t.Fatalf("expected 150 comments, got %d", len(comments))
+	}
+	for _, comment := range comments {
+		if comment.ThreadID != "T1" || !comment.Resolved {
+			t.Fatalf("expected every comment in the resolved thread, got %+v", comment)
+		}
+	}
+	if reactionRequests != 0 {
+		t.Errorf("expected no reactions to be fetched with threads, got %d requests", reactionRequests)
+	}
+}
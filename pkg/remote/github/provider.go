@@ -2,20 +2,36 @@ package github
 
 import (
 	"context"
+	"fmt"
 	"strings"
+	"sync"
 
 	"github.com/google/go-github/v60/github"
+	"golang.org/x/oauth2"
 )
 
 type Provider struct {
-	Client *github.Client
-	ctx    context.Context
-	owner  string
-	repo   string
+	Client      *github.Client
+	Pagination  PaginationOptions
+	ctx         context.Context
+	owner       string
+	repo        string
+	tokenSource oauth2.TokenSource
+	// app is set when the provider authenticates as a GitHub App installation
+	app *installationTokenSource
+	// currentUser caches the login the provider acts as
+	userMu      sync.Mutex
+	currentUser string
 }
 
 // creates a new github client so we can communicate with it's apis
 func NewProvider(path, token string) *Provider {
+	return newProviderWithTokenSource(path, oauth2.StaticTokenSource(
+		&oauth2.Token{AccessToken: token},
+	))
+}
+
+func newProviderWithTokenSource(path string, ts oauth2.TokenSource) *Provider {
 	parts := strings.Split(path, "/")
 	var owner, repo string
 	if len(parts) < 2 {
@@ -26,9 +42,71 @@ func NewProvider(path, token string) *Provider {
 		repo = parts[1]
 	}
 	return &Provider{
-		Client: newGitHubClient(context.Background(), token),
-		ctx:    context.Background(),
-		owner:  owner,
-		repo:   repo,
+		Client:      newGitHubClient(context.Background(), ts),
+		Pagination:  DefaultPaginationOptions,
+		ctx:         context.Background(),
+		owner:       owner,
+		repo:        repo,
+		tokenSource: ts,
+	}
+}
+
+// GitCredentials returns the basic auth credentials for git over HTTPS. For a
+// GitHub App this is the current installation token, so pushes are made by
+// the app's bot account.
+func (p *Provider) GitCredentials() (string, string, error) {
+	if p.tokenSource == nil {
+		return "", "", fmt.Errorf("github provider has no credentials")
+	}
+	token, err := p.tokenSource.Token()
+	if err != nil {
+		return "", "", fmt.Errorf("error getting github token: %v", err)
+	}
+	if token.AccessToken == "" {
+		return "", "", fmt.Errorf("github token is not set")
+	}
+	return "x-access-token", token.AccessToken, nil
+}
+
+// ownerRepo resolves the repository to address from the remote path passed by
+// the caller, falling back to the one the provider was created with when the
+// path is empty or a local path
+func (p *Provider) ownerRepo(remotePath string) (string, string, error) {
+	if remotePath != "" && !strings.HasPrefix(remotePath, "/") {
+		parts := strings.Split(strings.Trim(remotePath, "/"), "/")
+		if len(parts) >= 2 && parts[0] != "" && parts[1] != "" {
+			return parts[0], parts[1], nil
+		}
+	}
+	if p.owner == "" || p.repo == "" {
+		return "", "", fmt.Errorf("invalid remote path format")
+	}
+	return p.owner, p.repo, nil
+}
+
+// CurrentUser returns the login the provider acts as, for a GitHub App
+// installation the login of its bot account
+func (p *Provider) CurrentUser() (string, error) {
+	p.userMu.Lock()
+	defer p.userMu.Unlock()
+	if p.currentUser != "" {
+		return p.currentUser, nil
+	}
+
+	var login string
+	if p.app != nil {
+		var err error
+		login, err = p.app.botLogin()
+		if err != nil {
+			return "", err
+		}
+	} else {
+		user, _, err := p.Client.Users.Get(p.ctx, "")
+		if err != nil {
+			return "", fmt.Errorf("error fetching authenticated user: %v", err)
+		}
+		login = user.GetLogin()
 	}
+	p.currentUser = login
+	return login, nil
 }
@@ -0,0 +1,159 @@
+package github
+
+import (
+	"fmt"
+)
+
+/*
+Review threads are only exposed by the GraphQL API. They tell which review
+comments are resolved or outdated, and resolving a thread is a GraphQL
+mutation as well.
+
+fetchReviewThreads: Maps the ID of every review comment of a pull request to its thread, paging through threads and their comments.
+ResolveComment: Resolves the review thread of a comment.
+*/
+
+type reviewThread struct {
+	ID         string         `json:"id"`
+	IsResolved bool           `json:"isResolved"`
+	IsOutdated bool           `json:"isOutdated"`
+	Comments   threadComments `json:"comments"`
+}
+
+// threadComments is a page of the comments of a review thread
+type threadComments struct {
+	PageInfo struct {
+		HasNextPage bool   `json:"hasNextPage"`
+		EndCursor   string `json:"endCursor"`
+	} `json:"pageInfo"`
+	Nodes []struct {
+		DatabaseID int64 `json:"databaseId"`
+	} `json:"nodes"`
+}
+
+const reviewThreadsQuery = `query($owner: String!, $repo: String!, $number: Int!, $cursor: String) {
+  repository(owner: $owner, name: $repo) {
+    pullRequest(number: $number) {
+      reviewThreads(first: 100, after: $cursor) {
+        pageInfo { hasNextPage endCursor }
+        nodes {
+          id
+          isResolved
+          isOutdated
+          comments(first: 100) {
+            pageInfo { hasNextPage endCursor }
+            nodes { databaseId }
+          }
+        }
+      }
+    }
+  }
+}`
+
+// threadCommentsQuery pages through the comments of a thread with more than
+// fit on the page of the thread
+const threadCommentsQuery = `query($id: ID!, $cursor: String) {
+  node(id: $id) {
+    ... on PullRequestReviewThread {
+      comments(first: 100, after: $cursor) {
+        pageInfo { hasNextPage endCursor }
+        nodes { databaseId }
+      }
+    }
+  }
+}`
+
+const resolveReviewThreadMutation = `mutation($id: ID!) {
+  resolveReviewThread(input: {threadId: $id}) { thread { id } }
+}`
+
+func (p *Provider) fetchReviewThreads(owner, repo string, prNumber int) (map[int64]reviewThread, error) {
+	threads := make(map[int64]reviewThread)
+	var cursor *string
+	for page := 0; p.Pagination.MaxPages <= 0 || page < p.Pagination.MaxPages; page++ {
+		var data struct {
+			Repository struct {
+				PullRequest struct {
+					ReviewThreads struct {
+						PageInfo struct {
+							HasNextPage bool   `json:"hasNextPage"`
+							EndCursor   string `json:"endCursor"`
+						} `json:"pageInfo"`
+						Nodes []reviewThread `json:"nodes"`
+					} `json:"reviewThreads"`
+				} `json:"pullRequest"`
+			} `json:"repository"`
+		}
+		err := p.graphQL(reviewThreadsQuery, map[string]any{
+			"owner":  owner,
+			"repo":   repo,
+			"number": prNumber,
+			"cursor": cursor,
+		}, &data)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching review threads: %v", err)
+		}
+
+		reviewThreads := data.Repository.PullRequest.ReviewThreads
+		for _, thread := range reviewThreads.Nodes {
+			comments := thread.Comments
+			for {
+				for _, comment := range comments.Nodes {
+					threads[comment.DatabaseID] = thread
+				}
+				if !comments.PageInfo.HasNextPage {
+					break
+				}
+				comments, err = p.fetchThreadComments(thread.ID, comments.PageInfo.EndCursor)
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+		if !reviewThreads.PageInfo.HasNextPage {
+			break
+		}
+		cursor = &reviewThreads.PageInfo.EndCursor
+	}
+	return threads, nil
+}
+
+// fetchThreadComments returns the page of comments of the thread after the
+// cursor
+func (p *Provider) fetchThreadComments(threadID, cursor string) (threadComments, error) {
+	var data struct {
+		Node struct {
+			Comments threadComments `json:"comments"`
+		} `json:"node"`
+	}
+	err := p.graphQL(threadCommentsQuery, map[string]any{"id": threadID, "cursor": cursor}, &data)
+	if err != nil {
+		return threadComments{}, fmt.Errorf("error fetching the comments of review thread %s: %v", threadID, err)
+	}
+	return data.Node.Comments, nil
+}
+
+func (p *Provider) ResolveComment(remotePath string, prNumber int, commentID int64) error {
+	owner, repo, err := p.ownerRepo(remotePath)
+	if err != nil {
+		return err
+	}
+
+	threads, err := p.fetchReviewThreads(owner, repo, prNumber)
+	if err != nil {
+		return err
+	}
+	thread, ok := threads[commentID]
+	if !ok {
+		return fmt.Errorf("no review thread found for comment %d", commentID)
+	}
+	if thread.IsResolved {
+		return nil
+	}
+
+	err = p.graphQL(resolveReviewThreadMutation, map[string]any{"id": thread.ID}, nil)
+	if err != nil {
+		return fmt.Errorf("error resolving review thread: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,286 @@
+package gitlab
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/mule-ai/mule/pkg/remote/types"
+)
+
+type glPosition struct {
+	PositionType string `json:"position_type,omitempty"`
+	BaseSHA      string `json:"base_sha,omitempty"`
+	StartSHA     string `json:"start_sha,omitempty"`
+	HeadSHA      string `json:"head_sha,omitempty"`
+	OldPath      string `json:"old_path,omitempty"`
+	NewPath      string `json:"new_path,omitempty"`
+	OldLine      int    `json:"old_line,omitempty"`
+	NewLine      int    `json:"new_line,omitempty"`
+}
+
+type glNote struct {
+	ID        int64       `json:"id"`
+	Body      string      `json:"body"`
+	Author    glUser      `json:"author"`
+	System    bool        `json:"system"`
+	CreatedAt string      `json:"created_at"`
+	Position  *glPosition `json:"position,omitempty"`
+}
+
+type glDiscussion struct {
+	ID    string   `json:"id"`
+	Notes []glNote `json:"notes"`
+}
+
+type glAwardEmoji struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+// GitLab award emoji names for the reactions used by the provider interface
+var reactionToEmoji = map[string]string{
+	"+1":       "thumbsup",
+	"-1":       "thumbsdown",
+	"laugh":    "laughing",
+	"confused": "confused",
+	"heart":    "heart",
+	"hooray":   "tada",
+	"rocket":   "rocket",
+	"eyes":     "eyes",
+}
+
+/*
+FetchComments: Retrieves the discussion notes of a merge request together with their award emoji.
+AddCommentReaction: Awards an emoji to a merge request note, "+1" marks the note as acknowledged.
+CreatePRComment: Starts a discussion on a merge request, anchored to a diff line when a path is given.
+ReplyToComment: Adds a note to the discussion of a merge request note.
+ResolveComment: Resolves the discussion of a merge request note.
+*/
+func (p *Provider) FetchComments(owner, repo string, prNumber int) ([]*types.Comment, error) {
+	project := p.projectPath(joinProject(owner, repo))
+	endpoint := projectEndpoint(project, "merge_requests", strconv.Itoa(prNumber), "discussions")
+	discussions, err := getAll[glDiscussion](p, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching comments: %v", err)
+	}
+
+	var diff string
+	var comments []*types.Comment
+	for _, discussion := range discussions {
+		var firstNote int64
+		var kind string
+		for _, note := range discussion.Notes {
+			if note.System {
+				continue
+			}
+			p.rememberNote(note.ID, noteRef{
+				project:      project,
+				mergeRequest: prNumber,
+				discussionID: discussion.ID,
+			})
+			c := &types.Comment{
+				ID:        note.ID,
+				Body:      note.Body,
+				UserID:    note.Author.ID,
+				Author:    note.Author.Username,
+				CreatedAt: note.CreatedAt,
+				InReplyTo: firstNote,
+			}
+			// the other notes of a discussion answer its first one, which is
+			// general feedback unless it is on a diff line
+			if firstNote == 0 {
+				firstNote = note.ID
+				if note.Position == nil {
+					kind = types.CommentKindConversation
+				}
+			}
+			c.Kind = kind
+			if note.Position != nil {
+				c.Path = note.Position.NewPath
+				c.Line = note.Position.NewLine
+				if diff == "" {
+					diff, err = p.FetchDiffs(owner, repo, prNumber)
+					if err != nil {
+						return nil, err
+					}
+				}
+				c.DiffHunk = hunkForLine(diff, c.Path, c.Line)
+			}
+			reactions, err := p.fetchNoteReactions(project, prNumber, note.ID)
+			if err != nil {
+				return nil, fmt.Errorf("error fetching reactions: %v", err)
+			}
+			c.Reactions = reactions
+			comments = append(comments, c)
+		}
+	}
+	return comments, nil
+}
+
+func (p *Provider) fetchNoteReactions(project string, prNumber int, noteID int64) (types.Reactions, error) {
+	endpoint := projectEndpoint(project, "merge_requests", strconv.Itoa(prNumber), "notes", strconv.FormatInt(noteID, 10), "award_emoji")
+	emoji, err := getAll[glAwardEmoji](p, endpoint, nil)
+	if err != nil {
+		return types.Reactions{}, err
+	}
+
+	reactions := types.Reactions{}
+	for _, e := range emoji {
+		reactions.TotalCount++
+		switch e.Name {
+		case "thumbsup":
+			reactions.PlusOne++
+		case "thumbsdown":
+			reactions.MinusOne++
+		case "laughing":
+			reactions.Laugh++
+		case "confused":
+			reactions.Confused++
+		case "heart":
+			reactions.Heart++
+		case "tada":
+			reactions.Hooray++
+		case "rocket":
+			reactions.Rocket++
+		case "eyes":
+			reactions.Eyes++
+		}
+	}
+	return reactions, nil
+}
+
+func (p *Provider) AddCommentReaction(repoPath, reaction string, commentID int64) error {
+	ref, ok := p.lookupNote(commentID)
+	if !ok {
+		return fmt.Errorf("comment %d not found, fetch the merge request comments first", commentID)
+	}
+	name, ok := reactionToEmoji[reaction]
+	if !ok {
+		name = reaction
+	}
+
+	endpoint := projectEndpoint(ref.project, "merge_requests", strconv.Itoa(ref.mergeRequest), "notes", strconv.FormatInt(commentID, 10), "award_emoji")
+	_, err := p.do(http.MethodPost, endpoint, nil, map[string]string{"name": name}, nil)
+	if err != nil {
+		return fmt.Errorf("error adding reaction: %v", err)
+	}
+	return nil
+}
+
+func (p *Provider) CreatePRComment(remotePath string, prNumber int, comment types.Comment) error {
+	project := p.projectPath(remotePath)
+	mrEndpoint := projectEndpoint(project, "merge_requests", strconv.Itoa(prNumber))
+
+	request := map[string]any{"body": comment.Body}
+	if comment.Path != "" && comment.Line > 0 {
+		var mr glMergeRequest
+		if _, err := p.do(http.MethodGet, mrEndpoint, nil, nil, &mr); err != nil {
+			return fmt.Errorf("error fetching merge request: %v", err)
+		}
+		request["position"] = glPosition{
+			PositionType: "text",
+			BaseSHA:      mr.DiffRefs.BaseSHA,
+			StartSHA:     mr.DiffRefs.StartSHA,
+			HeadSHA:      mr.DiffRefs.HeadSHA,
+			OldPath:      comment.Path,
+			NewPath:      comment.Path,
+			NewLine:      comment.Line,
+		}
+	}
+
+	_, err := p.do(http.MethodPost, mrEndpoint+"/discussions", nil, request, nil)
+	if err != nil {
+		return fmt.Errorf("error creating merge request comment: %v", err)
+	}
+	return nil
+}
+
+func (p *Provider) ReplyToComment(remotePath string, prNumber int, commentID int64, body string) error {
+	ref, ok := p.lookupNote(commentID)
+	if !ok {
+		return fmt.Errorf("comment %d not found, fetch the merge request comments first", commentID)
+	}
+
+	endpoint := projectEndpoint(ref.project, "merge_requests", strconv.Itoa(ref.mergeRequest), "discussions", ref.discussionID, "notes")
+	_, err := p.do(http.MethodPost, endpoint, nil, map[string]string{"body": body}, nil)
+	if err != nil {
+		return fmt.Errorf("error replying to comment: %v", err)
+	}
+	return nil
+}
+
+func (p *Provider) ResolveComment(remotePath string, prNumber int, commentID int64) error {
+	ref, ok := p.lookupNote(commentID)
+	if !ok {
+		return fmt.Errorf("comment %d not found, fetch the merge request comments first", commentID)
+	}
+
+	endpoint := projectEndpoint(ref.project, "merge_requests", strconv.Itoa(ref.mergeRequest), "discussions", ref.discussionID)
+	query := url.Values{"resolved": {"true"}}
+	_, err := p.do(http.MethodPut, endpoint, query, nil, nil)
+	if err != nil {
+		return fmt.Errorf("error resolving discussion: %v", err)
+	}
+	return nil
+}
+
+// hunkForLine returns the hunk of the unified diff that touches line of path
+// in the new version of the file
+func hunkForLine(diff, path string, line int) string {
+	var inFile bool
+	var hunk []string
+	var start, length int
+	found := func() bool {
+		return len(hunk) > 0 && line >= start && line < start+length
+	}
+
+	for _, l := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(l, "diff --git "):
+			if found() {
+				return strings.Join(hunk, "\n")
+			}
+			inFile = strings.HasSuffix(l, " b/"+path)
+			hunk = nil
+		case inFile && strings.HasPrefix(l, "@@"):
+			if found() {
+				return strings.Join(hunk, "\n")
+			}
+			start, length = parseHunkHeader(l)
+			hunk = []string{l}
+		case inFile && len(hunk) > 0:
+			hunk = append(hunk, l)
+		}
+	}
+	if found() {
+		return strings.TrimRight(strings.Join(hunk, "\n"), "\n")
+	}
+	return ""
+}
+
+// parseHunkHeader reads the new file range from a header like @@ -1,4 +1,5 @@
+func parseHunkHeader(header string) (int, int) {
+	fields := strings.Fields(header)
+	for _, field := range fields {
+		if !strings.HasPrefix(field, "+") {
+			continue
+		}
+		start, length, found := strings.Cut(strings.TrimPrefix(field, "+"), ",")
+		s, err := strconv.Atoi(start)
+		if err != nil {
+			return 0, 0
+		}
+		if !found {
+			return s, 1
+		}
+		n, err := strconv.Atoi(length)
+		if err != nil {
+			return 0, 0
+		}
+		return s, n
+	}
+	return 0, 0
+}
@@ -0,0 +1,419 @@
+package gitlab
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/mule-ai/mule/pkg/remote/types"
+)
+
+const perPage = 100
+
+var re = regexp.MustCompile(`<!--(.*?)-->`)
+
+type glUser struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+}
+
+type glDiffRefs struct {
+	BaseSHA  string `json:"base_sha"`
+	HeadSHA  string `json:"head_sha"`
+	StartSHA string `json:"start_sha"`
+}
+
+type glMergeRequest struct {
+	IID          int        `json:"iid"`
+	Title        string     `json:"title"`
+	Description  string     `json:"description"`
+	State        string     `json:"state"`
+	WebURL       string     `json:"web_url"`
+	Labels       []string   `json:"labels"`
+	CreatedAt    string     `json:"created_at"`
+	UpdatedAt    string     `json:"updated_at"`
+	SourceBranch string     `json:"source_branch"`
+	TargetBranch string     `json:"target_branch"`
+	DiffRefs     glDiffRefs `json:"diff_refs"`
+}
+
+type glDiff struct {
+	OldPath     string `json:"old_path"`
+	NewPath     string `json:"new_path"`
+	Diff        string `json:"diff"`
+	NewFile     bool   `json:"new_file"`
+	DeletedFile bool   `json:"deleted_file"`
+}
+
+type glProject struct {
+	Name              string `json:"name"`
+	PathWithNamespace string `json:"path_with_namespace"`
+	Description       string `json:"description"`
+	HTTPURLToRepo     string `json:"http_url_to_repo"`
+	SSHURLToRepo      string `json:"ssh_url_to_repo"`
+}
+
+// apiError is returned when GitLab answers with a non 2xx status
+type apiError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("gitlab api returned %d: %s", e.StatusCode, e.Body)
+}
+
+func projectEndpoint(project string, parts ...string) string {
+	return "projects/" + url.PathEscape(project) + "/" + strings.Join(parts, "/")
+}
+
+// do sends a request to the GitLab API and decodes the JSON response into out
+func (p *Provider) do(method, endpoint string, query url.Values, body, out any) (*http.Response, error) {
+	u := p.baseURL + "/" + strings.TrimPrefix(endpoint, "/")
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("error encoding request: %v", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(p.ctx, method, u, reader)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if p.token != "" {
+		req.Header.Set("PRIVATE-TOKEN", p.token)
+	}
+
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return resp, fmt.Errorf("error reading response: %v", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp, &apiError{StatusCode: resp.StatusCode, Body: string(data)}
+	}
+	if out != nil && len(data) > 0 {
+		if err := json.Unmarshal(data, out); err != nil {
+			return resp, fmt.Errorf("error decoding response: %v", err)
+		}
+	}
+	return resp, nil
+}
+
+// getAll follows GitLab's X-Next-Page header until every page has been read
+func getAll[T any](p *Provider, endpoint string, query url.Values) ([]T, error) {
+	if query == nil {
+		query = url.Values{}
+	}
+	query.Set("per_page", strconv.Itoa(perPage))
+
+	var all []T
+	page := "1"
+	for page != "" {
+		query.Set("page", page)
+		var items []T
+		resp, err := p.do(http.MethodGet, endpoint, query, nil, &items)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, items...)
+		page = resp.Header.Get("X-Next-Page")
+	}
+	return all, nil
+}
+
+func (p *Provider) CreateDraftPR(path string, input types.PullRequestInput) (int, error) {
+	project, err := projectFromLocalRepository(path)
+	if err != nil {
+		log.Printf("Could not determine project from %s, using %s: %v", path, p.project, err)
+		project = p.project
+	}
+
+	title := input.Title
+	if input.Draft && !strings.HasPrefix(title, "Draft:") {
+		title = "Draft: " + title
+	}
+
+	request := map[string]any{
+		"source_branch":       input.Branch,
+		"target_branch":       input.Base,
+		"title":               title,
+		"description":         input.Description,
+		"allow_collaboration": input.MaintainerCanModify,
+	}
+	if len(input.Labels) > 0 {
+		request["labels"] = strings.Join(input.Labels, ",")
+	}
+
+	var mr glMergeRequest
+	_, err = p.do(http.MethodPost, projectEndpoint(project, "merge_requests"), nil, request, &mr)
+	if err != nil {
+		return 0, fmt.Errorf("error creating merge request: %v", err)
+	}
+
+	log.Printf("Merge request created successfully: %s", mr.WebURL)
+	return mr.IID, nil
+}
+
+func (p *Provider) FetchRepositories() ([]types.Repository, error) {
+	query := url.Values{}
+	query.Set("membership", "true")
+	query.Set("order_by", "last_activity_at")
+
+	projects, err := getAll[glProject](p, "projects", query)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching repositories: %v", err)
+	}
+
+	var result []types.Repository
+	for _, project := range projects {
+		result = append(result, types.Repository{
+			Name:        project.Name,
+			FullName:    project.PathWithNamespace,
+			Description: project.Description,
+			CloneURL:    project.HTTPURLToRepo,
+			SSHURL:      project.SSHURLToRepo,
+		})
+	}
+	return result, nil
+}
+
+// FetchPullRequests returns the open merge requests of the project, only the
+// ones carrying the label when it is set
+func (p *Provider) FetchPullRequests(remotePath, label string) ([]types.PullRequest, error) {
+	project := p.projectPath(remotePath)
+	query := url.Values{}
+	query.Set("state", "opened")
+	if label != "" {
+		query.Set("labels", label)
+	}
+
+	mergeRequests, err := getAll[glMergeRequest](p, projectEndpoint(project, "merge_requests"), query)
+	if err != nil {
+		log.Printf("Error fetching merge requests: %v, request: %v", err, remotePath)
+		return nil, fmt.Errorf("error fetching merge requests: %v", err)
+	}
+
+	owner, repo := splitProject(project)
+	var pullRequests []types.PullRequest
+	for _, mr := range mergeRequests {
+		pr := mergeRequestToPullRequest(mr)
+
+		comments, err := p.FetchComments(owner, repo, mr.IID)
+		if err != nil {
+			log.Printf("Error fetching comments for MR %d: %v", mr.IID, err)
+			// Don't return, just log the error and continue
+		}
+		pr.Comments = comments
+
+		diff, err := p.FetchDiffs(owner, repo, mr.IID)
+		if err != nil {
+			log.Printf("Error fetching diffs for MR %d: %v", mr.IID, err)
+			// Don't return, just log the error and continue
+		}
+		pr.Diff = diff
+
+		pullRequests = append(pullRequests, pr)
+	}
+	return pullRequests, nil
+}
+
+// FetchPullRequest returns a merge request in any state
+func (p *Provider) FetchPullRequest(remotePath string, prNumber int) (types.PullRequest, error) {
+	project := p.projectPath(remotePath)
+	var mr glMergeRequest
+	_, err := p.do(http.MethodGet, projectEndpoint(project, "merge_requests", strconv.Itoa(prNumber)), nil, nil, &mr)
+	if err != nil {
+		return types.PullRequest{}, fmt.Errorf("error fetching merge request: %v", err)
+	}
+	return mergeRequestToPullRequest(mr), nil
+}
+
+func (p *Provider) UpdatePullRequestState(remotePath string, prNumber int, state string) error {
+	project := p.projectPath(remotePath)
+	endpoint := projectEndpoint(project, "merge_requests", strconv.Itoa(prNumber))
+
+	var err error
+	switch state {
+	case "open", "opened":
+		_, err = p.do(http.MethodPut, endpoint, nil, map[string]string{"state_event": "reopen"}, nil)
+	case "closed":
+		_, err = p.do(http.MethodPut, endpoint, nil, map[string]string{"state_event": "close"}, nil)
+	case "merged":
+		_, err = p.do(http.MethodPut, endpoint+"/merge", nil, nil, nil)
+	default:
+		return fmt.Errorf("unsupported merge request state: %s", state)
+	}
+	if err != nil {
+		return fmt.Errorf("error updating merge request state: %v", err)
+	}
+	return nil
+}
+
+func (p *Provider) DeletePullRequest(repoPath string, prNumber int) error {
+	project := p.projectPath(repoPath)
+	_, err := p.do(http.MethodDelete, projectEndpoint(project, "merge_requests", strconv.Itoa(prNumber)), nil, nil, nil)
+	if err != nil {
+		return fmt.Errorf("error deleting merge request: %v", err)
+	}
+	return nil
+}
+
+// FetchDiffs assembles a unified diff from the per file diffs of a merge request
+func (p *Provider) FetchDiffs(owner, repo string, resourceID int) (string, error) {
+	project := p.projectPath(joinProject(owner, repo))
+	diffs, err := getAll[glDiff](p, projectEndpoint(project, "merge_requests", strconv.Itoa(resourceID), "diffs"), nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to get merge request diff: %w", err)
+	}
+
+	var sb strings.Builder
+	for _, d := range diffs {
+		oldPath := "a/" + d.OldPath
+		newPath := "b/" + d.NewPath
+		if d.NewFile {
+			oldPath = "/dev/null"
+		}
+		if d.DeletedFile {
+			newPath = "/dev/null"
+		}
+		fmt.Fprintf(&sb, "diff --git a/%s b/%s\n--- %s\n+++ %s\n", d.OldPath, d.NewPath, oldPath, newPath)
+		sb.WriteString(d.Diff)
+		if !strings.HasSuffix(d.Diff, "\n") {
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String(), nil
+}
+
+func mergeRequestToPullRequest(mr glMergeRequest) types.PullRequest {
+	return types.PullRequest{
+		Number:          mr.IID,
+		Title:           mr.Title,
+		Body:            mr.Description,
+		State:           fromGitLabState(mr.State),
+		HTMLURL:         mr.WebURL,
+		Labels:          mr.Labels,
+		CreatedAt:       mr.CreatedAt,
+		UpdatedAt:       mr.UpdatedAt,
+		Branch:          mr.SourceBranch,
+		BaseBranch:      mr.TargetBranch,
+		LinkedIssueURLs: getLinkedIssueURLs(mr.Description),
+		Comments:        make([]*types.Comment, 0),
+	}
+}
+
+// projectFromLocalRepository reads the project path from the origin remote
+// of the repository checked out at path
+func projectFromLocalRepository(path string) (string, error) {
+	// issue worktrees share the remotes of the main repository
+	repo, err := git.PlainOpenWithOptions(path, &git.PlainOpenOptions{EnableDotGitCommonDir: true})
+	if err != nil {
+		return "", err
+	}
+	remote, err := repo.Remote("origin")
+	if err != nil {
+		return "", fmt.Errorf("error getting remote: %v", err)
+	}
+	return projectFromURL(remote.Config().URLs[0])
+}
+
+// projectFromURL extracts group/project from an ssh or https clone URL
+func projectFromURL(remoteURL string) (string, error) {
+	var project string
+	switch {
+	case strings.HasPrefix(remoteURL, "git@"):
+		_, after, found := strings.Cut(remoteURL, ":")
+		if !found {
+			return "", fmt.Errorf("invalid remote url: %s", remoteURL)
+		}
+		project = after
+	default:
+		u, err := url.Parse(remoteURL)
+		if err != nil {
+			return "", fmt.Errorf("invalid remote url: %v", err)
+		}
+		project = u.Path
+	}
+	project = strings.TrimSuffix(strings.Trim(project, "/"), ".git")
+	if !strings.Contains(project, "/") {
+		return "", fmt.Errorf("invalid remote url: %s", remoteURL)
+	}
+	return project, nil
+}
+
+// splitProject splits group/subgroup/project into the namespace and project
+// name so it fits the owner, repo arguments of the provider interface
+func splitProject(project string) (string, string) {
+	idx := strings.LastIndex(project, "/")
+	if idx < 0 {
+		return "", project
+	}
+	return project[:idx], project[idx+1:]
+}
+
+func joinProject(owner, repo string) string {
+	if owner == "" {
+		return repo
+	}
+	return owner + "/" + repo
+}
+
+func fromGitLabState(state string) string {
+	if state == "opened" {
+		return "open"
+	}
+	return state
+}
+
+func toGitLabState(state string) string {
+	switch state {
+	case "", "open":
+		return "opened"
+	default:
+		return state
+	}
+}
+
+func getLinkedIssueURLs(body string) []string {
+	// URLs are in HTML comments, other comments such as the hints of a pull
+	// request template are left out
+	var urls []string
+	for _, match := range re.FindAllStringSubmatch(body, -1) {
+		link := strings.TrimSpace(match[1])
+		parsed, err := url.Parse(link)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			continue
+		}
+		urls = append(urls, link)
+	}
+	return urls
+}
+
+func (p *Provider) IsTeamMember(team, user string) (bool, error) {
+	return false, fmt.Errorf("teams are not supported by gitlab")
+}
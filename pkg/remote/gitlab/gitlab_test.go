@@ -0,0 +1,189 @@
+package gitlab
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/mule-ai/mule/pkg/remote/types"
+)
+
+func newTestProvider(t *testing.T, mux *http.ServeMux) *Provider {
+	t.Helper()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return NewProvider("group/project", server.URL, "token")
+}
+
+func TestFetchIssuesFollowsPages(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v4/projects/group%2Fproject/issues", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("PRIVATE-TOKEN") != "token" {
+			t.Errorf("expected token header, got %q", r.Header.Get("PRIVATE-TOKEN"))
+		}
+		if r.URL.Query().Get("state") != "opened" || r.URL.Query().Get("labels") != "mule" {
+			t.Errorf("unexpected filter: %s", r.URL.RawQuery)
+		}
+		issues := []glIssue{{IID: 1, Title: "first", State: "opened"}}
+		if r.URL.Query().Get("page") == "1" {
+			w.Header().Set("X-Next-Page", "2")
+		} else {
+			issues = []glIssue{{IID: 2, Title: "second", State: "opened", Labels: []string{"mule"}}}
+		}
+		_ = json.NewEncoder(w).Encode(issues)
+	})
+	p := newTestProvider(t, mux)
+
+	issues, err := p.FetchIssues("group/project", types.IssueFilterOptions{State: "open", Label: "mule"})
+	if err != nil {
+		t.Fatalf("FetchIssues returned error: %v", err)
+	}
+	if len(issues) != 2 {
+		t.Fatalf("expected 2 issues, got %d", len(issues))
+	}
+	if issues[1].Number != 2 || issues[1].State != "open" || issues[1].Labels[0] != "mule" {
+		t.Errorf("unexpected issue: %+v", issues[1])
+	}
+}
+
+func TestFetchIssuesWithNotes(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v4/projects/group%2Fproject/issues", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode([]glIssue{{IID: 4, State: "opened", UserNotesCount: 1, Author: glUser{ID: 5, Username: "dave"}}})
+	})
+	mux.HandleFunc("/api/v4/projects/group%2Fproject/issues/4/notes", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("sort") != "asc" {
+			t.Errorf("expected the oldest notes first, got %s", r.URL.RawQuery)
+		}
+		_ = json.NewEncoder(w).Encode([]glNote{
+			{ID: 1, Body: "added label", System: true},
+			{ID: 2, Body: "use the v2 api", Author: glUser{ID: 7, Username: "carol"}, CreatedAt: "2024-02-01T09:00:00Z"},
+		})
+	})
+	p := newTestProvider(t, mux)
+
+	issues, err := p.FetchIssues("group/project", types.IssueFilterOptions{})
+	if err != nil {
+		t.Fatalf("FetchIssues returned error: %v", err)
+	}
+	if len(issues) != 1 || len(issues[0].Comments) != 1 {
+		t.Fatalf("expected one issue with one comment, got %+v", issues)
+	}
+	if comment := issues[0].Comments[0]; comment.Author != "carol" || comment.CreatedAt != "2024-02-01T09:00:00Z" {
+		t.Errorf("unexpected comment: %+v", comment)
+	}
+	if email := issues[0].AuthorEmail; email != "5-dave@users.noreply.127.0.0.1" {
+		t.Errorf("expected the no-reply address of the author, got %q", email)
+	}
+}
+
+func TestFetchCommentsAndAcknowledge(t *testing.T) {
+	var awarded, reply string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v4/projects/group%2Fproject/merge_requests/3/discussions", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode([]glDiscussion{{
+			ID: "abc",
+			Notes: []glNote{
+				{ID: 10, Body: "merged", System: true},
+				{ID: 11, Body: "rename this", Position: &glPosition{NewPath: "main.go", NewLine: 2}},
+				{ID: 12, Body: "and the test too"},
+			},
+		}, {
+			ID:    "def",
+			Notes: []glNote{{ID: 13, Body: "please update the changelog"}},
+		}})
+	})
+	mux.HandleFunc("/api/v4/projects/group%2Fproject/merge_requests/3/notes/13/award_emoji", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode([]glAwardEmoji{})
+	})
+	mux.HandleFunc("/api/v4/projects/group%2Fproject/merge_requests/3/notes/12/award_emoji", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode([]glAwardEmoji{})
+	})
+	mux.HandleFunc("POST /api/v4/projects/group%2Fproject/merge_requests/3/discussions/abc/notes", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		reply = body["body"]
+		w.WriteHeader(http.StatusCreated)
+	})
+	mux.HandleFunc("/api/v4/projects/group%2Fproject/merge_requests/3/diffs", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode([]glDiff{{OldPath: "main.go", NewPath: "main.go", Diff: "@@ -1,2 +1,3 @@\n package main\n+var x = 1\n func main() {}\n"}})
+	})
+	mux.HandleFunc("/api/v4/projects/group%2Fproject/merge_requests/3/notes/11/award_emoji", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			var body map[string]string
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			awarded = body["name"]
+			w.WriteHeader(http.StatusCreated)
+			return
+		}
+		_ = json.NewEncoder(w).Encode([]glAwardEmoji{{ID: 1, Name: "eyes"}})
+	})
+	p := newTestProvider(t, mux)
+
+	comments, err := p.FetchComments("group", "project", 3)
+	if err != nil {
+		t.Fatalf("FetchComments returned error: %v", err)
+	}
+	if len(comments) != 3 {
+		t.Fatalf("expected system notes to be skipped, got %d comments", len(comments))
+	}
+	if comments[2].Kind != types.CommentKindConversation {
+		t.Errorf("expected a discussion without position to be general feedback, got %q", comments[2].Kind)
+	}
+	if comments[0].Kind != "" || comments[1].Kind != "" {
+		t.Errorf("expected a discussion on a diff line to be inline, got %q and %q", comments[0].Kind, comments[1].Kind)
+	}
+	if comments[0].InReplyTo != 0 || comments[1].InReplyTo != 11 {
+		t.Errorf("expected the second note to answer the first, got %d and %d", comments[0].InReplyTo, comments[1].InReplyTo)
+	}
+	c := comments[0]
+	if c.Path != "main.go" || c.Line != 2 || c.Reactions.Eyes != 1 {
+		t.Errorf("unexpected comment: %+v", c)
+	}
+	if c.DiffHunk == "" {
+		t.Errorf("expected diff hunk for positioned comment")
+	}
+
+	if err := p.AddCommentReaction("group/project", "+1", 11); err != nil {
+		t.Fatalf("AddCommentReaction returned error: %v", err)
+	}
+	if awarded != "thumbsup" {
+		t.Errorf("expected thumbsup award, got %q", awarded)
+	}
+	if err := p.AddCommentReaction("group/project", "+1", 99); err == nil {
+		t.Errorf("expected error for unknown note")
+	}
+
+	if err := p.ReplyToComment("group/project", 3, 11, "renamed"); err != nil {
+		t.Fatalf("ReplyToComment returned error: %v", err)
+	}
+	if reply != "renamed" {
+		t.Errorf("expected the reply in the discussion, got %q", reply)
+	}
+}
+
+func TestProjectFromURL(t *testing.T) {
+	tests := map[string]string{
+		"git@gitlab.example.com:group/sub/project.git": "group/sub/project",
+		"https://gitlab.example.com/group/project.git": "group/project",
+	}
+	for in, want := range tests {
+		got, err := projectFromURL(in)
+		if err != nil {
+			t.Errorf("projectFromURL(%q) returned error: %v", in, err)
+		}
+		if got != want {
+			t.Errorf("projectFromURL(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestLinkedIssueURLsOfTemplate(t *testing.T) {
+	body := "## Description\n<!-- Describe your change -->\n\n## Checklist\n<!-- see https://example.com/contributing -->\n\nCloses #7\n<!--https://gitlab.com/owner/repo/-/issues/7-->"
+	urls := getLinkedIssueURLs(body)
+	if !slices.Equal(urls, []string{"https://gitlab.com/owner/repo/-/issues/7"}) {
+		t.Errorf("expected only the linked issue, got %q", urls)
+	}
+}
@@ -0,0 +1,164 @@
+package gitlab
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/mule-ai/mule/pkg/remote/types"
+)
+
+type glIssue struct {
+	IID         int      `json:"iid"`
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	State       string   `json:"state"`
+	WebURL      string   `json:"web_url"`
+	Labels      []string `json:"labels"`
+	CreatedAt   string   `json:"created_at"`
+	UpdatedAt   string   `json:"updated_at"`
+	// UserNotesCount counts the comments, system notes excluded
+	UserNotesCount int    `json:"user_notes_count"`
+	Author         glUser `json:"author"`
+}
+
+func (p *Provider) CreateIssue(issue types.Issue) (int, error) {
+	request := map[string]string{
+		"title":       issue.Title,
+		"description": issue.Body,
+	}
+	if len(issue.Labels) > 0 {
+		request["labels"] = strings.Join(issue.Labels, ",")
+	}
+
+	var created glIssue
+	_, err := p.do(http.MethodPost, projectEndpoint(p.project, "issues"), nil, request, &created)
+	if err != nil {
+		return 0, fmt.Errorf("error creating issue: %v", err)
+	}
+	return created.IID, nil
+}
+
+func (p *Provider) FetchIssues(remotePath string, options types.IssueFilterOptions) ([]types.Issue, error) {
+	project := p.projectPath(remotePath)
+
+	query := url.Values{}
+	query.Set("state", toGitLabState(options.State))
+	if options.Label != "" {
+		query.Set("labels", options.Label)
+	}
+
+	glIssues, err := getAll[glIssue](p, projectEndpoint(project, "issues"), query)
+	if err != nil {
+		log.Printf("Error fetching issues: %v, request: %v", err, remotePath)
+		return nil, fmt.Errorf("error fetching issues: %v", err)
+	}
+
+	var issues []types.Issue
+	for _, issue := range glIssues {
+		i := types.Issue{
+			Number:    issue.IID,
+			Title:     issue.Title,
+			Body:      issue.Description,
+			State:     fromGitLabState(issue.State),
+			HTMLURL:   issue.WebURL,
+			SourceURL: issue.WebURL,
+			Labels:    []string{},
+			CreatedAt: issue.CreatedAt,
+			UpdatedAt: issue.UpdatedAt,
+			Author:    issue.Author.Username,
+		}
+		i.Labels = append(i.Labels, issue.Labels...)
+		i.AuthorEmail = p.noreplyEmail(issue.Author)
+		if issue.UserNotesCount > 0 {
+			i.Comments, err = p.fetchIssueNotes(project, issue.IID)
+			if err != nil {
+				log.Printf("Error fetching comments for issue %d: %v", i.Number, err)
+				// Don't return, just log the error and continue
+			}
+		}
+		issues = append(issues, i)
+	}
+	return issues, nil
+}
+
+// fetchIssueNotes returns the discussion of an issue, oldest first
+func (p *Provider) fetchIssueNotes(project string, issueNumber int) ([]*types.Comment, error) {
+	query := url.Values{}
+	query.Set("sort", "asc")
+	query.Set("order_by", "created_at")
+	notes, err := getAll[glNote](p, projectEndpoint(project, "issues", strconv.Itoa(issueNumber), "notes"), query)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching issue comments: %v", err)
+	}
+
+	comments := make([]*types.Comment, 0, len(notes))
+	for _, note := range notes {
+		if note.System {
+			continue
+		}
+		comments = append(comments, &types.Comment{
+			ID:        note.ID,
+			Body:      note.Body,
+			UserID:    note.Author.ID,
+			Author:    note.Author.Username,
+			CreatedAt: note.CreatedAt,
+		})
+	}
+	return comments, nil
+}
+
+func (p *Provider) AddLabelToIssue(issueNumber int, label string) error {
+	return p.updateIssue(issueNumber, map[string]string{"add_labels": label})
+}
+
+func (p *Provider) RemoveLabelFromIssue(issueNumber int, label string) error {
+	return p.updateIssue(issueNumber, map[string]string{"remove_labels": label})
+}
+
+func (p *Provider) UpdateIssueState(issueNumber int, state string) error {
+	switch state {
+	case "open", "opened":
+		return p.updateIssue(issueNumber, map[string]string{"state_event": "reopen"})
+	case "closed":
+		return p.updateIssue(issueNumber, map[string]string{"state_event": "close"})
+	}
+	return fmt.Errorf("unsupported issue state: %s", state)
+}
+
+func (p *Provider) UpdateIssue(issueNumber int, title, body string) error {
+	return p.updateIssue(issueNumber, map[string]string{
+		"title":       title,
+		"description": body,
+	})
+}
+
+func (p *Provider) updateIssue(issueNumber int, request map[string]string) error {
+	_, err := p.do(http.MethodPut, projectEndpoint(p.project, "issues", strconv.Itoa(issueNumber)), nil, request, nil)
+	if err != nil {
+		return fmt.Errorf("error updating issue: %v", err)
+	}
+	return nil
+}
+
+func (p *Provider) CreateIssueComment(remotePath string, issueNumber int, comment types.Comment) error {
+	project := p.projectPath(remotePath)
+	endpoint := projectEndpoint(project, "issues", strconv.Itoa(issueNumber), "notes")
+	_, err := p.do(http.MethodPost, endpoint, nil, map[string]string{"body": comment.Body}, nil)
+	if err != nil {
+		return fmt.Errorf("error creating issue comment: %v", err)
+	}
+	return nil
+}
+
+func (p *Provider) DeleteIssue(repoPath string, issueNumber int) error {
+	project := p.projectPath(repoPath)
+	_, err := p.do(http.MethodDelete, projectEndpoint(project, "issues", strconv.Itoa(issueNumber)), nil, nil, nil)
+	if err != nil {
+		return fmt.Errorf("error deleting issue: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package gitlab
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+const DefaultBaseURL = "https://gitlab.com"
+
+type Provider struct {
+	Client  *http.Client
+	ctx     context.Context
+	baseURL string
+	token   string
+	project string
+	// GitLab addresses notes through their merge request, so remember
+	// which merge request each fetched note belongs to
+	notesMu sync.RWMutex
+	notes   map[int64]noteRef
+	// currentUser caches the username the token belongs to
+	userMu      sync.Mutex
+	currentUser string
+}
+
+type noteRef struct {
+	project      string
+	mergeRequest int
+	discussionID string
+}
+
+// creates a new gitlab provider for the project at path (group/project)
+// on the instance served from baseURL
+func NewProvider(path, baseURL, token string) *Provider {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	baseURL = strings.TrimSuffix(baseURL, "/")
+	if !strings.HasSuffix(baseURL, "/api/v4") {
+		baseURL += "/api/v4"
+	}
+	return &Provider{
+		Client:  http.DefaultClient,
+		ctx:     context.Background(),
+		baseURL: baseURL,
+		token:   token,
+		project: strings.Trim(path, "/"),
+		notes:   make(map[int64]noteRef),
+	}
+}
+
+// noreplyEmail is the address GitLab attributes commits to the user with
+// without revealing their email
+func (p *Provider) noreplyEmail(user glUser) string {
+	u, err := url.Parse(p.baseURL)
+	if err != nil || user.Username == "" {
+		return ""
+	}
+	return fmt.Sprintf("%d-%s@users.noreply.%s", user.ID, user.Username, u.Hostname())
+}
+
+// projectPath returns the project to address, preferring the remote path
+// passed by the caller over the one the provider was created with
+func (p *Provider) projectPath(remotePath string) string {
+	if remotePath != "" && !strings.HasPrefix(remotePath, "/") {
+		return strings.Trim(remotePath, "/")
+	}
+	return p.project
+}
+
+func (p *Provider) rememberNote(noteID int64, ref noteRef) {
+	p.notesMu.Lock()
+	defer p.notesMu.Unlock()
+	p.notes[noteID] = ref
+}
+
+func (p *Provider) lookupNote(noteID int64) (noteRef, bool) {
+	p.notesMu.RLock()
+	defer p.notesMu.RUnlock()
+	ref, ok := p.notes[noteID]
+	return ref, ok
+}
+
+// GitCredentials returns the basic auth credentials for git over HTTPS
+func (p *Provider) GitCredentials() (string, string, error) {
+	if p.token == "" {
+		return "", "", fmt.Errorf("gitlab token is not set")
+	}
+	return "oauth2", p.token, nil
+}
+
+// CurrentUser returns the username the token belongs to
+func (p *Provider) CurrentUser() (string, error) {
+	p.userMu.Lock()
+	defer p.userMu.Unlock()
+	if p.currentUser != "" {
+		return p.currentUser, nil
+	}
+	var user glUser
+	if _, err := p.do(http.MethodGet, "user", nil, nil, &user); err != nil {
+		return "", fmt.Errorf("error fetching current user: %v", err)
+	}
+	p.currentUser = user.Username
+	return user.Username, nil
+}
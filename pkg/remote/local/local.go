@@ -11,17 +11,26 @@ import (
 	"slices"
 	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/mule-ai/mule/pkg/remote/types"
 )
 
 const (
-	dataPath    = ".config/mule/local-provider.json"
-	filterLabel = "mule"
+	dataPath = ".config/mule/local-provider.json"
+	// MuleUser authors the comments mule posts on local repositories,
+	// WebUser the ones added on the web interface
+	MuleUser = "mule"
+	WebUser  = "user"
 )
 
 var re = regexp.MustCompile(`<!--(.*?)-->`)
 
+// mu guards the state of every local provider and the file they are saved
+// to, issues are worked on concurrently
+var mu sync.Mutex
+
 type ProviderConfig struct {
 	Providers map[string]Provider `json:"providers"`
 }
@@ -34,6 +43,8 @@ type Provider struct {
 }
 
 func NewProvider(path string) *Provider {
+	mu.Lock()
+	defer mu.Unlock()
 	// load provider from file
 	provider, err := loadProvider(path)
 	if err != nil {
@@ -42,7 +53,9 @@ func NewProvider(path string) *Provider {
 	return provider
 }
 
-func (p *Provider) CreateDraftPR(path string, input types.PullRequestInput) error {
+func (p *Provider) CreateDraftPR(path string, input types.PullRequestInput) (int, error) {
+	mu.Lock()
+	defer mu.Unlock()
 	p.IssueCounter++
 	linkedIssueURLs := getLinkedIssueURLs(input.Description)
 	p.PullRequests[p.IssueCounter] = &types.PullRequest{
@@ -52,59 +65,72 @@ func (p *Provider) CreateDraftPR(path string, input types.PullRequestInput) erro
 		State:           "draft",
 		BaseBranch:      input.Base,
 		Branch:          input.Branch,
+		Labels:          input.Labels,
 		LinkedIssueURLs: linkedIssueURLs,
 	}
 
-	return p.Save()
+	return p.IssueCounter, p.save()
 }
 
 func (p *Provider) CreateIssue(issue types.Issue) (int, error) {
+	mu.Lock()
+	defer mu.Unlock()
 	p.IssueCounter++
 	issue.Number = p.IssueCounter
 	issue.SourceURL = fmt.Sprintf("%s/issues/%d", p.Path, p.IssueCounter)
 	p.Issues[p.IssueCounter] = &issue
 
-	return p.IssueCounter, p.Save()
+	return p.IssueCounter, p.save()
 }
 
 func (p *Provider) DeleteIssue(repoPath string, issueNumber int) error {
+	mu.Lock()
+	defer mu.Unlock()
 	_, ok := p.Issues[issueNumber]
 	if !ok {
 		return fmt.Errorf("issue %d not found", issueNumber)
 	}
 	delete(p.Issues, issueNumber)
-	return p.Save()
+	return p.save()
 }
 
 func (p *Provider) DeletePullRequest(repoPath string, prNumber int) error {
+	mu.Lock()
+	defer mu.Unlock()
 	_, ok := p.PullRequests[prNumber]
 	if !ok {
 		return fmt.Errorf("pull request %d not found", prNumber)
 	}
 	delete(p.PullRequests, prNumber)
-	return p.Save()
+	return p.save()
 }
 
 func (p *Provider) UpdateIssueState(issueNumber int, state string) error {
+	mu.Lock()
+	defer mu.Unlock()
 	issue, ok := p.Issues[issueNumber]
 	if !ok {
 		return fmt.Errorf("issue %d not found", issueNumber)
 	}
 	issue.State = state
-	return p.Save()
+	return p.save()
 }
 
 func (p *Provider) UpdateIssue(issueNumber int, title, body string) error {
+	mu.Lock()
+	defer mu.Unlock()
 	issue, ok := p.Issues[issueNumber]
 	if !ok {
 		return fmt.Errorf("issue %d not found", issueNumber)
 	}
 	issue.Title = title
 	issue.Body = body
-	return p.Save()
+	return p.save()
 }
 
 func (p *Provider) FetchIssues(remotePath string, options types.IssueFilterOptions) ([]types.Issue, error) {
+	mu.Lock()
+	defer mu.Unlock()
 	issues := make([]types.Issue, 0, len(p.Issues))
 	for _, issue := range p.Issues {
 		if options.Label != "" && !slices.Contains(issue.Labels, options.Label) {
@@ -113,7 +139,9 @@ func (p *Provider) FetchIssues(remotePath string, options types.IssueFilterOptio
 		if options.State != "" && issue.State != options.State {
 			continue
 		}
-		issues = append(issues, *issue)
+		copied := *issue
+		copied.Comments = copyComments(issue.Comments)
+		issues = append(issues, copied)
 	}
 	sort.Slice(issues, func(i, j int) bool {
 		return issues[i].Number < issues[j].Number
@@ -122,24 +150,48 @@ func (p *Provider) FetchIssues(remotePath string, options types.IssueFilterOptio
 }
 
 func (p *Provider) AddLabelToIssue(issueNumber int, label string) error {
+	mu.Lock()
+	defer mu.Unlock()
 	issue, ok := p.Issues[issueNumber]
 	if !ok {
 		return fmt.Errorf("issue %d not found", issueNumber)
 	}
 	issue.Labels = append(issue.Labels, label)
 	p.Issues[issueNumber] = issue
-	return p.Save()
+	return p.save()
+}
+
+func (p *Provider) RemoveLabelFromIssue(issueNumber int, label string) error {
+	mu.Lock()
+	defer mu.Unlock()
+	issue, ok := p.Issues[issueNumber]
+	if !ok {
+		return fmt.Errorf("issue %d not found", issueNumber)
+	}
+	issue.Labels = slices.DeleteFunc(issue.Labels, func(l string) bool {
+		return l == label
+	})
+	return p.save()
 }
 
 func (p *Provider) FetchPullRequests(remotePath, label string) ([]types.PullRequest, error) {
+	mu.Lock()
 	pullRequests := make([]types.PullRequest, 0, len(p.PullRequests))
 	for _, pullRequest := range p.PullRequests {
-		diff, err := p.FetchDiffs("", "", pullRequest.Number)
+		if label != "" && !slices.Contains(pullRequest.Labels, label) {
+			continue
+		}
+		pullRequests = append(pullRequests, copyPullRequest(pullRequest))
+	}
+	mu.Unlock()
+
+	// the diffs are generated without holding the lock
+	for i := range pullRequests {
+		diff, err := p.diff(pullRequests[i])
 		if err != nil {
 			return nil, fmt.Errorf("error fetching diffs: %v", err)
 		}
-		pullRequest.Diff = diff
-		pullRequests = append(pullRequests, *pullRequest)
+		pullRequests[i].Diff = diff
 	}
 	sort.Slice(pullRequests, func(i, j int) bool {
 		return pullRequests[i].Number < pullRequests[j].Number
@@ -147,22 +199,45 @@ func (p *Provider) FetchPullRequests(remotePath, label string) ([]types.PullRequ
 	return pullRequests, nil
 }
 
+func (p *Provider) FetchPullRequest(remotePath string, prNumber int) (types.PullRequest, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	pullRequest, ok := p.PullRequests[prNumber]
+	if !ok {
+		return types.PullRequest{}, fmt.Errorf("pull request %d not found", prNumber)
+	}
+	return copyPullRequest(pullRequest), nil
+}
+
 func (p *Provider) UpdatePullRequestState(remotePath string, prNumber int, state string) error {
+	mu.Lock()
+	defer mu.Unlock()
 	pullRequest, ok := p.PullRequests[prNumber]
 	if !ok {
 		return fmt.Errorf("pull request %d not found", prNumber)
 	}
 	pullRequest.State = state
 	p.PullRequests[prNumber] = pullRequest
-	return p.Save()
+	return p.save()
 }
 
 func (p *Provider) FetchDiffs(_, _ string, resourceID int) (string, error) {
+	mu.Lock()
 	pr, ok := p.PullRequests[resourceID]
+	var pullRequest types.PullRequest
+	if ok {
+		pullRequest = copyPullRequest(pr)
+	}
+	mu.Unlock()
 	if !ok {
 		return "", fmt.Errorf("pull request %d not found", resourceID)
 	}
+	return p.diff(pullRequest)
+}
 
+// diff runs git diff between the base branch and the branch of the pull
+// request
+func (p *Provider) diff(pr types.PullRequest) (string, error) {
 	// Create a temporary directory for the diff operation
 	tmpDir, err := os.MkdirTemp("", "dev-team-diff-*")
 	if err != nil {
@@ -170,8 +245,14 @@ func (p *Provider) FetchDiffs(_, _ string, resourceID int) (string, error) {
 	}
 	defer os.RemoveAll(tmpDir)
 
+	// pull requests created before the base branch was recorded
+	baseBranch := pr.BaseBranch
+	if baseBranch == "" {
+		baseBranch = p.defaultBranch()
+	}
+
 	// Run git diff command
-	cmd := exec.Command("git", "-C", p.Path, "diff", pr.BaseBranch+".."+pr.Branch)
+	cmd := exec.Command("git", "-C", p.Path, "diff", baseBranch+".."+pr.Branch)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		return "", fmt.Errorf("error generating diff: %v: %s", err, string(output))
@@ -180,20 +261,34 @@ func (p *Provider) FetchDiffs(_, _ string, resourceID int) (string, error) {
 	return string(output), nil
 }
 
+// defaultBranch returns the branch origin/HEAD points to, main when the
+// repository has no such reference
+func (p *Provider) defaultBranch() string {
+	output, err := exec.Command("git", "-C", p.Path, "symbolic-ref", "--short", "refs/remotes/origin/HEAD").Output()
+	if err != nil {
+		return "main"
+	}
+	return strings.TrimPrefix(strings.TrimSpace(string(output)), "origin/")
+}
+
 func (p *Provider) FetchComments(owner, repo string, prNumber int) ([]*types.Comment, error) {
+	mu.Lock()
+	defer mu.Unlock()
 	pr, ok := p.PullRequests[prNumber]
 	if !ok {
 		return nil, fmt.Errorf("pull request %d not found", prNumber)
 	}
-	return pr.Comments, nil
+	return copyComments(pr.Comments), nil
 }
 
 func (p *Provider) AddCommentReaction(repoPath, reaction string, commentID int64) error {
+	mu.Lock()
+	defer mu.Unlock()
 	for _, pr := range p.PullRequests {
 		for _, comment := range pr.Comments {
 			if comment.ID == commentID {
 				comment.Reactions = addReactionToReactions(comment.Reactions, reaction)
-				return p.Save()
+				return p.save()
 			}
 		}
 	}
@@ -201,7 +296,7 @@ func (p *Provider) AddCommentReaction(repoPath, reaction string, commentID int64
 		for _, comment := range issue.Comments {
 			if comment.ID == commentID {
 				comment.Reactions = addReactionToReactions(comment.Reactions, reaction)
-				return p.Save()
+				return p.save()
 			}
 		}
 	}
@@ -213,21 +308,24 @@ func (p *Provider) FetchRepositories() ([]types.Repository, error) {
 }
 
 func (p *Provider) CreateIssueComment(remotePath string, issueNumber int, comment types.Comment) error {
+	mu.Lock()
+	defer mu.Unlock()
 	issue, ok := p.Issues[issueNumber]
 	if !ok {
 		return fmt.Errorf("issue %d not found", issueNumber)
 	}
+	if comment.CreatedAt == "" {
+		comment.CreatedAt = time.Now().Format(time.RFC3339)
+	}
+	if comment.Author == "" {
+		comment.Author = MuleUser
+	}
 	issue.Comments = append(issue.Comments, &comment)
 	p.Issues[issueNumber] = issue
-	return p.Save()
+	return p.save()
 }
 
 func (p *Provider) CreatePRComment(remotePath string, prNumber int, comment types.Comment) error {
-	pr, ok := p.PullRequests[prNumber]
-	if !ok {
-		return fmt.Errorf("pull request %d not found", prNumber)
-	}
-
 	// If there's a diff hunk, validate it exists in the PR diff
 	if comment.DiffHunk != "" {
 		diff, err := p.FetchDiffs("", "", prNumber)
@@ -239,9 +337,70 @@ func (p *Provider) CreatePRComment(remotePath string, prNumber int, comment type
 		}
 	}
 
+	mu.Lock()
+	defer mu.Unlock()
+	pr, ok := p.PullRequests[prNumber]
+	if !ok {
+		return fmt.Errorf("pull request %d not found", prNumber)
+	}
+	if comment.Author == "" {
+		comment.Author = MuleUser
+	}
 	pr.Comments = append(pr.Comments, &comment)
 	p.PullRequests[prNumber] = pr
-	return p.Save()
+	return p.save()
+}
+
+func (p *Provider) ReplyToComment(remotePath string, prNumber int, commentID int64, body string) error {
+	mu.Lock()
+	defer mu.Unlock()
+	pr, ok := p.PullRequests[prNumber]
+	if !ok {
+		return fmt.Errorf("pull request %d not found", prNumber)
+	}
+	pr.Comments = append(pr.Comments, &types.Comment{
+		ID:        time.Now().UnixNano(),
+		Body:      body,
+		Author:    MuleUser,
+		CreatedAt: time.Now().Format(time.RFC3339),
+		InReplyTo: commentID,
+	})
+	return p.save()
+}
+
+func (p *Provider) IsTeamMember(team, user string) (bool, error) {
+	return false, fmt.Errorf("local repositories have no teams")
+}
+
+// CurrentUser returns the author of the comments mule posts locally
+func (p *Provider) CurrentUser() (string, error) {
+	return MuleUser, nil
+}
+
+// ResolveComment is not supported, local comments are acknowledged with a
+// reaction
+func (p *Provider) ResolveComment(remotePath string, prNumber int, commentID int64) error {
+	return fmt.Errorf("local pull requests have no review threads")
+}
+
+// copyPullRequest copies a pull request with its comments, so callers can
+// read it while the provider changes it
+func copyPullRequest(pr *types.PullRequest) types.PullRequest {
+	copied := *pr
+	copied.Comments = copyComments(pr.Comments)
+	return copied
+}
+
+func copyComments(comments []*types.Comment) []*types.Comment {
+	if comments == nil {
+		return nil
+	}
+	copied := make([]*types.Comment, len(comments))
+	for i, comment := range comments {
+		c := *comment
+		copied[i] = &c
+	}
+	return copied
 }
 
 func addReactionToReactions(reactions types.Reactions, reaction string) types.Reactions {
@@ -268,6 +427,12 @@ func addReactionToReactions(reactions types.Reactions, reaction string) types.Re
 }
 
 func (p *Provider) Save() error {
+	mu.Lock()
+	defer mu.Unlock()
+	return p.save()
+}
+
+func (p *Provider) save() error {
 	// get current provider config
 	providerConfig, err := loadProviderConfig()
 	if err != nil {
@@ -0,0 +1,54 @@
+package local
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/mule-ai/mule/pkg/remote/types"
+)
+
+func TestProviderConcurrently(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	p := &Provider{
+		Path:         t.TempDir(),
+		Issues:       make(map[int]*types.Issue),
+		PullRequests: make(map[int]*types.PullRequest),
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			number, err := p.CreateIssue(types.Issue{Title: fmt.Sprintf("issue %d", i), State: "open"})
+			if err != nil {
+				t.Errorf("CreateIssue returned error: %v", err)
+				return
+			}
+			if err := p.AddLabelToIssue(number, "mule"); err != nil {
+				t.Errorf("AddLabelToIssue returned error: %v", err)
+			}
+			if err := p.CreateIssueComment("", number, types.Comment{ID: int64(i), Body: "working on it"}); err != nil {
+				t.Errorf("CreateIssueComment returned error: %v", err)
+			}
+			if _, err := p.FetchIssues("", types.IssueFilterOptions{Label: "mule"}); err != nil {
+				t.Errorf("FetchIssues returned error: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	issues, err := p.FetchIssues("", types.IssueFilterOptions{Label: "mule"})
+	if err != nil {
+		t.Fatalf("FetchIssues returned error: %v", err)
+	}
+	if len(issues) != 8 || p.IssueCounter != 8 {
+		t.Fatalf("expected 8 issues, got %d and counter %d", len(issues), p.IssueCounter)
+	}
+	for _, issue := range issues {
+		if len(issue.Comments) != 1 {
+			t.Errorf("expected one comment on issue %d, got %d", issue.Number, len(issue.Comments))
+		}
+	}
+}
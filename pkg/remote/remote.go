@@ -2,8 +2,11 @@ package remote
 
 import (
 	"fmt"
+	"log"
 
+	"github.com/mule-ai/mule/pkg/remote/gitea"
 	"github.com/mule-ai/mule/pkg/remote/github"
+	"github.com/mule-ai/mule/pkg/remote/gitlab"
 	"github.com/mule-ai/mule/pkg/remote/local"
 	"github.com/mule-ai/mule/pkg/remote/types"
 )
@@ -11,20 +14,26 @@ import (
 const (
 	LOCAL  = 0
 	GITHUB = 1
+	GITLAB = 2
+	GITEA  = 3
 )
 
 var stringToIntMap = map[string]int{
 	"local":  LOCAL,
 	"github": GITHUB,
+	"gitlab": GITLAB,
+	"gitea":  GITEA,
 }
 
 var intToStringMap = map[int]string{
 	LOCAL:  "local",
 	GITHUB: "github",
+	GITLAB: "gitlab",
+	GITEA:  "gitea",
 }
 
 type Provider interface {
-	CreateDraftPR(path string, input types.PullRequestInput) error
+	CreateDraftPR(path string, input types.PullRequestInput) (int, error)
 	CreateIssue(issue types.Issue) (int, error)
 	CreateIssueComment(path string, issueNumber int, comment types.Comment) error
 	CreatePRComment(path string, prNumber int, comment types.Comment) error
@@ -33,25 +42,44 @@ type Provider interface {
 	UpdateIssueState(issueNumber int, state string) error
 	UpdateIssue(issueNumber int, title, body string) error
 	AddLabelToIssue(issueNumber int, label string) error
+	RemoveLabelFromIssue(issueNumber int, label string) error
 	FetchRepositories() ([]types.Repository, error)
 	FetchIssues(remotePath string, options types.IssueFilterOptions) ([]types.Issue, error)
 	FetchPullRequests(remotePath, label string) ([]types.PullRequest, error)
+	FetchPullRequest(remotePath string, prNumber int) (types.PullRequest, error)
 	UpdatePullRequestState(remotePath string, prNumber int, state string) error
 	FetchDiffs(owner, repo string, resourceID int) (string, error)
 	FetchComments(owner, repo string, prNumber int) ([]*types.Comment, error)
 	AddCommentReaction(repoPath, reaction string, commentID int64) error
+	ReplyToComment(remotePath string, prNumber int, commentID int64, body string) error
+	ResolveComment(remotePath string, prNumber int, commentID int64) error
+	IsTeamMember(team, user string) (bool, error)
+	// CurrentUser returns the login of the account the provider acts as
+	CurrentUser() (string, error)
+}
+
+// GitCredentials is implemented by providers that can authenticate git over
+// HTTPS with their own credentials
+type GitCredentials interface {
+	GitCredentials() (username, password string, err error)
 }
 
 type ProviderSettings struct {
-	Provider string `json:"provider,omitempty"`
-	Path     string `json:"path,omitempty"`
-	Token    string `json:"token,omitempty"`
+	Provider string                 `json:"provider,omitempty"`
+	Path     string                 `json:"path,omitempty"`
+	Token    string                 `json:"token,omitempty"`
+	Server   string                 `json:"server,omitempty"`
+	App      *github.AppCredentials `json:"app,omitempty"`
 }
 
 type ProviderOptions struct {
 	Type        int
 	GitHubToken string
+	GitHubApp   github.AppCredentials
+	GitLabToken string
+	GiteaToken  string
 	Path        string
+	Server      string
 }
 
 func New(options ProviderOptions) Provider {
@@ -59,7 +87,18 @@ func New(options ProviderOptions) Provider {
 	case LOCAL:
 		return local.NewProvider(options.Path)
 	case GITHUB:
+		if options.GitHubApp.Enabled() {
+			provider, err := github.NewAppProvider(options.Path, options.GitHubApp)
+			if err == nil {
+				return provider
+			}
+			log.Printf("Error creating GitHub App provider, falling back to token: %v", err)
+		}
 		return github.NewProvider(options.Path, options.GitHubToken)
+	case GITLAB:
+		return gitlab.NewProvider(options.Path, options.Server, options.GitLabToken)
+	case GITEA:
+		return gitea.NewProvider(options.Path, options.Server, options.GiteaToken)
 	}
 	return nil
 }
@@ -69,11 +108,23 @@ func SettingsToOptions(settings ProviderSettings) (ProviderOptions, error) {
 	if !ok {
 		return ProviderOptions{}, fmt.Errorf("invalid provider: %s", settings.Provider)
 	}
-	return ProviderOptions{
-		Type:        provider,
-		GitHubToken: settings.Token,
-		Path:        settings.Path,
-	}, nil
+	options := ProviderOptions{
+		Type:   provider,
+		Path:   settings.Path,
+		Server: settings.Server,
+	}
+	switch provider {
+	case GITHUB:
+		options.GitHubToken = settings.Token
+		if settings.App != nil {
+			options.GitHubApp = *settings.App
+		}
+	case GITLAB:
+		options.GitLabToken = settings.Token
+	case GITEA:
+		options.GiteaToken = settings.Token
+	}
+	return options, nil
 }
 
 func ProviderTypeToString(providerType int) string {
@@ -19,6 +19,14 @@ type Issue struct {
 	UpdatedAt string     `json:"updated_at"`
 	Labels    []string   `json:"labels"`
 	Comments  []*Comment `json:"comments"`
+	// Author is the login of the user who opened the issue.
+	// AuthorAssociation is their relation to the repository, like OWNER,
+	// MEMBER or COLLABORATOR, on providers that report it.
+	Author            string `json:"author,omitempty"`
+	AuthorAssociation string `json:"author_association,omitempty"`
+	// AuthorEmail is an address commits can credit the author with, usually
+	// the no-reply address the provider attributes to them
+	AuthorEmail string `json:"author_email,omitempty"`
 }
 
 type IssueFilterOptions struct {
@@ -33,6 +41,8 @@ type PullRequestInput struct {
 	Base                string `json:"base"`
 	Draft               bool   `json:"draft"`
 	MaintainerCanModify bool   `json:"maintainer_can_modify"`
+	// Labels are added to the pull request once it is created
+	Labels []string `json:"labels,omitempty"`
 }
 
 type PullRequest struct {
@@ -53,15 +63,41 @@ type PullRequest struct {
 }
 
 type Comment struct {
-	ID        int64     `json:"id"`
-	Body      string    `json:"body"`
-	DiffHunk  string    `json:"diff_hunk,omitempty"`
-	HTMLURL   string    `json:"html_url"`
-	URL       string    `json:"url"`
-	UserID    int64     `json:"user_id"`
-	Reactions Reactions `json:"reactions,omitempty"`
+	ID        int64  `json:"id"`
+	Body      string `json:"body"`
+	DiffHunk  string `json:"diff_hunk,omitempty"`
+	Path      string `json:"path,omitempty"`
+	Line      int    `json:"line,omitempty"`
+	HTMLURL   string `json:"html_url"`
+	URL       string `json:"url"`
+	UserID    int64  `json:"user_id"`
+	Author    string `json:"author,omitempty"`
+	CreatedAt string `json:"created_at,omitempty"`
+	// AuthorAssociation is the relation of the author to the repository, see
+	// Issue.AuthorAssociation
+	AuthorAssociation string `json:"author_association,omitempty"`
+	// InReplyTo is the comment this one answers in a review thread
+	InReplyTo int64 `json:"in_reply_to,omitempty"`
+	// ThreadID is set by providers with resolvable review threads, Resolved
+	// and Outdated are the state of the thread
+	ThreadID string `json:"thread_id,omitempty"`
+	Resolved bool   `json:"resolved,omitempty"`
+	Outdated bool   `json:"outdated,omitempty"`
+	// Kind tells general feedback on a pull request from inline review
+	// comments, which have no kind. ReviewState is the verdict of a review.
+	Kind        string    `json:"kind,omitempty"`
+	ReviewState string    `json:"review_state,omitempty"`
+	Reactions   Reactions `json:"reactions,omitempty"`
 }
 
+// Kinds of pull request comments that are not anchored to a line
+const (
+	// CommentKindConversation is a comment in the conversation of a pull request
+	CommentKindConversation = "conversation"
+	// CommentKindReview is the body of a review
+	CommentKindReview = "review"
+)
+
 type Reaction struct {
 	ID      int64  `json:"id,omitempty"`
 	Content string `json:"content,omitempty"`
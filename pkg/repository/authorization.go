@@ -0,0 +1,242 @@
+package repository
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/mule-ai/mule/pkg/remote/types"
+)
+
+// decides whose issues and comments mule takes instructions from
+
+// AuthorizationRules restrict the authors of the issues and comments mule
+// works on. Without any rule everyone is trusted.
+type AuthorizationRules struct {
+	// Users are logins
+	Users []string `json:"users,omitempty"`
+	// Teams are given as org/team-slug, only GitHub supports them
+	Teams []string `json:"teams,omitempty"`
+	// Associations are relations to the repository like OWNER, MEMBER or
+	// COLLABORATOR, only GitHub reports them
+	Associations []string `json:"associations,omitempty"`
+}
+
+// authorAssociations are the author associations GitHub reports
+var authorAssociations = []string{
+	"OWNER", "MEMBER", "COLLABORATOR", "CONTRIBUTOR",
+	"FIRST_TIME_CONTRIBUTOR", "FIRST_TIMER", "MANNEQUIN", "NONE",
+}
+
+// ignoredMarker tags the notices on ignored issues and comments with the ID of
+// the ignored comment, 0 for the issue itself, so each gets one notice
+const ignoredMarker = "<!-- mule:ignored %d -->"
+
+var ignoredPattern = regexp.MustCompile(`<!-- mule:ignored (\d+) -->`)
+
+// muleMarker starts the markers of everything mule posts
+const muleMarker = "<!-- mule:"
+
+// IsMule reports whether login is the account mule acts as on the remote
+func (r *Repository) IsMule(login string) bool {
+	account := r.muleAccount()
+	return login != "" && account != "" && strings.EqualFold(account, login)
+}
+
+// muleAccount returns the login mule acts as on the remote, empty when it
+// can't be looked up
+func (r *Repository) muleAccount() string {
+	if r.Remote == nil {
+		return ""
+	}
+	account, err := r.Remote.CurrentUser()
+	if err != nil {
+		r.Logger.Error(err, "Error fetching the account of mule")
+		return ""
+	}
+	return account
+}
+
+// Enabled reports whether any rule is set
+func (a AuthorizationRules) Enabled() bool {
+	return len(a.Users) > 0 || len(a.Teams) > 0 || len(a.Associations) > 0
+}
+
+// Normalize trims the rules, drops empty entries and checks the teams and
+// associations
+func (a AuthorizationRules) Normalize() (AuthorizationRules, error) {
+	var normalized AuthorizationRules
+	for _, user := range a.Users {
+		if user = strings.TrimPrefix(strings.TrimSpace(user), "@"); user != "" {
+			normalized.Users = append(normalized.Users, user)
+		}
+	}
+	for _, team := range a.Teams {
+		team = strings.TrimPrefix(strings.TrimSpace(team), "@")
+		if team == "" {
+			continue
+		}
+		if org, slug, ok := strings.Cut(team, "/"); !ok || org == "" || slug == "" {
+			return AuthorizationRules{}, fmt.Errorf("invalid team %q, expected org/team-slug", team)
+		}
+		normalized.Teams = append(normalized.Teams, team)
+	}
+	for _, association := range a.Associations {
+		association = strings.ToUpper(strings.TrimSpace(association))
+		if association == "" {
+			continue
+		}
+		if !slices.Contains(authorAssociations, association) {
+			return AuthorizationRules{}, fmt.Errorf("unknown author association: %s", association)
+		}
+		normalized.Associations = append(normalized.Associations, association)
+	}
+	return normalized, nil
+}
+
+// authorizer checks authors against the rules, team memberships are looked up
+// once per sync
+type authorizer struct {
+	repo    *Repository
+	rules   AuthorizationRules
+	members map[string]bool
+}
+
+func (a *authorizer) trusted(author, association string) bool {
+	if !a.rules.Enabled() {
+		return true
+	}
+	if author == "" {
+		return false
+	}
+	for _, user := range a.rules.Users {
+		if strings.EqualFold(user, author) {
+			return true
+		}
+	}
+	for _, allowed := range a.rules.Associations {
+		if strings.EqualFold(allowed, association) {
+			return true
+		}
+	}
+	for _, team := range a.rules.Teams {
+		key := team + "\x00" + strings.ToLower(author)
+		member, ok := a.members[key]
+		if !ok {
+			var err error
+			member, err = a.repo.Remote.IsTeamMember(team, author)
+			if err != nil {
+				a.repo.Logger.Error(err, "Error checking team membership", "team", team, "user", author)
+			}
+			a.members[key] = member
+		}
+		if member {
+			return true
+		}
+	}
+	return false
+}
+
+// authorizeIssues drops the issues and comments of untrusted authors, each
+// ignored item gets a notice once when notify is set
+func (r *Repository) authorizeIssues(notify bool) {
+	r.Mu.RLock()
+	rules := r.Authorization
+	r.Mu.RUnlock()
+	if !rules.Enabled() {
+		return
+	}
+	a := &authorizer{repo: r, rules: rules, members: make(map[string]bool)}
+	account := r.muleAccount()
+
+	for _, pr := range r.PullRequests {
+		pr.Comments = r.authorizePRComments(a, pr, notify)
+	}
+	for number, issue := range r.Issues {
+		noticed := ignoredNotices(issue.Comments)
+		if !a.trusted(issue.Author, issue.AuthorAssociation) {
+			r.Logger.Info("Ignoring issue of untrusted author", "issue", number, "author", issue.Author)
+			if notify && !noticed[0] {
+				r.noticeIgnored(number, 0, "issue", issue.Author)
+			}
+			delete(r.Issues, number)
+			continue
+		}
+
+		var comments []*Comment
+		for _, comment := range issue.Comments {
+			switch {
+			case ignoredPattern.MatchString(comment.Body):
+				// the notices carry no instructions
+			case strings.Contains(comment.Body, muleMarker) && account != "" && strings.EqualFold(comment.Author, account),
+				a.trusted(comment.Author, comment.AuthorAssociation):
+				// markers only vouch for the comments of mule itself
+				comments = append(comments, comment)
+			case notify && !noticed[comment.ID]:
+				r.noticeIgnored(number, comment.ID, "comment", comment.Author)
+			}
+		}
+		issue.Comments = comments
+	}
+}
+
+// authorizePRComments returns the feedback on the pull request that comes
+// from trusted authors. Open comments of others get a reply when notify is
+// set, which also acknowledges them.
+func (r *Repository) authorizePRComments(a *authorizer, pr *PullRequest, notify bool) []*Comment {
+	var comments []*Comment
+	for _, comment := range pr.Comments {
+		if comment.Acknowledged || a.trusted(comment.Author, comment.AuthorAssociation) {
+			comments = append(comments, comment)
+			continue
+		}
+		r.Logger.Info("Ignoring comment of untrusted author", "pullRequest", pr.Number, "comment", comment.ID, "author", comment.Author)
+		if !notify {
+			continue
+		}
+		body := fmt.Sprintf(replyMarker+"\n%s", comment.ID, ignoredNotice("comment", comment.Author))
+		var err error
+		if comment.Kind != "" {
+			err = r.Remote.CreatePRComment(r.RemotePath, pr.Number, types.Comment{Body: body})
+		} else {
+			err = r.Remote.ReplyToComment(r.RemotePath, pr.Number, comment.ID, body)
+		}
+		if err != nil {
+			r.Logger.Error(err, "Error replying to ignored comment", "comment", comment.ID)
+		}
+	}
+	return comments
+}
+
+func (r *Repository) noticeIgnored(issueNumber int, commentID int64, item, author string) {
+	body := fmt.Sprintf(ignoredMarker+"\n%s", commentID, ignoredNotice(item, author))
+	err := r.Remote.CreateIssueComment(r.RemotePath, issueNumber, types.Comment{Body: body})
+	if err != nil {
+		r.Logger.Error(err, "Error commenting on ignored item", "issue", issueNumber, "comment", commentID)
+	}
+}
+
+func ignoredNotice(item, author string) string {
+	if author == "" {
+		author = "its author"
+	} else {
+		author = "@" + author
+	}
+	return fmt.Sprintf("This %s is ignored, %s is not allowed to give instructions to mule in this repository.", item, author)
+}
+
+// ignoredNotices returns the IDs of the comments that already got a notice, 0
+// stands for the issue
+func ignoredNotices(comments []*Comment) map[int64]bool {
+	noticed := make(map[int64]bool)
+	for _, comment := range comments {
+		for _, match := range ignoredPattern.FindAllStringSubmatch(comment.Body, -1) {
+			if id, err := strconv.ParseInt(match[1], 10, 64); err == nil {
+				noticed[id] = true
+			}
+		}
+	}
+	return noticed
+}
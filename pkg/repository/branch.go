@@ -8,11 +8,15 @@ import (
 	"github.com/go-git/go-git/v5/plumbing"
 )
 
+// DefaultBaseBranch is used when the base branch of a repository is neither
+// configured nor detected from its remote
+const DefaultBaseBranch = "main"
+
 // wraps over repository to handle changes to branches
 
 
 func (r *Repository) CreateBranch(branchName string) error {
-	repo, err := git.PlainOpen(r.Path)
+	repo, err := openRepository(r.Path)
 	if err != nil {
 		return err
 	}
@@ -35,7 +39,7 @@ func (r *Repository) CreateBranch(branchName string) error {
 }
 
 func (r *Repository) CheckoutBranch(branchName string) error {
-	repo, err := git.PlainOpen(r.Path)
+	repo, err := openRepository(r.Path)
 	if err != nil {
 		return err
 	}
@@ -50,7 +54,110 @@ func (r *Repository) CheckoutBranch(branchName string) error {
 	})
 }
 
-func (r *Repository) createIssueBranch(issueTitle string) (string, error) {
+// baseBranch returns the branch issue branches are created from and pull
+// requests target
+func (r *Repository) baseBranch() string {
+	// the repository configuration file takes precedence
+	if base := r.repositoryConfig().BaseBranch; base != "" {
+		return base
+	}
+	return r.configuredBaseBranch()
+}
+
+// configuredBaseBranch returns the base branch of the repository settings,
+// the branch the configuration file is read from
+func (r *Repository) configuredBaseBranch() string {
+	r.Mu.RLock()
+	defer r.Mu.RUnlock()
+	if r.BaseBranch == "" {
+		return DefaultBaseBranch
+	}
+	return r.BaseBranch
+}
+
+// DetectBaseBranch returns the branch HEAD of the origin remote points to.
+// The local origin/HEAD reference is used when present, otherwise the remote
+// is asked.
+func (r *Repository) DetectBaseBranch() (string, error) {
+	repo, err := openRepository(r.Path)
+	if err != nil {
+		return "", err
+	}
+
+	ref, err := repo.Reference(plumbing.NewRemoteHEADReferenceName("origin"), false)
+	if err == nil && ref.Type() == plumbing.SymbolicReference {
+		return strings.TrimPrefix(ref.Target().Short(), "origin/"), nil
+	}
+
+	origin, err := repo.Remote("origin")
+	if err != nil {
+		return "", fmt.Errorf("error getting remote: %v", err)
+	}
+	auth, err := r.gitAuth(repo)
+	if err != nil {
+		return "", err
+	}
+	refs, err := origin.List(&git.ListOptions{Auth: auth})
+	if err != nil {
+		return "", fmt.Errorf("error listing remote references: %v", err)
+	}
+	for _, ref := range refs {
+		if ref.Name() == plumbing.HEAD && ref.Type() == plumbing.SymbolicReference {
+			return ref.Target().Short(), nil
+		}
+	}
+	return "", fmt.Errorf("remote HEAD not found")
+}
+
+// detectBaseBranch sets the base branch from the remote when none is
+// configured, keeping the default if it can't be detected
+func (r *Repository) detectBaseBranch() {
+	r.Mu.RLock()
+	configured := r.BaseBranch
+	r.Mu.RUnlock()
+	if configured != "" {
+		return
+	}
+	baseBranch, err := r.DetectBaseBranch()
+	if err != nil {
+		r.Logger.Error(err, "Error detecting base branch, using default", "default", DefaultBaseBranch)
+		return
+	}
+	r.Mu.Lock()
+	// the base branch may have been set while the remote was asked
+	if r.BaseBranch == "" {
+		r.BaseBranch = baseBranch
+	}
+	r.Mu.Unlock()
+}
+
+// checkoutBaseBranch checks out the base branch, creating it from origin
+// when it only exists on the remote
+func (r *Repository) checkoutBaseBranch() error {
+	repo, err := openRepository(r.Path)
+	if err != nil {
+		return err
+	}
+
+	baseBranch := r.baseBranch()
+	_, err = repo.Reference(plumbing.NewBranchReferenceName(baseBranch), true)
+	if err == plumbing.ErrReferenceNotFound {
+		remoteRef, err := repo.Reference(plumbing.NewRemoteReferenceName("origin", baseBranch), true)
+		if err != nil {
+			return fmt.Errorf("base branch %s not found: %v", baseBranch, err)
+		}
+		ref := plumbing.NewHashReference(plumbing.NewBranchReferenceName(baseBranch), remoteRef.Hash())
+		if err := repo.Storer.SetReference(ref); err != nil {
+			return err
+		}
+	} else if err != nil {
+		return err
+	}
+	return r.CheckoutBranch(baseBranch)
+}
+
+// issueBranchName derives the branch name for an issue from its title
+func issueBranchName(issueTitle string) string {
 	// Convert to lowercase and replace special characters with hyphens
 	branchName := strings.ToLower(issueTitle)
 	// Replace any character that isn't alphanumeric or hyphen with a hyphen
@@ -75,31 +182,11 @@ func (r *Repository) createIssueBranch(issueTitle string) (string, error) {
 		branchName = strings.TrimRight(branchName, "-")
 	}
 
-	err := r.Fetch()
-	if err != nil {
-		return "", fmt.Errorf("error fetching before creating branch: %w", err)
-	}
-
-	err = r.CheckoutBranch("main")
-	if err != nil {
-		return "", fmt.Errorf("error checking out main before creating branch: %w", err)
-	}
-
-	err = r.CreateBranch(branchName)
-	if err != nil {
-		return "", fmt.Errorf("error creating branch: %w", err)
-	}
-
-	err = r.CheckoutBranch(branchName)
-	if err != nil {
-		return "", fmt.Errorf("error checking out new branch: %w", err)
-	}
-
-	return branchName, nil
+	return branchName
 }
 
 func (r *Repository) Reset() error {
-	repo, err := git.PlainOpen(r.Path)
+	repo, err := openRepository(r.Path)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-logr/logr"
+
+	"github.com/mule-ai/mule/pkg/auth"
+)
+
+func runGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	cmd.Env = append(cmd.Environ(),
+		"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+		"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+	)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v failed: %v: %s", args, err, output)
+	}
+}
+
+// newRemote creates a repository whose HEAD points to the given branch
+func newRemote(t *testing.T, branch string) string {
+	t.Helper()
+	dir := t.TempDir()
+	runGit(t, dir, "init", "--initial-branch", branch)
+	runGit(t, dir, "commit", "--allow-empty", "-m", "initial commit")
+	return dir
+}
+
+func TestCloneDetectsBaseBranch(t *testing.T) {
+	remoteDir := newRemote(t, "develop")
+
+	repo := NewRepository(filepath.Join(t.TempDir(), "clone"))
+	repo.Logger = logr.Discard()
+	repo.Auth = auth.Settings{Method: auth.MethodHTTPS}
+	if err := repo.Clone(remoteDir); err != nil {
+		t.Fatalf("Clone returned error: %v", err)
+	}
+	if repo.BaseBranch != "develop" {
+		t.Errorf("expected base branch develop, got %q", repo.BaseBranch)
+	}
+
+	// detection works again from the recorded remote HEAD
+	detected, err := repo.DetectBaseBranch()
+	if err != nil {
+		t.Fatalf("DetectBaseBranch returned error: %v", err)
+	}
+	if detected != "develop" {
+		t.Errorf("expected detected base branch develop, got %q", detected)
+	}
+}
+
+func TestSetAuth(t *testing.T) {
+	repo := newClone(t)
+	runGit(t, repo.Path, "remote", "set-url", "origin", "https://github.com/mule-ai/mule.git")
+
+	if err := repo.SetAuth(auth.Settings{Method: auth.MethodSSH}); err != nil {
+		t.Fatalf("SetAuth returned error: %v", err)
+	}
+	if repo.Auth.Method != auth.MethodSSH {
+		t.Errorf("expected the ssh method, got %q", repo.Auth.Method)
+	}
+	if url := gitOutput(t, repo.Path, "remote", "get-url", "origin"); url != "git@github.com:mule-ai/mule.git" {
+		t.Errorf("expected origin to use ssh, got %q", url)
+	}
+}
+
+func TestDetectBaseBranchAsksRemote(t *testing.T) {
+	remoteDir := newRemote(t, "trunk")
+
+	// a repository cloned elsewhere without an origin/HEAD reference
+	localDir := t.TempDir()
+	runGit(t, localDir, "init", "--initial-branch", "trunk")
+	runGit(t, localDir, "remote", "add", "origin", remoteDir)
+	runGit(t, localDir, "fetch", "origin")
+
+	repo := NewRepository(localDir)
+	repo.Logger = logr.Discard()
+	repo.Auth = auth.Settings{Method: auth.MethodHTTPS}
+	detected, err := repo.DetectBaseBranch()
+	if err != nil {
+		t.Fatalf("DetectBaseBranch returned error: %v", err)
+	}
+	if detected != "trunk" {
+		t.Errorf("expected detected base branch trunk, got %q", detected)
+	}
+
+	// the base branch only exists on the remote, it is created on checkout
+	repo.BaseBranch = detected
+	if err := repo.checkoutBaseBranch(); err != nil {
+		t.Fatalf("checkoutBaseBranch returned error: %v", err)
+	}
+	status, err := repo.Status()
+	if err != nil {
+		t.Fatalf("Status returned error: %v", err)
+	}
+	if status.CurrentBranch != "trunk" {
+		t.Errorf("expected trunk to be checked out, got %q", status.CurrentBranch)
+	}
+}
+
+func TestIssueBranchName(t *testing.T) {
+	tests := map[string]string{
+		"Fix the build":            "fix-the-build",
+		"Add --verbose flag (CLI)": "add-verbose-flag-cli",
+		"  Ünïcode & symbols!! ":   "n-code-symbols",
+	}
+	for title, want := range tests {
+		if got := issueBranchName(title); got != want {
+			t.Errorf("issueBranchName(%q) = %q, want %q", title, got, want)
+		}
+	}
+}
+
+func TestBaseBranchDefault(t *testing.T) {
+	repo := NewRepository(t.TempDir())
+	if got := repo.baseBranch(); got != DefaultBaseBranch {
+		t.Errorf("expected %q, got %q", DefaultBaseBranch, got)
+	}
+	repo.BaseBranch = "master"
+	if got := repo.baseBranch(); got != "master" {
+		t.Errorf("expected master, got %q", got)
+	}
+}
+
+func TestDetectBaseBranchWhileRead(t *testing.T) {
+	remoteDir := newRemote(t, "trunk")
+	localDir := t.TempDir()
+	runGit(t, localDir, "init", "--initial-branch", "trunk")
+	runGit(t, localDir, "remote", "add", "origin", remoteDir)
+
+	repo := NewRepository(localDir)
+	repo.Logger = logr.Discard()
+	repo.Auth = auth.Settings{Method: auth.MethodHTTPS}
+
+	// the sync reads the base branch while detection sets it, run with -race
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		repo.detectBaseBranch()
+	}()
+	for detecting := true; detecting; {
+		select {
+		case <-done:
+			detecting = false
+		default:
+			if got := repo.baseBranch(); got != DefaultBaseBranch && got != "trunk" {
+				t.Fatalf("unexpected base branch %q", got)
+			}
+		}
+	}
+	if got := repo.baseBranch(); got != "trunk" {
+		t.Errorf("expected the detected base branch trunk, got %q", got)
+	}
+}
@@ -0,0 +1,194 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// cleans up after the pull requests of mule once they are merged or closed
+
+// PullRequestOutcome records how the pull request of an issue ended and what
+// was cleaned up afterwards
+type PullRequestOutcome struct {
+	PullRequest int `json:"pullRequest"`
+	// State is merged or closed
+	State               string `json:"state"`
+	Branch              string `json:"branch,omitempty"`
+	LocalBranchDeleted  bool   `json:"localBranchDeleted,omitempty"`
+	RemoteBranchDeleted bool   `json:"remoteBranchDeleted,omitempty"`
+	// IssueClosed is set when mule closed the issue because the provider
+	// left it open after the merge
+	IssueClosed bool      `json:"issueClosed,omitempty"`
+	FinishedAt  time.Time `json:"finishedAt"`
+}
+
+// reconcilePullRequests looks up the pull requests of the work states that
+// are no longer open. Their branches are deleted when the repository is
+// configured to, a merged pull request closes its issue if it is still open,
+// the issue of a pull request closed without merging is declined. A pull
+// request whose cleanup failed is tried again on the next sync.
+func (r *Repository) reconcilePullRequests() {
+	states := r.WorkStates()
+	for _, number := range slices.Sorted(maps.Keys(states)) {
+		state := states[number]
+		if state.PullRequest == 0 {
+			continue
+		}
+		if state.Outcome != nil && state.Outcome.PullRequest == state.PullRequest {
+			continue
+		}
+		if pr, ok := r.PullRequests[state.PullRequest]; ok && pr.Open() {
+			continue
+		}
+
+		pullRequest, err := r.Remote.FetchPullRequest(r.RemotePath, state.PullRequest)
+		if err != nil {
+			r.Logger.Error(err, "Error fetching pull request", "pullRequest", state.PullRequest)
+			continue
+		}
+		if pullRequest.State != "merged" && pullRequest.State != "closed" {
+			continue
+		}
+		if pullRequest.Branch == "" {
+			pullRequest.Branch = state.Branch
+		}
+
+		r.Logger.Info("Pull request finished", "pullRequest", pullRequest.Number, "state", pullRequest.State)
+		outcome, err := r.finishPullRequest(number, pullRequest.Number, pullRequest.State, pullRequest.Branch)
+		update := func(state *IssueWorkState) {
+			if err != nil {
+				state.LastError = err.Error()
+				return
+			}
+			state.Outcome = outcome
+			state.LastError = ""
+			if outcome.State == "merged" {
+				state.Status = WorkDone
+			} else {
+				// working on the issue again would only open another pull
+				// request, until the issue is edited
+				state.Status = WorkDeclined
+			}
+		}
+		if err := r.workState().Update(r.workStatePath(), number, update); err != nil {
+			r.Logger.Error(err, "Error saving work state", "issue", number)
+		}
+	}
+}
+
+// finishPullRequest cleans up after a merged or closed pull request of an
+// issue
+func (r *Repository) finishPullRequest(issueNumber, prNumber int, state, branch string) (*PullRequestOutcome, error) {
+	outcome := &PullRequestOutcome{
+		PullRequest: prNumber,
+		State:       state,
+		Branch:      branch,
+		FinishedAt:  time.Now(),
+	}
+	var errs []error
+	if r.DeleteBranches && branch != "" && branch != r.baseBranch() {
+		deleted, err := r.deleteLocalBranch(branch)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		outcome.LocalBranchDeleted = deleted
+
+		deleted, err = r.deleteRemoteBranch(branch)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		outcome.RemoteBranchDeleted = deleted
+	}
+
+	// most providers close the issue themselves when the pull request
+	// references it, the ones still listed as open didn't
+	if _, open := r.Issues[issueNumber]; open && state == "merged" {
+		if err := r.Remote.UpdateIssueState(issueNumber, "closed"); err != nil {
+			errs = append(errs, fmt.Errorf("error closing issue %d: %v", issueNumber, err))
+		} else {
+			outcome.IssueClosed = true
+			delete(r.Issues, issueNumber)
+		}
+	}
+	return outcome, errors.Join(errs...)
+}
+
+// deleteLocalBranch removes the branch and its worktree, it reports false
+// when there was no such branch
+func (r *Repository) deleteLocalBranch(branch string) (bool, error) {
+	r.worktreeMu.Lock()
+	defer r.worktreeMu.Unlock()
+
+	dir, err := r.worktreesDir()
+	if err != nil {
+		return false, err
+	}
+	path := filepath.Join(dir, branch)
+	if _, err := os.Stat(path); err == nil {
+		if err := r.removeWorktree(path); err != nil {
+			return false, err
+		}
+	}
+
+	if r.git("rev-parse", "--verify", "--quiet", "refs/heads/"+branch) != nil {
+		return false, nil
+	}
+	if err := r.git("branch", "-D", branch); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// deleteRemoteBranch removes the branch from origin, it reports false when
+// the branch was already gone, for example because the provider deletes
+// merged branches itself
+func (r *Repository) deleteRemoteBranch(branch string) (bool, error) {
+	repo, err := openRepository(r.Path)
+	if err != nil {
+		return false, err
+	}
+	auth, err := r.gitAuth(repo)
+	if err != nil {
+		return false, err
+	}
+	origin, err := repo.Remote("origin")
+	if err != nil {
+		return false, fmt.Errorf("error getting remote: %v", err)
+	}
+	refs, err := origin.List(&git.ListOptions{Auth: auth})
+	if err != nil {
+		return false, fmt.Errorf("error listing remote branches: %v", err)
+	}
+
+	refName := plumbing.NewBranchReferenceName(branch)
+	exists := slices.ContainsFunc(refs, func(ref *plumbing.Reference) bool {
+		return ref.Name() == refName
+	})
+	if exists {
+		r.Logger.Info("Deleting remote branch", "branch", branch)
+		err = repo.Push(&git.PushOptions{
+			RemoteName: "origin",
+			RefSpecs:   []config.RefSpec{config.RefSpec(":" + refName.String())},
+			Auth:       auth,
+		})
+		if err != nil && err != git.NoErrAlreadyUpToDate {
+			return false, fmt.Errorf("error deleting remote branch %s: %v", branch, err)
+		}
+	}
+
+	// the remote tracking branch would otherwise outlive the branch
+	err = repo.Storer.RemoveReference(plumbing.NewRemoteReferenceName("origin", branch))
+	if err != nil {
+		return exists, fmt.Errorf("error removing remote tracking branch: %v", err)
+	}
+	return exists, nil
+}
@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/mule-ai/mule/pkg/agent"
+	"github.com/mule-ai/mule/pkg/remote/local"
+	"github.com/mule-ai/mule/pkg/remote/types"
+)
+
+func TestReconcilePullRequests(t *testing.T) {
+	repo := newClone(t)
+	remoteDir := gitOutput(t, repo.Path, "remote", "get-url", "origin")
+	commitFile(t, remoteDir, "fix-a", "a.txt", "a")
+	if err := repo.Fetch(); err != nil {
+		t.Fatalf("Fetch returned error: %v", err)
+	}
+	worktree, err := repo.issueWorktree("fix-a")
+	if err != nil {
+		t.Fatalf("issueWorktree returned error: %v", err)
+	}
+
+	provider := &local.Provider{
+		Issues: map[int]*types.Issue{
+			1: {Number: 1, State: "open", Labels: []string{"mule"}},
+			3: {Number: 3, State: "open", Labels: []string{"mule"}},
+		},
+		PullRequests: map[int]*types.PullRequest{
+			2: {Number: 2, State: "merged", Branch: "fix-a"},
+			4: {Number: 4, State: "closed", Branch: "fix-b"},
+		},
+	}
+	repo.Remote = provider
+	repo.RemotePath = "local"
+	repo.DeleteBranches = true
+	repo.Issues = map[int]*Issue{
+		1: ghIssueToIssue(*provider.Issues[1]),
+		3: ghIssueToIssue(*provider.Issues[3]),
+	}
+	repo.setWorkState(repo.Issues[1], func(state *IssueWorkState) {
+		state.Status = WorkPROpen
+		state.PullRequest = 2
+	})
+	repo.setWorkState(repo.Issues[3], func(state *IssueWorkState) {
+		state.Status = WorkPROpen
+		state.PullRequest = 4
+	})
+
+	repo.reconcilePullRequests()
+
+	merged := repo.WorkStates()[1]
+	if merged.Status != WorkDone || merged.Outcome == nil {
+		t.Fatalf("expected the merged pull request to finish the issue, got %+v", merged)
+	}
+	if outcome := *merged.Outcome; outcome.State != "merged" || !outcome.LocalBranchDeleted || !outcome.RemoteBranchDeleted || !outcome.IssueClosed {
+		t.Errorf("unexpected outcome for the merged pull request: %+v", outcome)
+	}
+	if state := provider.Issues[1].State; state != "closed" {
+		t.Errorf("expected the issue of the merged pull request to be closed, got %q", state)
+	}
+	if _, err := os.Stat(worktree.Path); !os.IsNotExist(err) {
+		t.Errorf("expected the worktree to be removed, got %v", err)
+	}
+	for _, dir := range []string{repo.Path, remoteDir} {
+		if exec.Command("git", "-C", dir, "rev-parse", "--verify", "refs/heads/fix-a").Run() == nil {
+			t.Errorf("expected fix-a to be deleted in %s", dir)
+		}
+	}
+
+	closed := repo.WorkStates()[3]
+	if closed.Status != WorkDeclined || closed.Outcome == nil || closed.Outcome.State != "closed" || closed.Outcome.IssueClosed {
+		t.Errorf("unexpected state for the closed pull request: %+v, %+v", closed, closed.Outcome)
+	}
+	if state := provider.Issues[3].State; state != "open" {
+		t.Errorf("expected the issue of the closed pull request to stay open, got %q", state)
+	}
+}
+
+func TestDeclinedPullRequestIsNotRedone(t *testing.T) {
+	repo := newClone(t)
+	runGit(t, repo.Path, "branch", "fix-the-build")
+	provider := &local.Provider{
+		Path:         repo.Path,
+		Issues:       map[int]*types.Issue{3: {Number: 3, Title: "Fix the build", State: "open", Labels: []string{"mule"}}},
+		PullRequests: map[int]*types.PullRequest{4: {Number: 4, State: "closed", Branch: "fix-the-build"}},
+	}
+	repo.Remote = provider
+	repo.RemotePath = "local"
+	repo.Issues = map[int]*Issue{3: ghIssueToIssue(*provider.Issues[3])}
+	repo.setWorkState(repo.Issues[3], func(state *IssueWorkState) {
+		state.Status = WorkPROpen
+		state.PullRequest = 4
+	})
+
+	// the workflow has no steps, a run would fail and count an attempt
+	for i := 0; i < 2; i++ {
+		if err := repo.syncIssues(nil, &agent.Workflow{}, nil, &SyncReport{}); err != nil {
+			t.Fatalf("syncIssues returned error: %v", err)
+		}
+		state := repo.WorkStates()[3]
+		if state.Status != WorkDeclined || state.Attempts != 0 {
+			t.Fatalf("expected the issue of the closed pull request to be left alone, got %+v", state)
+		}
+	}
+
+	// editing the issue asks for another try
+	provider.Issues[3].Body = "The linker fails on arm64."
+	if err := repo.syncIssues(nil, &agent.Workflow{}, nil, &SyncReport{}); err != nil {
+		t.Fatalf("syncIssues returned error: %v", err)
+	}
+	if state := repo.WorkStates()[3]; state.Status == WorkDeclined || state.Attempts != 1 {
+		t.Errorf("expected the edited issue to be worked on again, got %+v", state)
+	}
+}
@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// decides who the commits of mule are made by and how they are signed
+
+const (
+	// DefaultCommitName and DefaultCommitEmail are the author of the commits
+	// when a repository does not set one
+	DefaultCommitName  = "mule"
+	DefaultCommitEmail = "mule@muleai.io"
+)
+
+type SigningFormat string
+
+/*
+Formats:
+gpg: signed with gpg, the key is a key ID or fingerprint
+ssh: signed with ssh-keygen, the key is the path of a key file or a public key
+prefixed with "key::"
+*/
+const (
+	SigningGPG SigningFormat = "gpg"
+	SigningSSH SigningFormat = "ssh"
+)
+
+// CommitSettings are the identity mule commits with
+type CommitSettings struct {
+	// AuthorName and AuthorEmail default to mule
+	AuthorName  string `json:"authorName,omitempty"`
+	AuthorEmail string `json:"authorEmail,omitempty"`
+	// CommitterName and CommitterEmail default to the author
+	CommitterName  string `json:"committerName,omitempty"`
+	CommitterEmail string `json:"committerEmail,omitempty"`
+	// Signing signs the commits with SigningKey, empty leaves them unsigned
+	Signing    SigningFormat `json:"signing,omitempty"`
+	SigningKey string        `json:"signingKey,omitempty"`
+	// CoAuthor credits the author of the issue with a Co-authored-by trailer
+	CoAuthor bool `json:"coAuthor,omitempty"`
+}
+
+// Normalize trims the settings and checks the identities and the signing
+// configuration
+func (c CommitSettings) Normalize() (CommitSettings, error) {
+	normalized := CommitSettings{
+		AuthorName:     strings.TrimSpace(c.AuthorName),
+		AuthorEmail:    strings.TrimSpace(c.AuthorEmail),
+		CommitterName:  strings.TrimSpace(c.CommitterName),
+		CommitterEmail: strings.TrimSpace(c.CommitterEmail),
+		Signing:        SigningFormat(strings.ToLower(strings.TrimSpace(string(c.Signing)))),
+		SigningKey:     strings.TrimSpace(c.SigningKey),
+		CoAuthor:       c.CoAuthor,
+	}
+	for _, value := range []string{normalized.AuthorName, normalized.AuthorEmail, normalized.CommitterName, normalized.CommitterEmail} {
+		if strings.ContainsAny(value, "<>\n") {
+			return CommitSettings{}, fmt.Errorf("invalid commit identity %q", value)
+		}
+	}
+	switch normalized.Signing {
+	case "":
+		if normalized.SigningKey != "" {
+			return CommitSettings{}, fmt.Errorf("a signing key needs a signing format")
+		}
+	case SigningGPG, SigningSSH:
+		if normalized.SigningKey == "" {
+			return CommitSettings{}, fmt.Errorf("%s signing needs a signing key", normalized.Signing)
+		}
+	default:
+		return CommitSettings{}, fmt.Errorf("unknown signing format: %s", c.Signing)
+	}
+	return normalized, nil
+}
+
+// commitSettings returns the commit settings with the defaults filled in
+func (r *Repository) commitSettings() CommitSettings {
+	r.Mu.RLock()
+	settings := r.Commits
+	r.Mu.RUnlock()
+	if settings.AuthorName == "" {
+		settings.AuthorName = DefaultCommitName
+	}
+	if settings.AuthorEmail == "" {
+		settings.AuthorEmail = DefaultCommitEmail
+	}
+	if settings.CommitterName == "" {
+		settings.CommitterName = settings.AuthorName
+	}
+	if settings.CommitterEmail == "" {
+		settings.CommitterEmail = settings.AuthorEmail
+	}
+	return settings
+}
+
+// gitArgs are the git options new commits get the author and signature of
+// the settings with
+func (c CommitSettings) gitArgs() []string {
+	args := []string{"-c", "user.name=" + c.AuthorName, "-c", "user.email=" + c.AuthorEmail}
+	switch c.Signing {
+	case SigningGPG:
+		args = append(args, "-c", "commit.gpgSign=true", "-c", "gpg.format=openpgp", "-c", "user.signingKey="+c.SigningKey)
+	case SigningSSH:
+		args = append(args, "-c", "commit.gpgSign=true", "-c", "gpg.format=ssh", "-c", "user.signingKey="+c.SigningKey)
+	default:
+		// the global git config of the host doesn't apply
+		args = append(args, "-c", "commit.gpgSign=false")
+	}
+	return args
+}
+
+// gitEnv sets the committer, which git takes from the environment
+func (c CommitSettings) gitEnv() []string {
+	return []string{
+		"GIT_COMMITTER_NAME=" + c.CommitterName,
+		"GIT_COMMITTER_EMAIL=" + c.CommitterEmail,
+	}
+}
+
+// gitCommit runs a git command that creates commits with the commit settings
+// of the repository. Rebased commits keep their author.
+func (r *Repository) gitCommit(args ...string) error {
+	settings := r.commitSettings()
+	cmd := exec.Command("git", append(append([]string{"-C", r.Path}, settings.gitArgs()...), args...)...)
+	cmd.Env = append(os.Environ(), settings.gitEnv()...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("git %s: %v: %s", strings.Join(args, " "), err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// withCoAuthor adds a trailer crediting the author of the issue to the
+// commit message, if the repository is configured to and the provider
+// reported an address for them
+func (r *Repository) withCoAuthor(message string, issue *Issue) string {
+	if issue == nil || issue.Author == "" || issue.AuthorEmail == "" || !r.commitSettings().CoAuthor {
+		return message
+	}
+	trailer := fmt.Sprintf("Co-authored-by: %s <%s>", issue.Author, issue.AuthorEmail)
+	if strings.Contains(message, trailer) {
+		return message
+	}
+	return strings.TrimRight(message, "\n") + "\n\n" + trailer
+}
@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeCommitSettings(t *testing.T) {
+	settings, err := CommitSettings{AuthorName: " Release Bot ", Signing: "SSH", SigningKey: " ~/.ssh/id_ed25519.pub "}.Normalize()
+	if err != nil {
+		t.Fatalf("Normalize returned error: %v", err)
+	}
+	if settings.AuthorName != "Release Bot" || settings.Signing != SigningSSH || settings.SigningKey != "~/.ssh/id_ed25519.pub" {
+		t.Errorf("unexpected settings: %+v", settings)
+	}
+
+	for _, invalid := range []CommitSettings{
+		{Signing: "x509", SigningKey: "key"},
+		{Signing: SigningGPG},
+		{SigningKey: "ABCD1234"},
+		{AuthorEmail: "<bot@example.com>"},
+	} {
+		if _, err := invalid.Normalize(); err == nil {
+			t.Errorf("expected an error for %+v", invalid)
+		}
+	}
+}
+
+func writeFile(t *testing.T, dir, file, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, file), []byte(content), 0644); err != nil {
+		t.Fatalf("error writing file: %v", err)
+	}
+}
+
+func TestCommitIdentity(t *testing.T) {
+	repo := newClone(t)
+	writeFile(t, repo.Path, "a.txt", "a")
+	if err := repo.Commit("# Add a\n\nA file."); err != nil {
+		t.Fatalf("Commit returned error: %v", err)
+	}
+	if identity := gitOutput(t, repo.Path, "log", "-1", "--format=%an <%ae> %cn <%ce>"); identity != "mule <mule@muleai.io> mule <mule@muleai.io>" {
+		t.Errorf("expected mule as the default author and committer, got %q", identity)
+	}
+	if message := gitOutput(t, repo.Path, "log", "-1", "--format=%B"); message != "# Add a\n\nA file." {
+		t.Errorf("expected the message to be kept as it is, got %q", message)
+	}
+
+	repo.Commits = CommitSettings{
+		AuthorName:     "Release Bot",
+		AuthorEmail:    "bot@example.com",
+		CommitterName:  "CI",
+		CommitterEmail: "ci@example.com",
+	}
+	writeFile(t, repo.Path, "b.txt", "b")
+	if err := repo.Commit("add b"); err != nil {
+		t.Fatalf("Commit returned error: %v", err)
+	}
+	if identity := gitOutput(t, repo.Path, "log", "-1", "--format=%an <%ae> %cn <%ce>"); identity != "Release Bot <bot@example.com> CI <ci@example.com>" {
+		t.Errorf("expected the configured author and committer, got %q", identity)
+	}
+}
+
+func TestCommitSSHSigning(t *testing.T) {
+	if _, err := exec.LookPath("ssh-keygen"); err != nil {
+		t.Skip("ssh-keygen not available")
+	}
+	repo := newClone(t)
+	key := filepath.Join(t.TempDir(), "id_ed25519")
+	if output, err := exec.Command("ssh-keygen", "-q", "-t", "ed25519", "-N", "", "-f", key).CombinedOutput(); err != nil {
+		t.Fatalf("ssh-keygen failed: %v: %s", err, output)
+	}
+	repo.Commits = CommitSettings{Signing: SigningSSH, SigningKey: key}
+
+	writeFile(t, repo.Path, "a.txt", "a")
+	if err := repo.Commit("add a"); err != nil {
+		t.Fatalf("Commit returned error: %v", err)
+	}
+	if commit := gitOutput(t, repo.Path, "cat-file", "commit", "HEAD"); !strings.Contains(commit, "-----BEGIN SSH SIGNATURE-----") {
+		t.Errorf("expected an ssh signature, got:\n%s", commit)
+	}
+}
+
+func TestWithCoAuthor(t *testing.T) {
+	repo := NewRepository(t.TempDir())
+	issue := &Issue{Author: "alice", AuthorEmail: "9+alice@users.noreply.github.com"}
+	if message := repo.withCoAuthor("fix the build", issue); message != "fix the build" {
+		t.Errorf("expected no trailer unless configured, got %q", message)
+	}
+
+	repo.Commits.CoAuthor = true
+	expected := "fix the build\n\nCo-authored-by: alice <9+alice@users.noreply.github.com>"
+	if message := repo.withCoAuthor("fix the build\n", issue); message != expected {
+		t.Errorf("expected the trailer, got %q", message)
+	}
+	if message := repo.withCoAuthor(expected, issue); message != expected {
+		t.Errorf("expected the trailer to be added once, got %q", message)
+	}
+	if message := repo.withCoAuthor("fix the build", &Issue{Author: "bob"}); message != "fix the build" {
+		t.Errorf("expected no trailer without an address, got %q", message)
+	}
+}
@@ -0,0 +1,451 @@
+package repository
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/mule-ai/mule/pkg/agent"
+)
+
+// follows the commit message conventions and the pull request template of a
+// repository when generating commit messages and pull requests
+
+// DefaultMessageAttempts is the number of generated messages that may be
+// rejected before a deterministic message is used instead
+const DefaultMessageAttempts = 3
+
+// PRTemplateFiles are where pull request templates are looked for, the first
+// one found is used
+var PRTemplateFiles = []string{
+	".github/pull_request_template.md",
+	".github/PULL_REQUEST_TEMPLATE.md",
+	"pull_request_template.md",
+	"PULL_REQUEST_TEMPLATE.md",
+	"docs/pull_request_template.md",
+	"docs/PULL_REQUEST_TEMPLATE.md",
+	".gitea/pull_request_template.md",
+	".gitlab/merge_request_templates/Default.md",
+}
+
+// CommitlintFiles are the commitlint configurations that are looked for, the
+// first one found is used. package.json counts when it has a commitlint key.
+var CommitlintFiles = []string{
+	".commitlintrc",
+	".commitlintrc.json",
+	".commitlintrc.yaml",
+	".commitlintrc.yml",
+	".commitlintrc.js",
+	".commitlintrc.cjs",
+	".commitlintrc.mjs",
+	".commitlintrc.ts",
+	"commitlint.config.js",
+	"commitlint.config.cjs",
+	"commitlint.config.mjs",
+	"commitlint.config.ts",
+	"package.json",
+}
+
+// conventionalTypes are the types allowed by @commitlint/config-conventional
+var conventionalTypes = []string{"build", "chore", "ci", "docs", "feat", "fix", "perf", "refactor", "revert", "style", "test"}
+
+// headerPattern matches a conventional commit header, type(scope)!: subject
+var headerPattern = regexp.MustCompile(`^([^\s():!]+)(?:\(([^()]*)\))?(!)?: (.*)$`)
+
+// templateHeadingPattern matches the markdown headings of a template
+var templateHeadingPattern = regexp.MustCompile(`(?m)^#{1,6}[ \t]+(.+?)[ \t#]*$`)
+
+// Conventions are the commit message rules and the pull request template of
+// a checkout
+type Conventions struct {
+	// Commit is nil when the repository has no commitlint configuration
+	Commit     *CommitRules
+	PRTemplate string
+}
+
+// CommitRules are the rules of @commitlint/config-conventional with the
+// rules of the configuration applied over them. Limits of 0 are disabled.
+type CommitRules struct {
+	// Source is the configuration file and Config the part of it about
+	// commitlint, which the agents get to see
+	Source            string
+	Config            string
+	Types             []string
+	Scopes            []string
+	HeaderMaxLength   int
+	BodyMaxLineLength int
+}
+
+// commitlintConfig is the part of a commitlint configuration that can be
+// read without running it
+type commitlintConfig struct {
+	Rules map[string][]any `json:"rules" yaml:"rules"`
+}
+
+// loadConventions reads the commitlint configuration and the pull request
+// template of the checkout
+func (r *Repository) loadConventions() (Conventions, error) {
+	var conventions Conventions
+	for _, name := range PRTemplateFiles {
+		data, err := os.ReadFile(filepath.Join(r.Path, name))
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return conventions, fmt.Errorf("error reading %s: %v", name, err)
+		}
+		conventions.PRTemplate = strings.TrimSpace(string(data))
+		break
+	}
+	for _, name := range CommitlintFiles {
+		data, err := os.ReadFile(filepath.Join(r.Path, name))
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return conventions, fmt.Errorf("error reading %s: %v", name, err)
+		}
+		rules, err := ParseCommitlintConfig(name, data)
+		if err != nil {
+			return conventions, err
+		}
+		if rules != nil {
+			conventions.Commit = rules
+			break
+		}
+	}
+	return conventions, nil
+}
+
+// ParseCommitlintConfig reads the rules of a commitlint configuration. The
+// rules of JavaScript configurations can't be read, they get the rules of
+// config-conventional. A package.json without a commitlint key gives nil.
+func ParseCommitlintConfig(name string, data []byte) (*CommitRules, error) {
+	rules := &CommitRules{
+		Source:            name,
+		Config:            strings.TrimSpace(string(data)),
+		Types:             conventionalTypes,
+		HeaderMaxLength:   100,
+		BodyMaxLineLength: 100,
+	}
+	var config commitlintConfig
+	switch {
+	case filepath.Base(name) == "package.json":
+		var pkg struct {
+			Commitlint json.RawMessage `json:"commitlint"`
+		}
+		if err := json.Unmarshal(data, &pkg); err != nil {
+			return nil, fmt.Errorf("error decoding %s: %v", name, err)
+		}
+		if len(pkg.Commitlint) == 0 {
+			return nil, nil
+		}
+		rules.Config = string(pkg.Commitlint)
+		if err := json.Unmarshal(pkg.Commitlint, &config); err != nil {
+			return nil, fmt.Errorf("error decoding the commitlint key of %s: %v", name, err)
+		}
+	case slices.Contains([]string{".js", ".cjs", ".mjs", ".ts"}, filepath.Ext(name)):
+		return rules, nil
+	default:
+		// JSON is valid YAML, .commitlintrc may be either
+		if err := yaml.Unmarshal(data, &config); err != nil {
+			return nil, fmt.Errorf("error decoding %s: %v", name, err)
+		}
+	}
+
+	for rule, value := range config.Rules {
+		// rules are [level, "always" or "never", value], level 0 disables
+		// them and level 1 only warns
+		if len(value) == 0 {
+			continue
+		}
+		level, _ := value[0].(int)
+		if f, ok := value[0].(float64); ok {
+			level = int(f)
+		}
+		enabled := level == 2 && (len(value) < 2 || value[1] == "always")
+		var arg any
+		if len(value) > 2 {
+			arg = value[2]
+		}
+		switch rule {
+		case "type-enum":
+			rules.Types = nil
+			if enabled {
+				rules.Types = toStrings(arg)
+			}
+		case "scope-enum":
+			rules.Scopes = nil
+			if enabled {
+				rules.Scopes = toStrings(arg)
+			}
+		case "header-max-length":
+			rules.HeaderMaxLength = 0
+			if enabled {
+				rules.HeaderMaxLength = toInt(arg)
+			}
+		case "body-max-line-length":
+			rules.BodyMaxLineLength = 0
+			if enabled {
+				rules.BodyMaxLineLength = toInt(arg)
+			}
+		}
+	}
+	return rules, nil
+}
+
+func toStrings(value any) []string {
+	values, _ := value.([]any)
+	var strs []string
+	for _, value := range values {
+		if s, ok := value.(string); ok {
+			strs = append(strs, s)
+		}
+	}
+	return strs
+}
+
+func toInt(value any) int {
+	switch n := value.(type) {
+	case int:
+		return n
+	case float64:
+		return int(n)
+	}
+	return 0
+}
+
+// Validate checks a commit message or, without a body, a pull request title
+// against the rules. Every problem found is returned.
+func (c *CommitRules) Validate(message string) error {
+	header, body, _ := strings.Cut(strings.TrimSpace(message), "\n")
+	match := headerPattern.FindStringSubmatch(header)
+	if match == nil {
+		return fmt.Errorf("the header %q is not of the form type(scope): subject", header)
+	}
+	commitType, scope, subject := match[1], match[2], match[4]
+
+	var errs []error
+	if commitType != strings.ToLower(commitType) {
+		errs = append(errs, fmt.Errorf("the type %q must be lower case", commitType))
+	}
+	if len(c.Types) > 0 && !slices.Contains(c.Types, commitType) {
+		errs = append(errs, fmt.Errorf("the type %q must be one of %s", commitType, strings.Join(c.Types, ", ")))
+	}
+	if scope != "" && len(c.Scopes) > 0 && !slices.Contains(c.Scopes, scope) {
+		errs = append(errs, fmt.Errorf("the scope %q must be one of %s", scope, strings.Join(c.Scopes, ", ")))
+	}
+	if strings.TrimSpace(subject) == "" {
+		errs = append(errs, fmt.Errorf("the subject is empty"))
+	} else {
+		if first, _ := utf8.DecodeRuneInString(subject); unicode.IsUpper(first) {
+			errs = append(errs, fmt.Errorf("the subject %q must start with a lower case letter", subject))
+		}
+		if strings.HasSuffix(subject, ".") {
+			errs = append(errs, fmt.Errorf("the subject must not end with a period"))
+		}
+	}
+	if length := utf8.RuneCountInString(header); c.HeaderMaxLength > 0 && length > c.HeaderMaxLength {
+		errs = append(errs, fmt.Errorf("the header is %d characters long, at most %d are allowed", length, c.HeaderMaxLength))
+	}
+	if body != "" && strings.TrimSpace(strings.SplitN(body, "\n", 2)[0]) != "" {
+		errs = append(errs, fmt.Errorf("the body must be separated from the header by a blank line"))
+	}
+	for _, line := range strings.Split(body, "\n") {
+		if length := utf8.RuneCountInString(line); c.BodyMaxLineLength > 0 && length > c.BodyMaxLineLength {
+			errs = append(errs, fmt.Errorf("body lines must be at most %d characters long", c.BodyMaxLineLength))
+			break
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// instructions describes the rules to the commit agent, what names the
+// message, like "commit messages"
+func (c *CommitRules) instructions(what string) string {
+	var rules strings.Builder
+	fmt.Fprintf(&rules, "The %s of this repository must follow the Conventional Commits specification, they are checked by commitlint:\n", what)
+	rules.WriteString("- the header is `type(scope): subject`, the scope is optional\n")
+	if len(c.Types) > 0 {
+		fmt.Fprintf(&rules, "- the type is one of: %s\n", strings.Join(c.Types, ", "))
+	}
+	if len(c.Scopes) > 0 {
+		fmt.Fprintf(&rules, "- the scope is one of: %s\n", strings.Join(c.Scopes, ", "))
+	}
+	rules.WriteString("- the subject starts with a lower case letter and doesn't end with a period\n")
+	if c.HeaderMaxLength > 0 {
+		fmt.Fprintf(&rules, "- the header is at most %d characters long\n", c.HeaderMaxLength)
+	}
+	if c.BodyMaxLineLength > 0 {
+		fmt.Fprintf(&rules, "- a body is separated from the header by a blank line, its lines are at most %d characters long\n", c.BodyMaxLineLength)
+	}
+	if c.Config != "" {
+		fmt.Fprintf(&rules, "\nThe commitlint configuration in %s:\n\n```\n%s\n```", c.Source, c.Config)
+	}
+	return strings.TrimSpace(rules.String())
+}
+
+// fallback builds a message following the rules from subject, for when the
+// generated messages kept being rejected. The type is taken from a label
+// naming one or from a subject that is a header already, fix otherwise.
+func (c *CommitRules) fallback(subject string, labels []string) string {
+	var types []string
+	types = append(types, labels...)
+	subject = strings.TrimRight(strings.TrimSpace(subject), ".")
+	if match := headerPattern.FindStringSubmatch(subject); match != nil {
+		// the issue title may already be a conventional header
+		types = append(types, match[1])
+		subject = match[4]
+	}
+	commitType := "fix"
+	for _, name := range types {
+		if slices.Contains(c.Types, name) {
+			commitType = name
+			break
+		}
+	}
+	if len(c.Types) > 0 && !slices.Contains(c.Types, commitType) {
+		commitType = c.Types[0]
+	}
+	if first, size := utf8.DecodeRuneInString(subject); unicode.IsUpper(first) {
+		subject = string(unicode.ToLower(first)) + subject[size:]
+	}
+	if subject == "" {
+		subject = "update"
+	}
+	header := commitType + ": " + subject
+	if c.HeaderMaxLength > 0 && utf8.RuneCountInString(header) > c.HeaderMaxLength {
+		// cut at the last word that fits
+		header = string([]rune(header)[:c.HeaderMaxLength])
+		if i := strings.LastIndex(header, " "); i > len(commitType)+1 {
+			header = header[:i]
+		}
+		header = strings.TrimSpace(header)
+	}
+	return header
+}
+
+// templateInstructions describes the pull request template to the pull
+// request body agent
+func templateInstructions(template string) string {
+	return fmt.Sprintf("Pull request descriptions of this repository follow its template. Fill in every section of the template and keep its headings:\n\n```markdown\n%s\n```", template)
+}
+
+// validateTemplate checks that a pull request description keeps the headings
+// of the template
+func validateTemplate(template, description string) error {
+	present := make(map[string]bool)
+	for _, match := range templateHeadingPattern.FindAllStringSubmatch(description, -1) {
+		present[strings.ToLower(match[1])] = true
+	}
+	var missing []string
+	for _, match := range templateHeadingPattern.FindAllStringSubmatch(template, -1) {
+		if !present[strings.ToLower(match[1])] {
+			missing = append(missing, match[1])
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("the description is missing the template headings: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// templateFallback fills the template with a reference to the issue, for when
+// the generated descriptions kept being rejected
+func templateFallback(template string, issue *Issue) string {
+	return fmt.Sprintf("This pull request works on #%d: %s\n\n%s", issue.ID, issue.Title, template)
+}
+
+// generateCommitMessage generates a commit message following the commit
+// rules, subject and labels are what the fallback message is made of
+func (r *Repository) generateCommitMessage(a *agent.Agent, input agent.PromptInput, conventions Conventions, subject string, labels []string) (string, error) {
+	rules := conventions.Commit
+	if rules == nil {
+		return r.generateMessage(a, input, "", nil, nil)
+	}
+	return r.generateMessage(a, input, rules.instructions("commit messages"), rules.Validate, func() string {
+		return rules.fallback(subject, labels)
+	})
+}
+
+// generatePRTitle generates a pull request title, which becomes the header of
+// the commit when the pull request is squashed, so it follows the commit rules
+func (r *Repository) generatePRTitle(a *agent.Agent, input agent.PromptInput, conventions Conventions, issue *Issue) (string, error) {
+	rules := conventions.Commit
+	if rules == nil {
+		return r.generateMessage(a, input, "", nil, nil)
+	}
+	validate := func(title string) error {
+		if strings.Contains(title, "\n") {
+			return fmt.Errorf("the title must be a single line")
+		}
+		return rules.Validate(title)
+	}
+	return r.generateMessage(a, input, rules.instructions("pull request titles"), validate, func() string {
+		return rules.fallback(issue.Title, issue.Labels)
+	})
+}
+
+// generatePRBody generates a pull request description following the template
+func (r *Repository) generatePRBody(a *agent.Agent, input agent.PromptInput, conventions Conventions, issue *Issue) (string, error) {
+	template := conventions.PRTemplate
+	if template == "" {
+		return r.generateMessage(a, input, "", nil, nil)
+	}
+	validate := func(description string) error {
+		return validateTemplate(template, description)
+	}
+	return r.generateMessage(a, input, templateInstructions(template), validate, func() string {
+		return templateFallback(template, issue)
+	})
+}
+
+// generateMessage asks the agent for a message until validate accepts it,
+// telling it about the rules and the problems of the rejected message. After
+// DefaultMessageAttempts rejected messages the fallback is used. A nil
+// validate accepts the first message.
+func (r *Repository) generateMessage(a *agent.Agent, input agent.PromptInput, rules string, validate func(string) error, fallback func() string) (string, error) {
+	base := r.repositoryConfig().instructions()
+	defer a.SetInstructions(base)
+
+	var rejected error
+	for attempt := 1; attempt <= DefaultMessageAttempts; attempt++ {
+		instructions := []string{base, rules}
+		if rejected != nil {
+			instructions = append(instructions, fmt.Sprintf("Your previous answer was rejected:\n%v", rejected))
+		}
+		a.SetInstructions(joinInstructions(instructions...))
+		message, err := a.Generate("", input)
+		if err != nil {
+			return "", err
+		}
+		message = strings.TrimSpace(message)
+		if validate == nil {
+			return message, nil
+		}
+		if rejected = validate(message); rejected == nil {
+			return message, nil
+		}
+		r.Logger.Info("Generated message rejected", "attempt", attempt, "error", rejected.Error())
+	}
+	message := fallback()
+	r.Logger.Info("Using fallback message", "message", message)
+	return message, nil
+}
+
+func joinInstructions(instructions ...string) string {
+	var parts []string
+	for _, instruction := range instructions {
+		if instruction != "" {
+			parts = append(parts, instruction)
+		}
+	}
+	return strings.Join(parts, "\n\n")
+}
@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseCommitlintConfig(t *testing.T) {
+	rules, err := ParseCommitlintConfig(".commitlintrc.yaml", []byte(`
+extends: ["@commitlint/config-conventional"]
+rules:
+  type-enum: [2, always, [feat, fix, docs]]
+  header-max-length: [2, always, 72]
+  body-max-line-length: [0]
+`))
+	if err != nil {
+		t.Fatalf("ParseCommitlintConfig returned error: %v", err)
+	}
+	if !slices.Equal(rules.Types, []string{"feat", "fix", "docs"}) || rules.HeaderMaxLength != 72 || rules.BodyMaxLineLength != 0 {
+		t.Errorf("unexpected rules: %+v", rules)
+	}
+
+	rules, err = ParseCommitlintConfig("package.json", []byte(`{"name": "app", "commitlint": {"rules": {"scope-enum": [2, "always", ["api", "ui"]]}}}`))
+	if err != nil {
+		t.Fatalf("ParseCommitlintConfig returned error: %v", err)
+	}
+	if !slices.Equal(rules.Scopes, []string{"api", "ui"}) || rules.HeaderMaxLength != 100 || !strings.Contains(rules.Config, "scope-enum") {
+		t.Errorf("unexpected rules from package.json: %+v", rules)
+	}
+
+	if rules, err := ParseCommitlintConfig("package.json", []byte(`{"name": "app"}`)); err != nil || rules != nil {
+		t.Errorf("expected no rules without a commitlint key, got %+v, %v", rules, err)
+	}
+	rules, err = ParseCommitlintConfig("commitlint.config.js", []byte(`module.exports = {extends: ['@commitlint/config-conventional']}`))
+	if err != nil || !slices.Equal(rules.Types, conventionalTypes) {
+		t.Errorf("expected the conventional rules for a javascript config, got %+v, %v", rules, err)
+	}
+}
+
+func TestValidateCommitMessage(t *testing.T) {
+	rules, err := ParseCommitlintConfig(".commitlintrc.json", []byte(`{"rules": {"scope-enum": [2, "always", ["api"]]}}`))
+	if err != nil {
+		t.Fatalf("ParseCommitlintConfig returned error: %v", err)
+	}
+	for _, valid := range []string{
+		"feat: add the dry run mode",
+		"fix(api)!: reject empty titles\n\nThe title was optional before.",
+	} {
+		if err := rules.Validate(valid); err != nil {
+			t.Errorf("expected %q to be valid, got %v", valid, err)
+		}
+	}
+	for message, problem := range map[string]string{
+		"Add the dry run mode":             "not of the form",
+		"feature: add the dry run mode":    "must be one of",
+		"fix(ui): reject empty titles":     "scope",
+		"fix: Reject empty titles":         "lower case letter",
+		"fix: reject empty titles.":        "period",
+		"fix: reject\nempty titles":        "blank line",
+		"fix: " + strings.Repeat("a", 100): "header is 105 characters long",
+	} {
+		err := rules.Validate(message)
+		if err == nil || !strings.Contains(err.Error(), problem) {
+			t.Errorf("expected %q to be rejected for %q, got %v", message, problem, err)
+		}
+	}
+}
+
+func TestCommitFallback(t *testing.T) {
+	rules := &CommitRules{Types: conventionalTypes, HeaderMaxLength: 30}
+	for _, test := range []struct {
+		subject  string
+		labels   []string
+		expected string
+	}{
+		{"Fix the login page.", nil, "fix: fix the login page"},
+		{"Document the API", []string{"mule", "docs"}, "docs: document the API"},
+		{"feat: Add dark mode", nil, "feat: add dark mode"},
+		{"Support every configuration file format", nil, "fix: support every"},
+	} {
+		message := rules.fallback(test.subject, test.labels)
+		if message != test.expected {
+			t.Errorf("expected %q for %q, got %q", test.expected, test.subject, message)
+		}
+		if err := rules.Validate(message); err != nil {
+			t.Errorf("expected the fallback %q to be valid, got %v", message, err)
+		}
+	}
+}
+
+func TestValidateTemplate(t *testing.T) {
+	template := "## Summary\n\n<!-- what changed -->\n\n## Testing\n\n- [ ] tests added"
+	if err := validateTemplate(template, "## Summary\nAdds dry runs.\n\n## testing\nUnit tests."); err != nil {
+		t.Errorf("expected the description to follow the template, got %v", err)
+	}
+	err := validateTemplate(template, "## Summary\nAdds dry runs.")
+	if err == nil || !strings.Contains(err.Error(), "Testing") {
+		t.Errorf("expected the missing heading to be reported, got %v", err)
+	}
+	fallback := templateFallback(template, &Issue{ID: 7, Title: "Dry runs"})
+	if err := validateTemplate(template, fallback); err != nil || !strings.Contains(fallback, "#7") {
+		t.Errorf("expected the fallback to follow the template, got %q, %v", fallback, err)
+	}
+}
+
+func TestLoadConventions(t *testing.T) {
+	repo := NewRepository(t.TempDir())
+	conventions, err := repo.loadConventions()
+	if err != nil || conventions.Commit != nil || conventions.PRTemplate != "" {
+		t.Fatalf("expected no conventions, got %+v, %v", conventions, err)
+	}
+
+	for file, content := range map[string]string{
+		".github/pull_request_template.md": "## Summary\n",
+		"package.json":                     `{"name": "app"}`,
+		".commitlintrc.json":               `{"extends": ["@commitlint/config-conventional"]}`,
+	} {
+		path := filepath.Join(repo.Path, file)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("error creating directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("error writing file: %v", err)
+		}
+	}
+	conventions, err = repo.loadConventions()
+	if err != nil {
+		t.Fatalf("loadConventions returned error: %v", err)
+	}
+	if conventions.PRTemplate != "## Summary" || conventions.Commit == nil || conventions.Commit.Source != ".commitlintrc.json" {
+		t.Errorf("unexpected conventions: %+v", conventions)
+	}
+}
@@ -0,0 +1,232 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/mule-ai/mule/pkg/agent"
+)
+
+// a dry run works on the issues like a sync, but in scratch worktrees that
+// are thrown away afterwards. The diff and the generated messages are kept as
+// an artifact, nothing is committed, pushed or posted.
+
+const (
+	// dryRunsPath holds the dry run artifacts of every repository, relative
+	// to the home directory
+	dryRunsPath = ".config/mule/dry-runs"
+	// maxDryRuns is the number of artifacts kept per repository, older ones
+	// are removed
+	maxDryRuns = 20
+	// dryRunBranchPrefix names the branches of the scratch worktrees
+	dryRunBranchPrefix = "mule-dry-run/"
+)
+
+// DryRunArtifact is what a dry run generated for an issue
+type DryRunArtifact struct {
+	ID         string `json:"id"`
+	Issue      int    `json:"issue"`
+	IssueTitle string `json:"issueTitle"`
+	Workflow   string `json:"workflow,omitempty"`
+	// Branch is the issue branch the changes would have been pushed to
+	Branch string `json:"branch"`
+	// PullRequest and AddressedComments are set when review comments of an
+	// open pull request were worked on
+	PullRequest       int     `json:"pullRequest,omitempty"`
+	AddressedComments []int64 `json:"addressedComments,omitempty"`
+	Diff              string  `json:"diff,omitempty"`
+	CommitMessage     string  `json:"commitMessage,omitempty"`
+	// PRTitle and PRBody are set when a pull request would have been opened
+	PRTitle    string    `json:"prTitle,omitempty"`
+	PRBody     string    `json:"prBody,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	StartedAt  time.Time `json:"startedAt"`
+	FinishedAt time.Time `json:"finishedAt"`
+}
+
+func (r *Repository) dryRunConfigured() bool {
+	r.Mu.RLock()
+	defer r.Mu.RUnlock()
+	return r.DryRun
+}
+
+// dryRunIssue runs the workflow for the issue in a scratch worktree and
+// stores the result as an artifact, failed runs included
+func (r *Repository) dryRunIssue(agents map[int]*agent.Agent, workflow *agent.Workflow, issue *Issue) error {
+	started := time.Now()
+	artifact := &DryRunArtifact{
+		ID:         fmt.Sprintf("%s-%s", started.UTC().Format("20060102-150405"), uuid.New().String()[:8]),
+		Issue:      issue.Number,
+		IssueTitle: issue.Title,
+		Branch:     issueBranchName(issue.Title),
+		StartedAt:  started,
+	}
+	err := r.generateDryRun(agents, workflow, issue, artifact)
+	artifact.FinishedAt = time.Now()
+	if err != nil {
+		r.Logger.Error(err, "Error in dry run", "issue", issue.ID)
+		artifact.Error = err.Error()
+	}
+	if saveErr := r.saveDryRun(artifact); saveErr != nil {
+		r.Logger.Error(saveErr, "Error saving dry run", "issue", issue.ID)
+		if err == nil {
+			err = saveErr
+		}
+	}
+	return err
+}
+
+func (r *Repository) generateDryRun(agents map[int]*agent.Agent, workflow *agent.Workflow, issue *Issue, artifact *DryRunArtifact) error {
+	workflow, err := r.issueWorkflow(issue, workflow)
+	if err != nil {
+		return err
+	}
+	artifact.Workflow = workflow.Name()
+
+	worktree, cleanup, err := r.scratchWorktree(artifact.Branch, artifact.ID)
+	if err != nil {
+		return fmt.Errorf("error creating scratch worktree: %w", err)
+	}
+	defer cleanup()
+	worktree.dryRun = artifact
+	return worktree.generate(agents, workflow, issue)
+}
+
+// scratchWorktree checks out a throwaway branch where work on the issue
+// branch would continue from. The returned function removes the worktree and
+// its branch again.
+func (r *Repository) scratchWorktree(branchName, id string) (*Repository, func(), error) {
+	startPoint, _, err := r.issueStartPoint(branchName)
+	if err != nil {
+		return nil, nil, err
+	}
+	dir, err := os.MkdirTemp("", "mule-dry-run-")
+	if err != nil {
+		return nil, nil, fmt.Errorf("error creating scratch directory: %v", err)
+	}
+	path := filepath.Join(dir, "checkout")
+	scratchBranch := dryRunBranchPrefix + id
+
+	r.worktreeMu.Lock()
+	err = r.git("worktree", "add", "--no-track", "-b", scratchBranch, path, startPoint)
+	r.worktreeMu.Unlock()
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, nil, err
+	}
+
+	cleanup := func() {
+		r.worktreeMu.Lock()
+		defer r.worktreeMu.Unlock()
+		if err := r.removeWorktree(path); err != nil {
+			r.Logger.Error(err, "Error removing scratch worktree", "worktree", path)
+		}
+		if err := r.git("branch", "-D", scratchBranch); err != nil {
+			r.Logger.Error(err, "Error deleting scratch branch", "branch", scratchBranch)
+		}
+		if err := os.RemoveAll(dir); err != nil {
+			r.Logger.Error(err, "Error removing scratch directory", "directory", dir)
+		}
+	}
+	worktree, err := r.openWorktree(path, scratchBranch)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	return worktree, cleanup, nil
+}
+
+// recordDryRun stores the changes of the checkout and the commit message in
+// the artifact instead of committing them
+func (r *Repository) recordDryRun(commitMessage string) error {
+	if err := r.git("add", "-A"); err != nil {
+		return err
+	}
+	diff, err := r.gitOutput("diff", "--cached")
+	if err != nil {
+		return err
+	}
+	r.dryRun.Diff = diff
+	r.dryRun.CommitMessage = commitMessage
+	return nil
+}
+
+func (r *Repository) saveDryRun(artifact *DryRunArtifact) error {
+	dir, err := r.dataDir(dryRunsPath)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("error creating dry runs directory: %v", err)
+	}
+	data, err := json.MarshalIndent(artifact, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding dry run: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, artifact.ID+".json"), data, 0644); err != nil {
+		return fmt.Errorf("error writing dry run: %v", err)
+	}
+
+	// the names start with the time of the run, the oldest sort first
+	names, err := dryRunFiles(dir)
+	if err != nil {
+		return err
+	}
+	for len(names) > maxDryRuns {
+		if err := os.Remove(filepath.Join(dir, names[0])); err != nil {
+			return fmt.Errorf("error removing old dry run: %v", err)
+		}
+		names = names[1:]
+	}
+	return nil
+}
+
+// DryRuns returns the stored dry run artifacts of the repository, the latest
+// first
+func (r *Repository) DryRuns() ([]DryRunArtifact, error) {
+	dir, err := r.dataDir(dryRunsPath)
+	if err != nil {
+		return nil, err
+	}
+	names, err := dryRunFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	artifacts := []DryRunArtifact{}
+	for _, name := range slices.Backward(names) {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, fmt.Errorf("error reading dry run: %v", err)
+		}
+		var artifact DryRunArtifact
+		if err := json.Unmarshal(data, &artifact); err != nil {
+			return nil, fmt.Errorf("error decoding dry run %s: %v", name, err)
+		}
+		artifacts = append(artifacts, artifact)
+	}
+	return artifacts, nil
+}
+
+// dryRunFiles returns the names of the artifact files in dir, sorted
+func dryRunFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("error reading dry runs directory: %v", err)
+	}
+	var names []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".json") {
+			names = append(names, entry.Name())
+		}
+	}
+	return names, nil
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestScratchWorktree(t *testing.T) {
+	repo := newClone(t)
+	remoteDir := gitOutput(t, repo.Path, "remote", "get-url", "origin")
+	commitFile(t, remoteDir, "fix-a", "a.txt", "a")
+	if err := repo.Fetch(); err != nil {
+		t.Fatalf("Fetch returned error: %v", err)
+	}
+	remoteHead := gitOutput(t, remoteDir, "rev-parse", "fix-a")
+
+	worktree, cleanup, err := repo.scratchWorktree("fix-a", "test")
+	if err != nil {
+		t.Fatalf("scratchWorktree returned error: %v", err)
+	}
+	if worktree.State.CurrentBranch != dryRunBranchPrefix+"test" {
+		t.Errorf("expected the scratch branch in the worktree, got %q", worktree.State.CurrentBranch)
+	}
+	if head := gitOutput(t, worktree.Path, "rev-parse", "HEAD"); head != remoteHead {
+		t.Errorf("expected the scratch branch to start from the issue branch, got %s", head)
+	}
+
+	if err := os.WriteFile(filepath.Join(worktree.Path, "b.txt"), []byte("b\n"), 0644); err != nil {
+		t.Fatalf("error writing file: %v", err)
+	}
+	worktree.dryRun = &DryRunArtifact{}
+	if err := worktree.recordDryRun("add b"); err != nil {
+		t.Fatalf("recordDryRun returned error: %v", err)
+	}
+	if !strings.Contains(worktree.dryRun.Diff, "+++ b/b.txt") || worktree.dryRun.CommitMessage != "add b" {
+		t.Errorf("unexpected artifact: %+v", worktree.dryRun)
+	}
+
+	cleanup()
+	if _, err := os.Stat(worktree.Path); !os.IsNotExist(err) {
+		t.Errorf("expected the scratch worktree to be removed, got %v", err)
+	}
+	if exec.Command("git", "-C", repo.Path, "rev-parse", "--verify", dryRunBranchPrefix+"test").Run() == nil {
+		t.Errorf("expected the scratch branch to be deleted")
+	}
+	if head := gitOutput(t, remoteDir, "rev-parse", "fix-a"); head != remoteHead {
+		t.Errorf("expected the remote branch to be left alone, got %s", head)
+	}
+}
+
+func TestDryRuns(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	repo := NewRepository(t.TempDir())
+	for i := 0; i < maxDryRuns+2; i++ {
+		artifact := &DryRunArtifact{ID: fmt.Sprintf("20260101-0000%02d-abcd", i), Issue: i}
+		if err := repo.saveDryRun(artifact); err != nil {
+			t.Fatalf("saveDryRun returned error: %v", err)
+		}
+	}
+
+	dryRuns, err := repo.DryRuns()
+	if err != nil {
+		t.Fatalf("DryRuns returned error: %v", err)
+	}
+	if len(dryRuns) != maxDryRuns {
+		t.Fatalf("expected %d dry runs to be kept, got %d", maxDryRuns, len(dryRuns))
+	}
+	if first, last := dryRuns[0].Issue, dryRuns[len(dryRuns)-1].Issue; first != maxDryRuns+1 || last != 2 {
+		t.Errorf("expected the latest dry runs first, got issues %d to %d", first, last)
+	}
+}
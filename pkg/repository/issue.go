@@ -3,6 +3,7 @@ package repository
 import (
 	"fmt"
 	"log"
+	"strings"
 
 	"github.com/mule-ai/mule/pkg/remote/types"
 )
@@ -10,18 +11,22 @@ import (
 // all the functions to handle issues 
 
 type Issue struct {
-	ID           int            `json:"id"`
-	Number       int            `json:"number"`
-	Title        string         `json:"title"`
-	Body         string         `json:"body"`
-	State        string         `json:"state"`
-	HTMLURL      string         `json:"html_url"`
-	SourceURL    string         `json:"source_url"`
-	CreatedAt    string         `json:"created_at"`
-	UpdatedAt    string         `json:"updated_at"`
-	Comments     []*Comment     `json:"comments"`
-	PullRequests []*PullRequest `json:"pull_requests"`
-	Labels       []string       `json:"labels"`
+	ID                int            `json:"id"`
+	Number            int            `json:"number"`
+	Title             string         `json:"title"`
+	Body              string         `json:"body"`
+	State             string         `json:"state"`
+	HTMLURL           string         `json:"html_url"`
+	SourceURL         string         `json:"source_url"`
+	CreatedAt         string         `json:"created_at"`
+	UpdatedAt         string         `json:"updated_at"`
+	Comments          []*Comment     `json:"comments"`
+	PullRequests      []*PullRequest `json:"pull_requests"`
+	Labels            []string       `json:"labels"`
+	Author            string         `json:"author,omitempty"`
+	AuthorAssociation string         `json:"author_association,omitempty"`
+	// AuthorEmail is the address the author is credited with in commits
+	AuthorEmail string `json:"author_email,omitempty"`
 }
 
 func (i *Issue) addPullRequests(pullRequests map[int]*PullRequest) {
@@ -72,7 +77,7 @@ func (r *Repository) UpdateIssues() error {
 	}
 	issues, err := r.Remote.FetchIssues(r.RemotePath, types.IssueFilterOptions{
 		State: "open",
-		Label: "mule",
+		Label: r.labels().Trigger,
 	})
 	if err != nil {
 		log.Printf("Error fetching issues: %v, request: %v", err, r.RemotePath)
@@ -90,16 +95,42 @@ func (i *Issue) ToString() string {
 	return fmt.Sprintf("Issue #%d: %s\n%s", i.Number, i.Title, i.Body)
 }
 
+// RenderComments returns the discussion of the issue for a prompt, one
+// comment after the other with its author and time
+func (i *Issue) RenderComments() string {
+	var sb strings.Builder
+	for _, comment := range i.Comments {
+		author := comment.Author
+		if author == "" {
+			author = "unknown"
+		}
+		if sb.Len() > 0 {
+			sb.WriteString("\n")
+		}
+		fmt.Fprintf(&sb, "%s commented", author)
+		if comment.CreatedAt != "" {
+			fmt.Fprintf(&sb, " at %s", comment.CreatedAt)
+		}
+		fmt.Fprintf(&sb, ":\n%s\n", strings.TrimSpace(comment.Body))
+	}
+	return sb.String()
+}
+
 func ghIssueToIssue(issue types.Issue) *Issue {
 	return &Issue{
-		ID:        issue.Number,
-		Number:    issue.Number,
-		Title:     issue.Title,
-		Body:      issue.Body,
-		CreatedAt: issue.CreatedAt,
-		UpdatedAt: issue.UpdatedAt,
-		SourceURL: issue.SourceURL,
-		HTMLURL:   issue.HTMLURL,
-		State:     issue.State,
+		ID:                issue.Number,
+		Number:            issue.Number,
+		Title:             issue.Title,
+		Body:              issue.Body,
+		CreatedAt:         issue.CreatedAt,
+		UpdatedAt:         issue.UpdatedAt,
+		SourceURL:         issue.SourceURL,
+		HTMLURL:           issue.HTMLURL,
+		State:             issue.State,
+		Labels:            issue.Labels,
+		Author:            issue.Author,
+		Comments:          ghCommentsToComments(issue.Comments),
+		AuthorAssociation: issue.AuthorAssociation,
+		AuthorEmail:       issue.AuthorEmail,
 	}
 }
@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/mule-ai/mule/pkg/remote/types"
+)
+
+func TestRenderComments(t *testing.T) {
+	issue := ghIssueToIssue(types.Issue{
+		Number: 1,
+		Comments: []*types.Comment{
+			{Body: "Which endpoint is meant?\n", Author: "alice", CreatedAt: "2024-01-02T10:00:00Z"},
+			{Body: "The sync one."},
+		},
+	})
+
+	want := "alice commented at 2024-01-02T10:00:00Z:\nWhich endpoint is meant?\n" +
+		"\nunknown commented:\nThe sync one.\n"
+	if got := issue.RenderComments(); got != want {
+		t.Errorf("expected\n%q\ngot\n%q", want, got)
+	}
+
+	if got := (&Issue{}).RenderComments(); got != "" {
+		t.Errorf("expected nothing for an issue without comments, got %q", got)
+	}
+}
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"slices"
+	"strings"
+)
+
+// decides which labels mule reacts to and which it sets
+
+// DefaultTriggerLabel marks the issues mule works on when a repository does
+// not set its own trigger label
+const DefaultTriggerLabel = "mule"
+
+// LabelSettings let several mule instances share a repository, each with its
+// own labels
+type LabelSettings struct {
+	// Trigger marks the issues to work on
+	Trigger string `json:"trigger,omitempty"`
+	// PullRequest is added to the pull requests mule opens, only pull
+	// requests carrying it are tracked. Empty tracks every pull request.
+	PullRequest string `json:"pullRequest,omitempty"`
+	// InProgress is set on an issue while it is worked on, empty disables it
+	InProgress string `json:"inProgress,omitempty"`
+}
+
+// Normalize trims the labels
+func (l LabelSettings) Normalize() LabelSettings {
+	return LabelSettings{
+		Trigger:     strings.TrimSpace(l.Trigger),
+		PullRequest: strings.TrimSpace(l.PullRequest),
+		InProgress:  strings.TrimSpace(l.InProgress),
+	}
+}
+
+func (r *Repository) labels() LabelSettings {
+	r.Mu.RLock()
+	defer r.Mu.RUnlock()
+	labels := r.Labels
+	if labels.Trigger == "" {
+		labels.Trigger = DefaultTriggerLabel
+	}
+	return labels
+}
+
+// MuleLabel reports whether mule sets the label itself, as opposed to the
+// trigger label that people set
+func (r *Repository) MuleLabel(name string) bool {
+	labels := r.labels()
+	return name != "" && (name == labels.InProgress || name == labels.PullRequest)
+}
+
+// pullRequestLabels are added to a new pull request
+func (r *Repository) pullRequestLabels() []string {
+	if label := r.labels().PullRequest; label != "" {
+		return []string{label}
+	}
+	return nil
+}
+
+// markInProgress sets the in progress label on the issue, the returned
+// function removes it again
+func (r *Repository) markInProgress(issue *Issue) func() {
+	label := r.labels().InProgress
+	if label == "" {
+		return func() {}
+	}
+	if !slices.Contains(issue.Labels, label) {
+		err := r.Remote.AddLabelToIssue(issue.Number, label)
+		if err != nil {
+			r.Logger.Error(err, "Error adding in progress label", "issue", issue.Number)
+		}
+	}
+	return func() {
+		err := r.Remote.RemoveLabelFromIssue(issue.Number, label)
+		if err != nil {
+			r.Logger.Error(err, "Error removing in progress label", "issue", issue.Number)
+		}
+	}
+}
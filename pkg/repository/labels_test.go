@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/go-logr/logr"
+
+	"github.com/mule-ai/mule/pkg/remote/local"
+	"github.com/mule-ai/mule/pkg/remote/types"
+)
+
+func TestUpdateIssuesUsesTriggerLabel(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	provider := &local.Provider{
+		Issues: map[int]*types.Issue{
+			1: {Number: 1, State: "open", Labels: []string{"mule"}},
+			2: {Number: 2, State: "open", Labels: []string{"mule-frontend"}},
+		},
+		PullRequests: make(map[int]*types.PullRequest),
+	}
+	repo := NewRepository(t.TempDir())
+	repo.Logger = logr.Discard()
+	repo.Remote = provider
+	repo.RemotePath = "local"
+
+	if err := repo.UpdateIssues(); err != nil {
+		t.Fatalf("UpdateIssues returned error: %v", err)
+	}
+	if _, ok := repo.Issues[1]; !ok || len(repo.Issues) != 1 {
+		t.Errorf("expected the default trigger label to select issue 1, got %v", repo.Issues)
+	}
+
+	repo.Labels = LabelSettings{Trigger: "mule-frontend"}
+	if err := repo.UpdateIssues(); err != nil {
+		t.Fatalf("UpdateIssues returned error: %v", err)
+	}
+	if _, ok := repo.Issues[2]; !ok || len(repo.Issues) != 1 {
+		t.Errorf("expected the configured trigger label to select issue 2, got %v", repo.Issues)
+	}
+}
+
+func TestMarkInProgress(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	provider := &local.Provider{
+		Issues:       map[int]*types.Issue{1: {Number: 1, Labels: []string{"mule"}}},
+		PullRequests: make(map[int]*types.PullRequest),
+	}
+	repo := NewRepository(t.TempDir())
+	repo.Logger = logr.Discard()
+	repo.Remote = provider
+	issue := ghIssueToIssue(*provider.Issues[1])
+
+	// disabled without an in progress label
+	repo.markInProgress(issue)()
+	if labels := provider.Issues[1].Labels; len(labels) != 1 {
+		t.Errorf("expected the labels to be left alone, got %v", labels)
+	}
+
+	repo.Labels = LabelSettings{InProgress: "mule:in-progress"}
+	done := repo.markInProgress(issue)
+	if !slices.Contains(provider.Issues[1].Labels, "mule:in-progress") {
+		t.Errorf("expected the in progress label while working, got %v", provider.Issues[1].Labels)
+	}
+	done()
+	if labels := provider.Issues[1].Labels; len(labels) != 1 || labels[0] != "mule" {
+		t.Errorf("expected the in progress label to be removed, got %v", labels)
+	}
+}
@@ -13,6 +13,8 @@ type PullRequest struct {
 	Number          int        `json:"number"`
 	Title           string     `json:"title"`
 	Body            string     `json:"body"`
+	State           string     `json:"state"`
+	Branch          string     `json:"branch"`
 	CreatedAt       string     `json:"created_at"`
 	UpdatedAt       string     `json:"updated_at"`
 	Labels          []string   `json:"labels"`
@@ -23,14 +25,27 @@ type PullRequest struct {
 }
 
 type Comment struct {
-	ID           int64           `json:"id"`
-	Body         string          `json:"body"`
-	DiffHunk     string          `json:"diff_hunk,omitempty"`
-	HTMLURL      string          `json:"html_url"`
-	URL          string          `json:"url"`
-	UserID       int64           `json:"user_id"`
-	Acknowledged bool            `json:"acknowledged"`
-	Reactions    types.Reactions `json:"reactions"`
+	ID                int64           `json:"id"`
+	Body              string          `json:"body"`
+	DiffHunk          string          `json:"diff_hunk,omitempty"`
+	Path              string          `json:"path,omitempty"`
+	Line              int             `json:"line,omitempty"`
+	ThreadID          string          `json:"thread_id,omitempty"`
+	Kind              string          `json:"kind,omitempty"`
+	ReviewState       string          `json:"review_state,omitempty"`
+	HTMLURL           string          `json:"html_url"`
+	URL               string          `json:"url"`
+	UserID            int64           `json:"user_id"`
+	Author            string          `json:"author,omitempty"`
+	AuthorAssociation string          `json:"author_association,omitempty"`
+	CreatedAt         string          `json:"created_at,omitempty"`
+	Acknowledged      bool            `json:"acknowledged"`
+	Reactions         types.Reactions `json:"reactions"`
+}
+
+// Open reports whether the pull request is neither closed nor merged
+func (p *PullRequest) Open() bool {
+	return p.State != "closed" && p.State != "merged"
 }
 
 func (p *PullRequest) HasUnresolvedComments() bool {
@@ -63,24 +78,29 @@ func (r *Repository) UpdatePullRequests() error {
 	if r.RemotePath == "" {
 		return fmt.Errorf("repository remote path is not set")
 	}
-	pullRequests, err := r.Remote.FetchPullRequests(r.RemotePath, "dev-team")
+	pullRequests, err := r.Remote.FetchPullRequests(r.RemotePath, r.labels().PullRequest)
 	if err != nil {
 		log.Printf("Error fetching pull requests: %v, request: %v", err, r.RemotePath)
 		return err
 	}
 	// reset tracked pull requests
+	account := r.muleAccount()
 	r.PullRequests = make(map[int]*PullRequest)
 	for _, pullRequest := range pullRequests {
-		r.PullRequests[pullRequest.Number] = ghPullRequestToPullRequest(pullRequest)
+		r.PullRequests[pullRequest.Number] = ghPullRequestToPullRequest(pullRequest, account)
 	}
 	return nil
 }
 
-func ghPullRequestToPullRequest(pullRequest types.PullRequest) *PullRequest {
-	return &PullRequest{
+// ghPullRequestToPullRequest converts a pull request, account is the login
+// of mule whose replies acknowledge review comments
+func ghPullRequestToPullRequest(pullRequest types.PullRequest, account string) *PullRequest {
+	pr := &PullRequest{
 		Number:          pullRequest.Number,
 		Title:           pullRequest.Title,
 		Body:            pullRequest.Body,
+		State:           pullRequest.State,
+		Branch:          pullRequest.Branch,
 		CreatedAt:       pullRequest.CreatedAt,
 		UpdatedAt:       pullRequest.UpdatedAt,
 		Labels:          pullRequest.Labels,
@@ -89,19 +109,31 @@ func ghPullRequestToPullRequest(pullRequest types.PullRequest) *PullRequest {
 		Diff:            pullRequest.Diff,
 		Comments:        ghCommentsToComments(pullRequest.Comments),
 	}
+	replied := repliedComments(pullRequest.Comments, account)
+	for i, comment := range pullRequest.Comments {
+		pr.Comments[i].Acknowledged = acknowledged(comment, replied, account)
+	}
+	return pr
 }
 
 func ghCommentsToComments(comments []*types.Comment) []*Comment {
 	pullRequestComments := make([]*Comment, 0, len(comments))
 	for _, comment := range comments {
 		pullRequestComments = append(pullRequestComments, &Comment{
-			ID:           comment.ID,
-			Body:         comment.Body,
-			DiffHunk:     comment.DiffHunk,
-			HTMLURL:      comment.HTMLURL,
-			URL:          comment.URL,
-			UserID:       comment.UserID,
-			Acknowledged: comment.Reactions.PlusOne > 0,
+			ID:                comment.ID,
+			Body:              comment.Body,
+			DiffHunk:          comment.DiffHunk,
+			Path:              comment.Path,
+			Line:              comment.Line,
+			ThreadID:          comment.ThreadID,
+			Kind:              comment.Kind,
+			ReviewState:       comment.ReviewState,
+			HTMLURL:           comment.HTMLURL,
+			URL:               comment.URL,
+			UserID:            comment.UserID,
+			Author:            comment.Author,
+			AuthorAssociation: comment.AuthorAssociation,
+			CreatedAt:         comment.CreatedAt,
 		})
 	}
 	return pullRequestComments
@@ -0,0 +1,337 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+
+	"github.com/mule-ai/mule/pkg/agent"
+	"github.com/mule-ai/mule/pkg/validation"
+)
+
+// keeps the branches of open pull requests up to date with the base branch
+
+type BranchUpdate string
+
+/*
+Strategies:
+rebase: the branch is rebased onto the base branch and force pushed
+merge: the base branch is merged into the branch
+none: branches are left behind the base branch
+*/
+const (
+	BranchUpdateRebase BranchUpdate = "rebase"
+	BranchUpdateMerge  BranchUpdate = "merge"
+	BranchUpdateNone   BranchUpdate = "none"
+)
+
+// DefaultBranchUpdate is used when a repository does not set a strategy.
+// Rebasing force pushes, so it has to be chosen.
+const DefaultBranchUpdate = BranchUpdateNone
+
+// DefaultConflictWorkflow resolves conflicts when a repository does not name
+// its own conflict workflow. Without either the workflow of the sync is used.
+const DefaultConflictWorkflow = "Conflict Resolution"
+
+// maxConflictRounds limits how often a rebase may stop on conflicts, every
+// round is a workflow run
+const maxConflictRounds = 5
+
+// conflictMarker starts the part of a conflict from the branch being updated
+const conflictMarker = "<<<<<<<"
+
+// ParseBranchUpdate checks a strategy name, an empty name gives the default
+func ParseBranchUpdate(name string) (BranchUpdate, error) {
+	strategy := BranchUpdate(strings.TrimSpace(name))
+	switch strategy {
+	case "":
+		return DefaultBranchUpdate, nil
+	case BranchUpdateRebase, BranchUpdateMerge, BranchUpdateNone:
+		return strategy, nil
+	}
+	return "", fmt.Errorf("unknown branch update strategy: %s", name)
+}
+
+func (r *Repository) branchUpdate() BranchUpdate {
+	r.Mu.RLock()
+	defer r.Mu.RUnlock()
+	if r.BranchUpdate == "" {
+		return DefaultBranchUpdate
+	}
+	return r.BranchUpdate
+}
+
+// conflictWorkflow returns the workflow resolving conflicts, fallback when
+// the repository has none
+func (r *Repository) conflictWorkflow(fallback *agent.Workflow) *agent.Workflow {
+	r.Mu.RLock()
+	defer r.Mu.RUnlock()
+	name := r.ConflictWorkflow
+	if name == "" {
+		name = DefaultConflictWorkflow
+	}
+	if workflow, ok := r.Workflows[name]; ok {
+		return workflow
+	}
+	return fallback
+}
+
+// updateBranches brings the branches of the open pull requests of the
+// selected issues up to date with the base branch. A branch that can't be
+// updated is logged and left as it is.
+func (r *Repository) updateBranches(agents map[int]*agent.Agent, workflow *agent.Workflow, selected func(*Issue) bool) {
+	strategy := r.branchUpdate()
+	if strategy == BranchUpdateNone {
+		return
+	}
+	issues, _ := r.GetIssues()
+	slices.SortFunc(issues, func(a, b *Issue) int { return a.Number - b.Number })
+	for _, issue := range issues {
+		if selected != nil && !selected(issue) {
+			continue
+		}
+		for _, pr := range issue.PullRequests {
+			if !pr.Open() || pr.Branch == "" {
+				continue
+			}
+			behind, err := r.branchBehind(pr.Branch)
+			if err != nil {
+				r.Logger.Error(err, "Error comparing branch with base branch", "branch", pr.Branch)
+				continue
+			}
+			if !behind {
+				continue
+			}
+			issueWorkflow, err := r.issueWorkflow(issue, workflow)
+			if err != nil {
+				r.Logger.Error(err, "Error selecting workflow", "branch", pr.Branch)
+				continue
+			}
+			r.Logger.Info("Updating branch", "branch", pr.Branch, "strategy", strategy)
+			err = r.updateBranch(r.cloneAgents(agents), issueWorkflow, issue, pr, strategy)
+			if err != nil {
+				r.Logger.Error(err, "Error updating branch", "branch", pr.Branch)
+			}
+		}
+	}
+}
+
+// branchBehind reports whether the pushed branch lacks commits of the base
+// branch
+func (r *Repository) branchBehind(branch string) (bool, error) {
+	base := "origin/" + r.baseBranch()
+	cmd := exec.Command("git", "-C", r.Path, "merge-base", "--is-ancestor", base, "origin/"+branch)
+	output, err := cmd.CombinedOutput()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		return true, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("error comparing %s with %s: %v: %s", branch, base, err, strings.TrimSpace(string(output)))
+	}
+	return false, nil
+}
+
+// updateBranch rebases or merges the branch of the pull request in its
+// worktree. Conflicts are resolved by the conflict workflow and validated
+// again before the branch is pushed. The push is refused when the branch
+// moved on the remote in the meantime.
+func (r *Repository) updateBranch(agents map[int]*agent.Agent, workflow *agent.Workflow, issue *Issue, pr *PullRequest, strategy BranchUpdate) error {
+	repo, err := openRepository(r.Path)
+	if err != nil {
+		return err
+	}
+	remoteBranch := plumbing.NewRemoteReferenceName("origin", pr.Branch)
+	pushed, err := repo.Reference(remoteBranch, true)
+	if err != nil {
+		return fmt.Errorf("error getting %s: %v", remoteBranch.Short(), err)
+	}
+
+	worktree, err := r.issueWorktree(pr.Branch)
+	if err != nil {
+		return fmt.Errorf("error creating issue worktree: %w", err)
+	}
+	// start from what was pushed, the local branch may lag behind
+	if err := worktree.git("reset", "--hard", remoteBranch.Short()); err != nil {
+		return err
+	}
+
+	base := "origin/" + r.baseBranch()
+	if strategy == BranchUpdateMerge {
+		err = worktree.gitCommit("merge", "--no-edit", base)
+	} else {
+		err = worktree.gitCommit("rebase", base)
+	}
+	if err != nil {
+		conflictWorkflow := r.conflictWorkflow(workflow)
+		err = worktree.resolveConflicts(agents, conflictWorkflow, issue, pr, strategy, err)
+		if err == nil {
+			err = worktree.validate(workflow.ValidationFunctions)
+		}
+		if err != nil {
+			worktree.abortBranchUpdate(strategy, remoteBranch.Short())
+			return err
+		}
+	}
+
+	return worktree.pushWithLease(pr.Branch, pushed.Hash())
+}
+
+// resolveConflicts runs the conflict workflow until the rebase or merge is
+// complete. updateErr is the error the rebase or merge stopped with.
+func (r *Repository) resolveConflicts(agents map[int]*agent.Agent, workflow *agent.Workflow, issue *Issue, pr *PullRequest, strategy BranchUpdate, updateErr error) error {
+	for round := 0; ; round++ {
+		files, err := r.conflictedFiles()
+		if err != nil {
+			return err
+		}
+		if len(files) == 0 {
+			return updateErr
+		}
+		if round == maxConflictRounds {
+			return fmt.Errorf("branch still conflicts after %d rounds of resolving conflicts", round)
+		}
+
+		conflicts, err := r.renderConflicts(files)
+		if err != nil {
+			return err
+		}
+		r.Logger.Info("Resolving conflicts", "files", files)
+		_, err = agent.ExecuteWorkflow(workflow.Steps, agents, conflictPromptInput(issue, pr, conflicts), r.Path, r.Logger, workflow.ValidationFunctions)
+		if err != nil {
+			return fmt.Errorf("error resolving conflicts: %w", err)
+		}
+		for _, file := range files {
+			content, err := os.ReadFile(filepath.Join(r.Path, file))
+			if err == nil && strings.Contains(string(content), conflictMarker) {
+				return fmt.Errorf("conflict markers left in %s", file)
+			}
+		}
+
+		if err := r.git("add", "-A"); err != nil {
+			return err
+		}
+		if strategy == BranchUpdateMerge {
+			updateErr = r.gitCommit("commit", "--no-edit")
+		} else {
+			updateErr = r.gitCommit("-c", "core.editor=true", "rebase", "--continue")
+		}
+		if updateErr == nil {
+			return nil
+		}
+	}
+}
+
+// conflictPromptInput asks to resolve the conflicts. Templates without a
+// conflicts section get them as a pull request comment.
+func conflictPromptInput(issue *Issue, pr *PullRequest, conflicts string) agent.PromptInput {
+	return agent.PromptInput{
+		IssueTitle:    issue.Title,
+		IssueBody:     issue.Body,
+		IssueComments: issue.RenderComments(),
+		Diff:          pr.Diff,
+		PRComment: "Updating the pull request with the latest base branch ran into conflicts. " +
+			"Resolve every conflict marker in the following files, keeping the intent of both sides:\n\n" + conflicts,
+		IsPRComment: true,
+		Conflicts:   conflicts,
+	}
+}
+
+// conflictedFiles returns the files with unresolved conflicts
+func (r *Repository) conflictedFiles() ([]string, error) {
+	output, err := exec.Command("git", "-C", r.Path, "diff", "--name-only", "--diff-filter=U").CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("error listing conflicts: %v: %s", err, strings.TrimSpace(string(output)))
+	}
+	return strings.Fields(string(output)), nil
+}
+
+// renderConflicts lists the conflicting parts of the files with their line
+// numbers
+func (r *Repository) renderConflicts(files []string) (string, error) {
+	var rendered strings.Builder
+	for _, file := range files {
+		content, err := os.ReadFile(filepath.Join(r.Path, file))
+		if err != nil {
+			return "", fmt.Errorf("error reading conflicted file: %v", err)
+		}
+		fmt.Fprintf(&rendered, "File %s:\n", file)
+		for _, block := range conflictBlocks(string(content)) {
+			fmt.Fprintf(&rendered, "%s\n", block)
+		}
+	}
+	return rendered.String(), nil
+}
+
+// conflictBlocks returns every conflict of a file from its start to its end
+// marker, prefixed with the line it starts at
+func conflictBlocks(content string) []string {
+	var blocks []string
+	var block []string
+	start := 0
+	for i, line := range strings.Split(content, "\n") {
+		switch {
+		case strings.HasPrefix(line, conflictMarker):
+			block = []string{line}
+			start = i + 1
+		case block != nil:
+			block = append(block, line)
+			if strings.HasPrefix(line, ">>>>>>>") {
+				blocks = append(blocks, fmt.Sprintf("line %d:\n%s", start, strings.Join(block, "\n")))
+				block = nil
+			}
+		}
+	}
+	return blocks
+}
+
+// validate runs the validation functions of the workflow on the checkout
+func (r *Repository) validate(names []string) error {
+	var validations []validation.ValidationFunc
+	for _, name := range names {
+		v, ok := validation.Get(name)
+		if !ok {
+			r.Logger.Error(fmt.Errorf("validation function %s not found", name), "Validation function not found")
+			continue
+		}
+		validations = append(validations, v)
+	}
+	output, err := validation.Run(&validation.ValidationInput{
+		Validations: validations,
+		Logger:      r.Logger.WithName("validation"),
+		Path:        r.Path,
+	})
+	if err != nil {
+		return fmt.Errorf("validation after resolving conflicts failed: %v: %s", err, output)
+	}
+	return nil
+}
+
+// abortBranchUpdate leaves the worktree at the pushed branch again
+func (r *Repository) abortBranchUpdate(strategy BranchUpdate, pushed string) {
+	abort := []string{"rebase", "--abort"}
+	if strategy == BranchUpdateMerge {
+		abort = []string{"merge", "--abort"}
+	}
+	// fails when the update already completed, the reset covers that
+	_ = r.git(abort...)
+	if err := r.git("reset", "--hard", pushed); err != nil {
+		r.Logger.Error(err, "Error resetting worktree")
+	}
+}
+
+// pushWithLease force pushes the branch unless the remote branch moved away
+// from expected
+func (r *Repository) pushWithLease(branch string, expected plumbing.Hash) error {
+	return r.push(&git.ForceWithLease{
+		RefName: plumbing.NewBranchReferenceName(branch),
+		Hash:    expected,
+	})
+}
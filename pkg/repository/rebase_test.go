@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mule-ai/mule/pkg/agent"
+)
+
+func gitOutput(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	output, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v failed: %v: %s", args, err, output)
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// commitFile commits a file on a branch of the remote, creating the branch
+// from main when it doesn't exist
+func commitFile(t *testing.T, remoteDir, branch, file, content string) {
+	t.Helper()
+	if branch != "main" {
+		if exec.Command("git", "-C", remoteDir, "rev-parse", "--verify", branch).Run() != nil {
+			runGit(t, remoteDir, "branch", branch, "main")
+		}
+	}
+	runGit(t, remoteDir, "checkout", branch)
+	if err := os.WriteFile(filepath.Join(remoteDir, file), []byte(content), 0644); err != nil {
+		t.Fatalf("error writing file: %v", err)
+	}
+	runGit(t, remoteDir, "add", file)
+	runGit(t, remoteDir, "commit", "-m", "change "+file)
+	runGit(t, remoteDir, "checkout", "main")
+}
+
+func TestUpdateBranches(t *testing.T) {
+	repo := newClone(t)
+	remoteDir := gitOutput(t, repo.Path, "remote", "get-url", "origin")
+
+	commitFile(t, remoteDir, "fix-a", "a.txt", "a")
+	commitFile(t, remoteDir, "fix-b", "shared.txt", "from the branch")
+	commitFile(t, remoteDir, "main", "shared.txt", "from main")
+	if err := repo.Fetch(); err != nil {
+		t.Fatalf("Fetch returned error: %v", err)
+	}
+	conflicting := gitOutput(t, remoteDir, "rev-parse", "fix-b")
+
+	repo.Issues = map[int]*Issue{
+		1: {Number: 1, PullRequests: []*PullRequest{{Number: 3, Branch: "fix-a", State: "open"}}},
+		2: {Number: 2, PullRequests: []*PullRequest{{Number: 4, Branch: "fix-b", State: "open"}}},
+	}
+	for _, branch := range []string{"fix-a", "fix-b"} {
+		if behind, err := repo.branchBehind(branch); err != nil || !behind {
+			t.Fatalf("expected %s to be behind main, got %v, %v", branch, behind, err)
+		}
+	}
+
+	// branches are only updated once a strategy is chosen
+	repo.updateBranches(nil, &agent.Workflow{}, nil)
+	if behind, err := repo.branchBehind("fix-a"); err != nil || !behind {
+		t.Fatalf("expected fix-a to be left behind by default, got %v, %v", behind, err)
+	}
+
+	// without steps the conflict workflow fails, the branch must be left alone
+	repo.BranchUpdate = BranchUpdateRebase
+	repo.Commits = CommitSettings{CommitterName: "CI", CommitterEmail: "ci@example.com"}
+	repo.updateBranches(nil, &agent.Workflow{}, nil)
+
+	runGit(t, remoteDir, "merge-base", "--is-ancestor", "main", "fix-a")
+	if subjects := gitOutput(t, remoteDir, "log", "--format=%s", "fix-a"); subjects != "change a.txt\nchange shared.txt\ninitial commit" {
+		t.Errorf("expected fix-a to be rebased onto main, got:\n%s", subjects)
+	}
+	if committer := gitOutput(t, remoteDir, "log", "-1", "--format=%cn <%ce>", "fix-a"); committer != "CI <ci@example.com>" {
+		t.Errorf("expected the rebased commit to get the configured committer, got %q", committer)
+	}
+	if head := gitOutput(t, remoteDir, "rev-parse", "fix-b"); head != conflicting {
+		t.Errorf("expected the conflicting branch to be left alone, got %s", head)
+	}
+
+	if err := repo.Fetch(); err != nil {
+		t.Fatalf("Fetch returned error: %v", err)
+	}
+	if behind, err := repo.branchBehind("fix-a"); err != nil || behind {
+		t.Errorf("expected fix-a to be up to date, got %v, %v", behind, err)
+	}
+}
+
+func TestConflictBlocks(t *testing.T) {
+	content := "package main\n\n<<<<<<< HEAD\nconst a = 1\n=======\nconst a = 2\n>>>>>>> fix\n\nfunc main() {}\n"
+	blocks := conflictBlocks(content)
+	if len(blocks) != 1 {
+		t.Fatalf("expected one conflict, got %v", blocks)
+	}
+	if blocks[0] != "line 3:\n<<<<<<< HEAD\nconst a = 1\n=======\nconst a = 2\n>>>>>>> fix" {
+		t.Errorf("unexpected conflict: %q", blocks[0])
+	}
+}
+
+func TestParseBranchUpdate(t *testing.T) {
+	if strategy, err := ParseBranchUpdate(""); err != nil || strategy != DefaultBranchUpdate {
+		t.Errorf("expected the default strategy, got %q, %v", strategy, err)
+	}
+	if strategy, err := ParseBranchUpdate("merge"); err != nil || strategy != BranchUpdateMerge {
+		t.Errorf("expected the merge strategy, got %q, %v", strategy, err)
+	}
+	if _, err := ParseBranchUpdate("squash"); err == nil {
+		t.Errorf("expected an error for an unknown strategy")
+	}
+}
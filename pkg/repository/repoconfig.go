@@ -0,0 +1,276 @@
+package repository
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"gopkg.in/yaml.v3"
+
+	"github.com/mule-ai/mule/pkg/agent"
+	"github.com/mule-ai/mule/pkg/remote/types"
+	"github.com/mule-ai/mule/pkg/validation"
+)
+
+// a repository can declare how mule works on it in a file at its root, which
+// is merged over the global settings on every sync
+
+// RepoConfigFiles are the names the repository configuration is looked for
+// under, the first one found is used
+var RepoConfigFiles = []string{".mule.yaml", ".mule.yml", ".mule.json"}
+
+// configMarker tags the comments reporting an invalid repository
+// configuration with a digest of the errors, so each is reported once
+const configMarker = "<!-- mule:config %s -->"
+
+// RepoConfig is the repository configuration file. Every field is optional,
+// unset fields keep the global settings.
+type RepoConfig struct {
+	// BaseBranch replaces the base branch of the repository
+	BaseBranch string `json:"baseBranch,omitempty" yaml:"baseBranch,omitempty"`
+	// Workflow names the configured workflow issues are worked on with
+	Workflow   string               `json:"workflow,omitempty" yaml:"workflow,omitempty"`
+	Validation RepoValidationConfig `json:"validation,omitempty" yaml:"validation,omitempty"`
+	// ExcludePaths are paths the agents may not change, as slash separated
+	// glob patterns relative to the repository root. A pattern matching a
+	// directory covers everything below it.
+	ExcludePaths []string `json:"excludePaths,omitempty" yaml:"excludePaths,omitempty"`
+	// PromptContext is put in front of the prompt of every agent
+	PromptContext string `json:"promptContext,omitempty" yaml:"promptContext,omitempty"`
+}
+
+// RepoValidationConfig changes what validates the generated changes
+type RepoValidationConfig struct {
+	// Functions replace the validation functions of the workflow
+	Functions []string `json:"functions,omitempty" yaml:"functions,omitempty"`
+	// Commands run with sh in the checkout after the functions, a command
+	// exiting with an error fails the validation
+	Commands []string `json:"commands,omitempty" yaml:"commands,omitempty"`
+}
+
+// ParseRepoConfig decodes a configuration file, its format is taken from the
+// name. Unknown fields are an error.
+func ParseRepoConfig(name string, data []byte) (RepoConfig, error) {
+	var config RepoConfig
+	if filepath.Ext(name) == ".json" {
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&config); err != nil {
+			return config, fmt.Errorf("error decoding %s: %v", name, err)
+		}
+		return config, nil
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	// an empty file is a valid configuration without settings
+	if err := decoder.Decode(&config); err != nil && !errors.Is(err, io.EOF) {
+		return config, fmt.Errorf("error decoding %s: %v", name, err)
+	}
+	return config, nil
+}
+
+// Validate checks the values of the configuration, workflows are the
+// configured workflows by name. Every problem found is returned.
+func (c RepoConfig) Validate(workflows map[string]*agent.Workflow) error {
+	var errs []error
+	if c.BaseBranch != "" {
+		if err := plumbing.NewBranchReferenceName(c.BaseBranch).Validate(); err != nil || strings.TrimSpace(c.BaseBranch) != c.BaseBranch {
+			errs = append(errs, fmt.Errorf("baseBranch: invalid branch name %q", c.BaseBranch))
+		}
+	}
+	if c.Workflow != "" {
+		if _, ok := workflows[c.Workflow]; !ok {
+			errs = append(errs, fmt.Errorf("workflow: unknown workflow %q", c.Workflow))
+		}
+	}
+	for _, name := range c.Validation.Functions {
+		if _, ok := validation.Get(name); !ok || strings.HasPrefix(name, validation.CommandPrefix) {
+			errs = append(errs, fmt.Errorf("validation.functions: unknown validation function %q", name))
+		}
+	}
+	for _, command := range c.Validation.Commands {
+		if strings.TrimSpace(command) == "" {
+			errs = append(errs, fmt.Errorf("validation.commands: empty command"))
+		}
+	}
+	for _, pattern := range c.ExcludePaths {
+		if err := checkExcludePattern(pattern); err != nil {
+			errs = append(errs, fmt.Errorf("excludePaths: %v", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func checkExcludePattern(pattern string) error {
+	if pattern == "" {
+		return fmt.Errorf("empty pattern")
+	}
+	if strings.HasPrefix(pattern, "/") || slices.Contains(strings.Split(pattern, "/"), "..") {
+		return fmt.Errorf("pattern %q must be relative to the repository root", pattern)
+	}
+	if _, err := path.Match(pattern, ""); err != nil {
+		return fmt.Errorf("invalid pattern %q: %v", pattern, err)
+	}
+	return nil
+}
+
+// loadRepoConfig reads the configuration file of the fetched base branch of
+// the repository settings, so it doesn't depend on the branch checked out. A
+// missing file gives an empty configuration, an invalid one an error.
+func (r *Repository) loadRepoConfig() (RepoConfig, error) {
+	revision := "origin/" + r.configuredBaseBranch()
+	if _, err := r.gitOutput("rev-parse", "--verify", "--quiet", revision+"^{commit}"); err != nil {
+		// a base branch that was never pushed
+		revision = r.configuredBaseBranch()
+	}
+	output, err := r.gitOutput(append([]string{"ls-tree", "--name-only", revision, "--"}, RepoConfigFiles...)...)
+	if err != nil {
+		return RepoConfig{}, fmt.Errorf("error listing configuration files: %v", err)
+	}
+	present := strings.Fields(output)
+	for _, name := range RepoConfigFiles {
+		if !slices.Contains(present, name) {
+			continue
+		}
+		data, err := r.gitOutput("show", revision+":"+name)
+		if err != nil {
+			return RepoConfig{}, fmt.Errorf("error reading %s: %v", name, err)
+		}
+		config, err := ParseRepoConfig(name, []byte(data))
+		if err != nil {
+			return RepoConfig{}, err
+		}
+		r.Mu.RLock()
+		workflows := r.Workflows
+		r.Mu.RUnlock()
+		if err := config.Validate(workflows); err != nil {
+			return RepoConfig{}, fmt.Errorf("invalid %s:\n%v", name, err)
+		}
+		return config, nil
+	}
+	return RepoConfig{}, nil
+}
+
+// applyRepoConfig loads the configuration file of the base branch for this
+// sync. An invalid file stops the sync and is reported on the issues that
+// would have been worked on when notify is set, the previous configuration
+// stays in place.
+func (r *Repository) applyRepoConfig(selected func(*Issue) bool, notify bool) error {
+	config, err := r.loadRepoConfig()
+	if err != nil {
+		if !notify {
+			return err
+		}
+		issues, _ := r.GetIssues()
+		for _, issue := range issues {
+			if (selected == nil || selected(issue)) && !issue.Completed() {
+				r.reportConfigError(issue, err)
+			}
+		}
+		return err
+	}
+	r.Mu.Lock()
+	r.repoConfig = config
+	r.Mu.Unlock()
+	return nil
+}
+
+// reportConfigError comments the configuration errors on the issue, unless
+// the same errors were reported before
+func (r *Repository) reportConfigError(issue *Issue, err error) {
+	digest := sha256.Sum256([]byte(err.Error()))
+	marker := fmt.Sprintf(configMarker, hex.EncodeToString(digest[:8]))
+	for _, comment := range issue.Comments {
+		if strings.Contains(comment.Body, marker) {
+			return
+		}
+	}
+	body := fmt.Sprintf("%s\nThis issue can't be worked on until the repository configuration is fixed:\n\n```\n%v\n```", marker, err)
+	commentErr := r.Remote.CreateIssueComment(r.RemotePath, issue.Number, types.Comment{Body: body})
+	if commentErr != nil {
+		r.Logger.Error(commentErr, "Error reporting invalid repository configuration", "issue", issue.Number)
+	}
+}
+
+// repositoryConfig returns the configuration file loaded by the last sync
+func (r *Repository) repositoryConfig() RepoConfig {
+	r.Mu.RLock()
+	defer r.Mu.RUnlock()
+	return r.repoConfig
+}
+
+// cloneAgents copies the agents for a single run, with the prompt context of
+// the repository configuration
+func (r *Repository) cloneAgents(agents map[int]*agent.Agent) map[int]*agent.Agent {
+	clones := agent.CloneAgents(agents)
+	instructions := r.repositoryConfig().instructions()
+	for _, clone := range clones {
+		clone.SetInstructions(instructions)
+	}
+	return clones
+}
+
+// instructions tells the agents about the prompt context and the paths they
+// may not change
+func (c RepoConfig) instructions() string {
+	instructions := strings.TrimSpace(c.PromptContext)
+	if len(c.ExcludePaths) > 0 {
+		if instructions != "" {
+			instructions += "\n\n"
+		}
+		instructions += "Do not change files matching these paths, changes to them are discarded: " + strings.Join(c.ExcludePaths, ", ")
+	}
+	return instructions
+}
+
+// excluded reports whether a changed file matches one of the excluded paths
+func (c RepoConfig) excluded(file string) bool {
+	for _, pattern := range c.ExcludePaths {
+		pattern = strings.TrimSuffix(pattern, "/")
+		// match the file and every directory it is in
+		for name := file; name != "." && name != "/"; name = path.Dir(name) {
+			if matched, _ := path.Match(pattern, name); matched {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// discardExcludedChanges reverts the changes the agents made to excluded
+// paths, new files are removed
+func (r *Repository) discardExcludedChanges() error {
+	config := r.repositoryConfig()
+	if len(config.ExcludePaths) == 0 {
+		return nil
+	}
+	changes, err := r.getChanges()
+	if err != nil {
+		return err
+	}
+	for _, file := range changes.Files {
+		if !config.excluded(file) {
+			continue
+		}
+		r.Logger.Info("Discarding change to excluded path", "file", file)
+		if r.git("cat-file", "-e", "HEAD:"+file) == nil {
+			err = r.git("checkout", "HEAD", "--", file)
+		} else {
+			err = os.RemoveAll(filepath.Join(r.Path, file))
+		}
+		if err != nil {
+			return fmt.Errorf("error discarding change to excluded path %s: %v", file, err)
+		}
+	}
+	return nil
+}
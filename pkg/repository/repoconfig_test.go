@@ -0,0 +1,189 @@
+package repository
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/mule-ai/mule/pkg/agent"
+	"github.com/mule-ai/mule/pkg/remote/local"
+	"github.com/mule-ai/mule/pkg/remote/types"
+)
+
+func TestParseRepoConfig(t *testing.T) {
+	config, err := ParseRepoConfig(".mule.yaml", []byte(`
+baseBranch: develop
+validation:
+  functions: [goFmt]
+  commands: ["make lint"]
+excludePaths: ["vendor", "*.pb.go"]
+promptContext: Use table driven tests.
+`))
+	if err != nil {
+		t.Fatalf("ParseRepoConfig returned error: %v", err)
+	}
+	if config.BaseBranch != "develop" || config.Validation.Commands[0] != "make lint" || len(config.ExcludePaths) != 2 {
+		t.Errorf("unexpected config: %+v", config)
+	}
+
+	if _, err := ParseRepoConfig(".mule.yaml", nil); err != nil {
+		t.Errorf("expected an empty file to be valid, got %v", err)
+	}
+	if _, err := ParseRepoConfig(".mule.yaml", []byte("basebranch: develop\n")); err == nil {
+		t.Errorf("expected an error for an unknown field")
+	}
+	if _, err := ParseRepoConfig(".mule.json", []byte(`{"excludePaths": "vendor"}`)); err == nil {
+		t.Errorf("expected an error for a field of the wrong type")
+	}
+}
+
+func TestValidateRepoConfig(t *testing.T) {
+	workflows := map[string]*agent.Workflow{"docs": {}}
+	valid := RepoConfig{BaseBranch: "release/1.0", Workflow: "docs", ExcludePaths: []string{"docs/*.md"}}
+	if err := valid.Validate(workflows); err != nil {
+		t.Errorf("expected the config to be valid, got %v", err)
+	}
+
+	invalid := RepoConfig{
+		BaseBranch:   "two words",
+		Workflow:     "frontend",
+		Validation:   RepoValidationConfig{Functions: []string{"goVet"}, Commands: []string{" "}},
+		ExcludePaths: []string{"../secrets", "[a-"},
+	}
+	err := invalid.Validate(workflows)
+	if err == nil {
+		t.Fatalf("expected the config to be invalid")
+	}
+	for _, field := range []string{"baseBranch", "workflow", "validation.functions", "validation.commands", "../secrets", "[a-"} {
+		if !strings.Contains(err.Error(), field) {
+			t.Errorf("expected an error about %s, got:\n%v", field, err)
+		}
+	}
+}
+
+func TestRepoConfigExcluded(t *testing.T) {
+	config := RepoConfig{ExcludePaths: []string{"vendor/", "*.pb.go", "docs/*.md"}}
+	for file, expected := range map[string]bool{
+		"vendor/github.com/x/y.go": true,
+		"api.pb.go":                true,
+		"api/api.pb.go":            false,
+		"docs/index.md":            true,
+		"docs/guide/index.md":      false,
+		"main.go":                  false,
+	} {
+		if excluded := config.excluded(file); excluded != expected {
+			t.Errorf("expected excluded(%q) to be %v", file, expected)
+		}
+	}
+}
+
+// pushConfig commits the configuration file to a branch of the remote and
+// fetches it
+func pushConfig(t *testing.T, repo *Repository, branch, content string) {
+	t.Helper()
+	commitFile(t, gitOutput(t, repo.Path, "remote", "get-url", "origin"), branch, ".mule.yaml", content)
+	if err := repo.Fetch(); err != nil {
+		t.Fatalf("Fetch returned error: %v", err)
+	}
+}
+
+func TestApplyRepoConfig(t *testing.T) {
+	repo := newClone(t)
+	provider := &local.Provider{
+		Issues:       map[int]*types.Issue{1: {Number: 1, State: "open", Labels: []string{"mule"}}},
+		PullRequests: make(map[int]*types.PullRequest),
+	}
+	repo.Remote = provider
+	repo.Issues = map[int]*Issue{1: ghIssueToIssue(*provider.Issues[1])}
+	docs := &agent.Workflow{ValidationFunctions: []string{"goTest"}}
+	repo.Workflows = map[string]*agent.Workflow{"docs": docs}
+
+	writeConfig := func(content string) {
+		t.Helper()
+		pushConfig(t, repo, "main", content)
+	}
+
+	writeConfig("workflow: docs\nbaseBranch: develop\nvalidation:\n  commands: [make lint]\n")
+	if err := repo.applyRepoConfig(nil, true); err != nil {
+		t.Fatalf("applyRepoConfig returned error: %v", err)
+	}
+	if base := repo.baseBranch(); base != "develop" {
+		t.Errorf("expected the configured base branch, got %q", base)
+	}
+	workflow := repo.configuredWorkflow(&agent.Workflow{})
+	if !slices.Equal(workflow.ValidationFunctions, []string{"goTest", "command:make lint"}) {
+		t.Errorf("expected the docs workflow with the command, got %v", workflow.ValidationFunctions)
+	}
+	if len(docs.ValidationFunctions) != 1 {
+		t.Errorf("expected the configured workflow to be left alone, got %v", docs.ValidationFunctions)
+	}
+
+	writeConfig("workflow: frontend\n")
+	if err := repo.applyRepoConfig(nil, true); err == nil {
+		t.Fatalf("expected an error for an unknown workflow")
+	}
+	comments := provider.Issues[1].Comments
+	if len(comments) != 1 || !strings.Contains(comments[0].Body, `unknown workflow "frontend"`) {
+		t.Errorf("expected the error to be reported on the issue, got %v", comments)
+	}
+	if base := repo.baseBranch(); base != "develop" {
+		t.Errorf("expected the previous configuration to stay in place, got %q", base)
+	}
+}
+
+func TestRepoConfigOfBaseBranch(t *testing.T) {
+	repo := newClone(t)
+	// each branch names the other one as base
+	pushConfig(t, repo, "develop", "baseBranch: main\n")
+	pushConfig(t, repo, "main", "baseBranch: develop\n")
+
+	// the file of the configured base branch decides, whatever is checked out
+	for _, checkout := range []string{"develop", "main", "develop"} {
+		runGit(t, repo.Path, "checkout", "-q", "-B", checkout, "origin/"+checkout)
+		if err := repo.applyRepoConfig(nil, false); err != nil {
+			t.Fatalf("applyRepoConfig returned error: %v", err)
+		}
+		if base := repo.baseBranch(); base != "develop" {
+			t.Errorf("expected develop as base with %s checked out, got %q", checkout, base)
+		}
+	}
+
+	// a local change to the file doesn't count before it is pushed
+	if err := os.WriteFile(filepath.Join(repo.Path, ".mule.yaml"), []byte("baseBranch: release\n"), 0644); err != nil {
+		t.Fatalf("error writing config: %v", err)
+	}
+	if err := repo.applyRepoConfig(nil, false); err != nil || repo.baseBranch() != "develop" {
+		t.Errorf("expected the pushed configuration, got %q, %v", repo.baseBranch(), err)
+	}
+}
+
+func TestDiscardExcludedChanges(t *testing.T) {
+	repo := newClone(t)
+	if err := os.WriteFile(filepath.Join(repo.Path, "README.md"), []byte("original"), 0644); err != nil {
+		t.Fatalf("error writing file: %v", err)
+	}
+	runGit(t, repo.Path, "add", "README.md")
+	runGit(t, repo.Path, "commit", "-m", "add readme")
+	repo.repoConfig = RepoConfig{ExcludePaths: []string{"vendor", "README.md"}}
+	for file, content := range map[string]string{"vendor/lib.go": "package lib", "README.md": "changed", "main.go": "package main"} {
+		path := filepath.Join(repo.Path, file)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("error creating directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("error writing file: %v", err)
+		}
+	}
+
+	if err := repo.discardExcludedChanges(); err != nil {
+		t.Fatalf("discardExcludedChanges returned error: %v", err)
+	}
+	if status := gitOutput(t, repo.Path, "status", "--porcelain"); status != "?? main.go" {
+		t.Errorf("expected only main.go to be changed, got:\n%s", status)
+	}
+	if content, _ := os.ReadFile(filepath.Join(repo.Path, "README.md")); string(content) != "original" {
+		t.Errorf("expected README.md to be restored, got %q", content)
+	}
+}
@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mule-ai/mule/pkg/remote/types"
+)
+
+// SyncReport is the result of a sync, one entry per issue that was selected
+type SyncReport struct {
+	StartedAt  time.Time `json:"startedAt"`
+	FinishedAt time.Time `json:"finishedAt"`
+	// Error is set when the sync stopped before working on the issues
+	Error  string        `json:"error,omitempty"`
+	Issues []IssueResult `json:"issues"`
+	// DryRun is set when the results were stored as dry run artifacts
+	DryRun bool `json:"dryRun,omitempty"`
+}
+
+type IssueResult struct {
+	Issue  int        `json:"issue"`
+	Title  string     `json:"title"`
+	Status WorkStatus `json:"status"`
+	// Skipped is set for issues that were not worked on, either because
+	// they are completed or because they failed too often
+	Skipped bool   `json:"skipped,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+func (s *SyncReport) add(issue *Issue, state IssueWorkState, skipped bool) {
+	result := IssueResult{
+		Issue:   issue.Number,
+		Title:   issue.Title,
+		Status:  state.Status,
+		Skipped: skipped,
+	}
+	if state.Status == WorkFailed {
+		result.Error = state.LastError
+	}
+	s.Issues = append(s.Issues, result)
+}
+
+// addDryRun records an issue worked on in a dry run, which leaves its work
+// state alone. It is done unless err is set.
+func (s *SyncReport) addDryRun(issue *Issue, err error) {
+	result := IssueResult{
+		Issue:  issue.Number,
+		Title:  issue.Title,
+		Status: WorkDone,
+	}
+	if err != nil {
+		result.Status = WorkFailed
+		result.Error = err.Error()
+	}
+	s.Issues = append(s.Issues, result)
+}
+
+// Failed returns the issues that failed during the sync
+func (s *SyncReport) Failed() []IssueResult {
+	var failed []IssueResult
+	for _, result := range s.Issues {
+		if !result.Skipped && result.Status == WorkFailed {
+			failed = append(failed, result)
+		}
+	}
+	return failed
+}
+
+// Summary returns a one line overview of the sync
+func (s *SyncReport) Summary() string {
+	if s.Error != "" {
+		return "sync failed: " + s.Error
+	}
+	var processed, skipped int
+	for _, result := range s.Issues {
+		if result.Skipped {
+			skipped++
+		} else {
+			processed++
+		}
+	}
+	summary := fmt.Sprintf("%d issues processed, %d failed, %d skipped", processed, len(s.Failed()), skipped)
+	if failed := s.Failed(); len(failed) > 0 {
+		numbers := make([]string, len(failed))
+		for i, result := range failed {
+			numbers[i] = fmt.Sprintf("#%d", result.Issue)
+		}
+		summary += " (" + strings.Join(numbers, ", ") + ")"
+	}
+	if s.DryRun {
+		summary = "dry run: " + summary
+	}
+	return summary
+}
+
+// failureCommentMarker tags the failure comments, so they aren't mistaken for
+// a user commenting on the issue
+const failureCommentMarker = "<!-- mule:failure -->"
+
+// LastSyncReport returns the report of the latest sync, nil before the first
+func (r *Repository) LastSyncReport() *SyncReport {
+	r.Mu.RLock()
+	defer r.Mu.RUnlock()
+	return r.lastSyncReport
+}
+
+// reportFailure tells the issue why working on it failed
+func (r *Repository) reportFailure(issue *Issue, err error) {
+	state := r.workState().Get(r.workStatePath(), issue.Number)
+	next := "It will be tried again on the next sync."
+	if state.Attempts >= DefaultMaxAttempts {
+		next = "It will not be tried again until the issue is edited or commented on."
+	}
+	body := fmt.Sprintf("%s\nWorking on this issue failed (attempt %d of %d):\n\n```\n%v\n```\n\n%s",
+		failureCommentMarker, state.Attempts, DefaultMaxAttempts, err, next)
+	commentErr := r.Remote.CreateIssueComment(r.RemotePath, issue.Number, types.Comment{Body: body})
+	if commentErr != nil {
+		r.Logger.Error(commentErr, "Error commenting on failed issue", "issue", issue.Number)
+	}
+}
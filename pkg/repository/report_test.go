@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr"
+
+	"github.com/mule-ai/mule/pkg/remote/local"
+	"github.com/mule-ai/mule/pkg/remote/types"
+)
+
+func TestSyncReport(t *testing.T) {
+	report := &SyncReport{}
+	report.add(&Issue{Number: 1, Title: "works"}, IssueWorkState{Status: WorkPROpen}, false)
+	report.add(&Issue{Number: 2, Title: "breaks"}, IssueWorkState{Status: WorkFailed, LastError: "no changes found"}, false)
+	report.add(&Issue{Number: 3, Title: "done"}, IssueWorkState{Status: WorkPROpen}, true)
+	report.add(&Issue{Number: 4, Title: "keeps breaking"}, IssueWorkState{Status: WorkFailed, LastError: "boom"}, true)
+
+	failed := report.Failed()
+	if len(failed) != 1 || failed[0].Issue != 2 || failed[0].Error != "no changes found" {
+		t.Errorf("expected only issue 2 to have failed, got %+v", failed)
+	}
+	if want := "2 issues processed, 1 failed, 2 skipped (#2)"; report.Summary() != want {
+		t.Errorf("expected summary %q, got %q", want, report.Summary())
+	}
+
+	report.Error = "error fetching"
+	if !strings.Contains(report.Summary(), "error fetching") {
+		t.Errorf("expected the sync error in the summary, got %q", report.Summary())
+	}
+}
+
+func TestReportFailure(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	provider := &local.Provider{
+		Issues: map[int]*types.Issue{
+			5: {Number: 5, Title: "broken"},
+		},
+		PullRequests: make(map[int]*types.PullRequest),
+	}
+	repo := NewRepository(t.TempDir())
+	repo.Logger = logr.Discard()
+	repo.Remote = provider
+	issue := &Issue{Number: 5, Title: "broken"}
+
+	repo.setWorkState(issue, func(state *IssueWorkState) {
+		state.Status = WorkFailed
+		state.Attempts = 1
+	})
+	repo.reportFailure(issue, errors.New("no changes found"))
+
+	repo.setWorkState(issue, func(state *IssueWorkState) {
+		state.Attempts = DefaultMaxAttempts
+	})
+	repo.reportFailure(issue, errors.New("no changes found"))
+
+	comments := provider.Issues[5].Comments
+	if len(comments) != 2 {
+		t.Fatalf("expected two failure comments, got %d", len(comments))
+	}
+	if !strings.Contains(comments[0].Body, "no changes found") || !strings.Contains(comments[0].Body, "tried again") {
+		t.Errorf("expected the error and a retry note, got %q", comments[0].Body)
+	}
+	if !strings.Contains(comments[1].Body, "until the issue is edited") {
+		t.Errorf("expected the issue to wait for an edit, got %q", comments[1].Body)
+	}
+}
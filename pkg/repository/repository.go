@@ -1,22 +1,29 @@
 package repository
 
 import (
+	"errors"
 	"fmt"
+	"maps"
+	"net/url"
 	"os/exec"
+	"slices"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/go-logr/logr"
+	"github.com/google/uuid"
 	"github.com/mule-ai/mule/internal/settings"
 	"github.com/mule-ai/mule/pkg/agent"
 	"github.com/mule-ai/mule/pkg/auth"
+	"github.com/mule-ai/mule/pkg/rag"
 	"github.com/mule-ai/mule/pkg/remote"
 	"github.com/mule-ai/mule/pkg/remote/types"
 
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/config"
-	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport"
 )
 
 /*
@@ -33,14 +40,60 @@ type Repository struct {
 	LastSync       time.Time               `json:"lastSync"`
 	State          *Status                 `json:"status,omitempty"`
 	RemotePath     string                  `json:"remotePath,omitempty"`
-	Issues         map[int]*Issue          `json:"-"`
-	PullRequests   map[int]*PullRequest    `json:"-"`
-	Mu             sync.RWMutex            `json:"-"`
-	Locked         bool                    `json:"locked"`
-	Logger         logr.Logger             `json:"-"`
-	Remote         remote.Provider         `json:"-"`
+	BaseBranch     string                  `json:"baseBranch,omitempty"`
+	MaxConcurrency int                     `json:"maxConcurrency,omitempty"`
+	// SchedulingPolicy and Queue decide the order issues are worked on in
+	SchedulingPolicy SchedulingPolicy `json:"schedulingPolicy,omitempty"`
+	Queue            []int            `json:"queue,omitempty"`
+	// CommentMode decides whether review comments are addressed one by one
+	// or all at once, ReplyToComments answers each addressed comment
+	CommentMode     CommentMode          `json:"commentMode,omitempty"`
+	ReplyToComments bool                 `json:"replyToComments,omitempty"`
+	Auth            auth.Settings        `json:"auth,omitempty"`
+	Issues          map[int]*Issue       `json:"-"`
+	PullRequests    map[int]*PullRequest `json:"-"`
+	Mu              sync.RWMutex         `json:"-"`
+	Locked          bool                 `json:"locked"`
+	Logger          logr.Logger          `json:"-"`
+	Remote          remote.Provider      `json:"-"`
+	RAG             *rag.Store           `json:"-"`
+	WorkState       *WorkStateStore      `json:"-"`
+	// Authorization restricts whose issues and comments are worked on
+	Authorization AuthorizationRules `json:"authorization,omitempty"`
+	// Labels are the trigger, pull request and in progress labels
+	Labels LabelSettings `json:"labels,omitempty"`
+	// BranchUpdate decides how pull request branches catch up with the base
+	// branch, ConflictWorkflow names the workflow resolving conflicts
+	BranchUpdate     BranchUpdate `json:"branchUpdate,omitempty"`
+	ConflictWorkflow string       `json:"conflictWorkflow,omitempty"`
+	// DeleteBranches removes the local and remote branch of a pull request
+	// once it is merged or closed
+	DeleteBranches bool `json:"deleteBranches,omitempty"`
+	// Workflow names the workflow issues are worked on with, the workflow of
+	// the sync when empty. Issues may pick their own with a workflow label.
+	Workflow string `json:"workflow,omitempty"`
+	// Commits are the author, committer and signing of the commits
+	Commits CommitSettings `json:"commits,omitempty"`
+	// DryRun makes every sync of the repository a dry run, see SyncDryRun
+	DryRun bool `json:"dryRun,omitempty"`
+	// Workflows are the configured workflows by name
+	Workflows map[string]*agent.Workflow `json:"-"`
+	// mainPath is the repository an issue worktree belongs to
+	mainPath       string
+	lastSyncReport *SyncReport
+	// repoConfig is the configuration file loaded by the last sync
+	repoConfig RepoConfig
+	// dryRun collects the results of a scratch worktree instead of
+	// committing and pushing them
+	dryRun *DryRunArtifact
+	// worktreeMu serializes adding and removing issue worktrees
+	worktreeMu sync.Mutex
 }
 
+// ErrRepositoryLocked is returned when a sync is started while another one is
+// still running on the repository
+var ErrRepositoryLocked = errors.New("repository is locked")
+
 type Changes struct {
 	Files   []string
 	Commits []string
@@ -77,32 +130,50 @@ func NewRepositoryWithRemote(path string, remote remote.Provider) *Repository {
 }
 
 func (r *Repository) Clone(repoURL string) error {
-	auth, err := auth.GetSSHAuth()
-	if err != nil {
-		return fmt.Errorf("SSH authentication error: %v", err)
-	}
-
 	// set remote path
-	r.RemotePath = strings.TrimPrefix(repoURL, "git@github.com:")
-	r.RemotePath = strings.TrimSuffix(r.RemotePath, ".git")
+	r.RemotePath = remotePathFromURL(repoURL)
 
-	// update url to use ssh
-	repoURL = strings.Replace(repoURL, "https://github.com/", "git@github.com:", 1)
+	// ssh auth needs an ssh url, https auth an https one
+	repoURL = r.Auth.RemoteURL(repoURL, r.gitCredentials())
 
-	_, err = git.PlainClone(r.Path, false, &git.CloneOptions{
+	cloneAuth, err := auth.GetAuth(r.Auth, repoURL, r.gitCredentials())
+	if err != nil {
+		return err
+	}
+
+	repo, err := git.PlainClone(r.Path, false, &git.CloneOptions{
 		URL:      repoURL,
 		Progress: nil,
-		Auth:     auth,
+		Auth:     cloneAuth,
 	})
 	if err != nil {
 		r.Logger.Error(err, "Error cloning repository", "repoURL", repoURL, "path", r.Path)
 		return fmt.Errorf("error cloning repository: %v", err)
 	}
+
+	// a fresh clone has the remote HEAD checked out, record it like git
+	// does so the base branch can be detected again without the remote
+	head, err := repo.Head()
+	if err != nil {
+		return fmt.Errorf("error getting cloned HEAD: %v", err)
+	}
+	remoteHead := plumbing.NewSymbolicReference(
+		plumbing.NewRemoteHEADReferenceName("origin"),
+		plumbing.NewRemoteReferenceName("origin", head.Name().Short()),
+	)
+	if err := repo.Storer.SetReference(remoteHead); err != nil {
+		return fmt.Errorf("error setting remote HEAD: %v", err)
+	}
+	r.Mu.Lock()
+	if r.BaseBranch == "" {
+		r.BaseBranch = head.Name().Short()
+	}
+	r.Mu.Unlock()
 	return nil
 }
 
 func (r *Repository) Upsert(repoURL string) error {
-	_, err := git.PlainOpen(r.Path)
+	_, err := openRepository(r.Path)
 	if err == git.ErrRepositoryNotExists {
 		return r.Clone(repoURL)
 	}
@@ -115,11 +186,12 @@ func (r *Repository) Upsert(repoURL string) error {
 		return err
 	}
 
-	return r.CheckoutBranch("main")
+	r.detectBaseBranch()
+	return r.checkoutBaseBranch()
 }
 
 func (r *Repository) Commit(message string) error {
-	repo, err := git.PlainOpen(r.Path)
+	repo, err := openRepository(r.Path)
 	if err != nil {
 		return err
 	}
@@ -139,32 +211,31 @@ func (r *Repository) Commit(message string) error {
 	}
 
 	// Add all changes
-	_, err = w.Add(".")
+	err = r.git("add", "-A")
 	if err != nil {
 		return err
 	}
 
-	_, err = w.Commit(message, &git.CommitOptions{
-		Author: &object.Signature{
-			Name:  "mule",
-			Email: "mule@muleai.io",
-			When:  time.Now(),
-		},
-	})
-
-	return err
+	// the commit is made by git, which signs it when configured to. The
+	// message is kept as it is, markdown headings included.
+	return r.gitCommit("commit", "--cleanup=whitespace", "-m", message)
 }
 
 func (r *Repository) Push() error {
-	repo, err := git.PlainOpen(r.Path)
+	return r.push(nil)
+}
+
+// push pushes the current branch, with a lease the push is refused when the
+// remote branch is not where the lease expects it
+func (r *Repository) push(lease *git.ForceWithLease) error {
+	repo, err := openRepository(r.Path)
 	if err != nil {
 		return err
 	}
 
-	// Get SSH authentication
-	auth, err := auth.GetSSHAuth()
+	auth, err := r.gitAuth(repo)
 	if err != nil {
-		return fmt.Errorf("SSH authentication error: %v", err)
+		return err
 	}
 
 	currentBranch, err := repo.Head()
@@ -179,23 +250,24 @@ func (r *Repository) Push() error {
 	)
 	refSpec := config.RefSpec(refSpecStr)
 	r.Logger.Info("Pushing", "refSpec", refSpec)
-	// Update push options to include SSH auth
+	// Update push options to include auth
 	return repo.Push(&git.PushOptions{
-		RemoteName: "origin",
-		RefSpecs:   []config.RefSpec{refSpec},
-		Auth:       auth,
+		RemoteName:     "origin",
+		RefSpecs:       []config.RefSpec{refSpec},
+		Auth:           auth,
+		ForceWithLease: lease,
 	})
 }
 
 func (r *Repository) Fetch() error {
-	repo, err := git.PlainOpen(r.Path)
+	repo, err := openRepository(r.Path)
 	if err != nil {
 		return err
 	}
 
-	auth, err := auth.GetSSHAuth()
+	auth, err := r.gitAuth(repo)
 	if err != nil {
-		return fmt.Errorf("SSH authentication error: %v", err)
+		return err
 	}
 
 	err = repo.Fetch(&git.FetchOptions{
@@ -207,7 +279,89 @@ func (r *Repository) Fetch() error {
 	return nil
 }
 
+// gitAuth picks the authentication for the origin remote from the auth
+// settings of the repository
+func (r *Repository) gitAuth(repo *git.Repository) (transport.AuthMethod, error) {
+	origin, err := repo.Remote("origin")
+	if err != nil {
+		return nil, fmt.Errorf("error getting remote: %v", err)
+	}
+	return auth.GetAuth(r.Auth, origin.Config().URLs[0], r.gitCredentials())
+}
+
+// SetAuth replaces the auth settings and points the origin remote at the
+// url the new method needs
+func (r *Repository) SetAuth(settings auth.Settings) error {
+	r.Mu.Lock()
+	r.Auth = settings
+	r.Mu.Unlock()
+
+	originURL, err := r.gitOutput("remote", "get-url", "origin")
+	if err != nil {
+		return err
+	}
+	originURL = strings.TrimSpace(originURL)
+	if remoteURL := settings.RemoteURL(originURL, r.gitCredentials()); remoteURL != originURL {
+		return r.git("remote", "set-url", "origin", remoteURL)
+	}
+	return nil
+}
+
+// remotePathFromURL returns the owner/repo part of a clone url
+func remotePathFromURL(repoURL string) string {
+	var path string
+	if u, err := url.Parse(repoURL); err == nil && u.Scheme != "" && u.Host != "" {
+		path = u.Path
+	} else {
+		// scp-like ssh url, git@host:owner/repo.git
+		_, path, _ = strings.Cut(repoURL, ":")
+	}
+	return strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+}
+
+// gitCredentials returns the HTTPS credentials of the remote provider, nil
+// when it has none
+func (r *Repository) gitCredentials() auth.CredentialsFunc {
+	if credentials, ok := r.Remote.(remote.GitCredentials); ok {
+		return credentials.GitCredentials
+	}
+	return nil
+}
+
 func (r *Repository) Sync(agents map[int]*agent.Agent, workflow *agent.Workflow) error {
+	return r.sync(agents, workflow, nil, r.dryRunConfigured())
+}
+
+// SyncDryRun works on the issues like Sync, but nothing is committed, pushed
+// or posted. The results are stored as dry run artifacts instead.
+func (r *Repository) SyncDryRun(agents map[int]*agent.Agent, workflow *agent.Workflow) error {
+	return r.sync(agents, workflow, nil, true)
+}
+
+// SyncIssue refreshes the repository but only works on the given issue. It is
+// used when a webhook reports a change to a single issue.
+func (r *Repository) SyncIssue(agents map[int]*agent.Agent, workflow *agent.Workflow, issueNumber int) error {
+	return r.sync(agents, workflow, func(issue *Issue) bool {
+		return issue.Number == issueNumber
+	}, r.dryRunConfigured())
+}
+
+// SyncPullRequest refreshes the repository but only works on the issues the
+// given pull request is linked to.
+func (r *Repository) SyncPullRequest(agents map[int]*agent.Agent, workflow *agent.Workflow, prNumber int) error {
+	return r.sync(agents, workflow, func(issue *Issue) bool {
+		for _, pullRequest := range issue.PullRequests {
+			if pullRequest.Number == prNumber {
+				return true
+			}
+		}
+		return false
+	}, r.dryRunConfigured())
+}
+
+// sync updates the repository state and works on every issue accepted by
+// selected, a nil selected accepts all of them
+func (r *Repository) sync(agents map[int]*agent.Agent, workflow *agent.Workflow, selected func(*Issue) bool, dryRun bool) error {
 	r.Logger.Info("Syncing repository")
 	if len(agents) == 0 {
 		return fmt.Errorf("no agents provided")
@@ -219,12 +373,32 @@ func (r *Repository) Sync(agents map[int]*agent.Agent, workflow *agent.Workflow)
 	}
 	defer r.unlock()
 
-	err = r.UpdateStatus()
+	report := &SyncReport{StartedAt: time.Now(), DryRun: dryRun}
+	err = r.syncIssues(agents, workflow, selected, report)
+	report.FinishedAt = time.Now()
+	if err != nil {
+		report.Error = err.Error()
+	}
+	r.Mu.Lock()
+	r.lastSyncReport = report
+	r.Mu.Unlock()
+	r.Logger.Info("Sync finished", "summary", report.Summary())
+	return err
+}
+
+// syncIssues updates the repository state and works on the selected issues.
+// Failing issues are recorded in the report, only errors that stop the whole
+// sync are returned.
+func (r *Repository) syncIssues(agents map[int]*agent.Agent, workflow *agent.Workflow, selected func(*Issue) bool, report *SyncReport) error {
+	err := r.UpdateStatus()
 	if err != nil {
 		r.Logger.Error(err, "Error getting repo status")
 		return err
 	}
 
+	// repositories added before base branches were configurable
+	r.detectBaseBranch()
+
 	// get latest pull requests
 	err = r.UpdatePullRequests()
 	if err != nil {
@@ -242,59 +416,235 @@ func (r *Repository) Sync(agents map[int]*agent.Agent, workflow *agent.Workflow)
 	for _, issue := range r.Issues {
 		issue.addPullRequests(r.PullRequests)
 	}
+	if !report.DryRun {
+		// clean up after pull requests merged or closed since the last sync
+		r.reconcilePullRequests()
+		r.finishWorkStates()
+	}
+	// drop the issues and comments of untrusted authors
+	r.authorizeIssues(!report.DryRun)
 
-	// select issue to work on
-	for _, issue := range r.Issues {
-		// if there are existing changes, log because we can't start work
-		if r.State.HasChanges {
-			r.Logger.Info("There are existing changes, resetting")
-			err = r.Reset()
-			if err != nil {
-				r.Logger.Error(err, "Error resetting repository")
-				return err
-			}
-		}
+	err = r.fetchForWork()
+	if err != nil {
+		r.Logger.Error(err, "Error preparing worktrees")
+		return err
+	}
+	// the configuration file of the base branch applies to this sync, it may
+	// name the base branch to check out
+	err = r.applyRepoConfig(selected, !report.DryRun)
+	if err != nil {
+		r.Logger.Error(err, "Error loading repository configuration")
+		return err
+	}
+	err = r.prepareWorktrees()
+	if err != nil {
+		r.Logger.Error(err, "Error preparing worktrees")
+		return err
+	}
+	workflow = r.configuredWorkflow(workflow)
+	if !report.DryRun {
+		// pull requests behind the base branch catch up before they are
+		// worked on
+		r.updateBranches(agents, workflow, selected)
+	}
 
+	// select issues to work on, in the order of the scheduling policy
+	candidates, _ := r.GetIssues()
+	r.orderIssues(candidates)
+	var issues []*Issue
+	for _, issue := range candidates {
+		if selected != nil && !selected(issue) {
+			continue
+		}
 		if issue.Completed() {
 			r.Logger.Info("Issue already completed", "path", r.Path, "issue", issue.ID)
+			if !report.DryRun {
+				r.recordPullRequest(issue)
+			}
+			report.add(issue, r.workState().Get(r.workStatePath(), issue.Number), true)
 			continue
 		}
-
-		// checkout new branch for issue
-		branchName, err := r.createIssueBranch(issue.Title)
-		if err != nil {
-			return fmt.Errorf("error creating issue branch: %w", err)
+		if r.shouldSkip(issue) {
+			r.Logger.Info("Issue failed too often or was declined, skipping until it is edited", "issue", issue.ID)
+			report.add(issue, r.workState().Get(r.workStatePath(), issue.Number), true)
+			continue
 		}
-		r.State.CurrentBranch = branchName
+		issues = append(issues, issue)
+	}
 
-		r.Logger.Info("Starting generation")
-		commentResolved, err := r.generateFromIssue(agents, workflow, issue)
-		if err != nil {
-			r.Logger.Error(err, "Error generating changes")
-			return err
+	if report.DryRun {
+		errs := r.processIssues(agents, workflow, issues, r.dryRunIssue)
+		for i, issue := range issues {
+			report.addDryRun(issue, errs[i])
 		}
-		if commentResolved {
-			r.Logger.Info("PR comment resolved, skipping PR creation")
-			continue
+		return nil
+	}
+	for _, issue := range issues {
+		r.setWorkState(issue, func(state *IssueWorkState) {
+			state.Status = WorkQueued
+		})
+	}
+	errs := r.processIssues(agents, workflow, issues, r.processIssue)
+	for i, issue := range issues {
+		if errs[i] != nil {
+			r.reportFailure(issue, errs[i])
 		}
+		report.add(issue, r.workState().Get(r.workStatePath(), issue.Number), false)
+	}
+	return nil
+}
 
-		// validate that generation resulted in changes
-		err = r.UpdateStatus()
+// fetchForWork discards leftover changes of the main checkout and fetches the
+// remote before issues are worked on
+func (r *Repository) fetchForWork() error {
+	// if there are existing changes, log because we can't start work
+	if r.State.HasChanges {
+		r.Logger.Info("There are existing changes, resetting")
+		err := r.Reset()
 		if err != nil {
-			r.Logger.Error(err, "Error updating status")
+			r.Logger.Error(err, "Error resetting repository")
 			return err
 		}
+	}
 
-		if !r.State.HasChanges {
-			r.Logger.Info("No changes found, expected changes from AI")
-			return fmt.Errorf("no changes found, expected changes from AI")
-		}
-		err = r.createPR(agents, issue)
-		if err != nil {
-			r.Logger.Error(err, "Error creating PR")
-			return err
+	err := r.Fetch()
+	if err != nil {
+		return fmt.Errorf("error fetching before working on issues: %w", err)
+	}
+	return nil
+}
+
+// prepareWorktrees brings the main checkout back to the latest base branch,
+// new issue worktrees branch off from it, and removes stale worktrees
+func (r *Repository) prepareWorktrees() error {
+	err := r.checkoutBaseBranch()
+	if err != nil {
+		return fmt.Errorf("error checking out %s: %w", r.baseBranch(), err)
+	}
+	err = r.UpdateStatus()
+	if err != nil {
+		return err
+	}
+	return r.cleanupWorktrees()
+}
+
+// processIssues works on the issues concurrently with process, up to
+// MaxConcurrency at once. A failing issue doesn't stop the others, the error
+// of every issue is returned at its index.
+func (r *Repository) processIssues(agents map[int]*agent.Agent, workflow *agent.Workflow, issues []*Issue, process func(map[int]*agent.Agent, *agent.Workflow, *Issue) error) []error {
+	var wg sync.WaitGroup
+	errs := make([]error, len(issues))
+	slots := make(chan struct{}, r.maxConcurrency())
+	for i, issue := range issues {
+		slots <- struct{}{}
+		wg.Add(1)
+		go func(i int, issue *Issue) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			// agents keep per run state like their path, every issue gets
+			// its own copies
+			errs[i] = process(r.cloneAgents(agents), workflow, issue)
+		}(i, issue)
+	}
+	wg.Wait()
+	return errs
+}
+
+// processIssue generates changes for a single issue in its worktree and opens
+// or updates its pull request
+func (r *Repository) processIssue(agents map[int]*agent.Agent, workflow *agent.Workflow, issue *Issue) error {
+	// checkout the issue branch in its own worktree
+	branchName := issueBranchName(issue.Title)
+	// the workflow label of the issue takes precedence
+	issueWorkflow, workflowErr := r.issueWorkflow(issue, workflow)
+	if workflowErr == nil {
+		workflow = issueWorkflow
+	}
+	r.setWorkState(issue, func(state *IssueWorkState) {
+		state.Status = WorkGenerating
+		state.Attempts++
+		state.LastError = ""
+		state.Branch = branchName
+		state.Workflow = workflow.Name()
+		state.RunID = uuid.New().String()
+	})
+
+	err := workflowErr
+	if err == nil {
+		// the in progress label is removed once the work ends, failed or not
+		defer r.markInProgress(issue)()
+		err = r.generateInWorktree(agents, workflow, issue, branchName)
+	}
+	if err != nil {
+		r.Logger.Error(err, "Error working on issue", "issue", issue.ID)
+		r.setWorkState(issue, func(state *IssueWorkState) {
+			state.Status = WorkFailed
+			state.LastError = err.Error()
+		})
+		return err
+	}
+	return nil
+}
+
+func (r *Repository) generateInWorktree(agents map[int]*agent.Agent, workflow *agent.Workflow, issue *Issue, branchName string) error {
+	worktree, err := r.issueWorktree(branchName)
+	if err != nil {
+		r.Logger.Error(err, "Error creating issue worktree", "issue", issue.ID)
+		return fmt.Errorf("error creating issue worktree: %w", err)
+	}
+	err = worktree.generate(agents, workflow, issue)
+	if err != nil {
+		// leave a clean worktree for the next attempt
+		if resetErr := worktree.resetWorktree(); resetErr != nil {
+			worktree.Logger.Error(resetErr, "Error resetting worktree")
 		}
+		return err
+	}
+	return nil
+}
+
+// generate runs the workflow for the issue in the current checkout and
+// commits the result to its pull request
+func (r *Repository) generate(agents map[int]*agent.Agent, workflow *agent.Workflow, issue *Issue) error {
+	r.Logger.Info("Starting generation")
+	commentResolved, err := r.generateFromIssue(agents, workflow, issue)
+	if err != nil {
+		r.Logger.Error(err, "Error generating changes")
+		return err
+	}
+	if commentResolved {
+		r.Logger.Info("PR comment resolved, skipping PR creation")
+		r.setWorkState(issue, func(state *IssueWorkState) {
+			state.Status = WorkAwaitingFeedback
+			state.Attempts = 0
+		})
+		return nil
+	}
+
+	// validate that generation resulted in changes
+	r.setWorkState(issue, func(state *IssueWorkState) {
+		state.Status = WorkValidating
+	})
+	err = r.UpdateStatus()
+	if err != nil {
+		r.Logger.Error(err, "Error updating status")
+		return err
+	}
+
+	if !r.State.HasChanges {
+		r.Logger.Info("No changes found, expected changes from AI")
+		return fmt.Errorf("no changes found, expected changes from AI")
 	}
+	prNumber, err := r.createPR(agents, issue)
+	if err != nil {
+		r.Logger.Error(err, "Error creating PR")
+		return err
+	}
+	r.setWorkState(issue, func(state *IssueWorkState) {
+		state.Status = WorkPROpen
+		state.Attempts = 0
+		state.PullRequest = prNumber
+	})
 	return nil
 }
 
@@ -307,7 +657,7 @@ func (r *Repository) ChangeSummary() (string, error) {
 }
 
 func (r *Repository) getChanges() (*Changes, error) {
-	repo, err := git.PlainOpen(r.Path)
+	repo, err := openRepository(r.Path)
 	if err != nil {
 		return nil, err
 	}
@@ -333,7 +683,8 @@ func (r *Repository) getChanges() (*Changes, error) {
 	}
 
 	currentBranch := head.Name().Short()
-	branchChanges, err := getBranchChanges(repo, currentBranch, "main")
+	baseBranch := r.baseBranch()
+	branchChanges, err := getBranchChanges(repo, currentBranch, baseBranch)
 	if err != nil {
 		return nil, fmt.Errorf("error getting branch changes: %v", err)
 	}
@@ -355,11 +706,11 @@ func (r *Repository) getChanges() (*Changes, error) {
 		}
 	}
 
-	// Get the diff between working directory and main branch
-	cmd := exec.Command("git", "-C", r.Path, "diff", "main")
+	// Get the diff between working directory and base branch
+	cmd := exec.Command("git", "-C", r.Path, "diff", baseBranch)
 	diffOutput, err := cmd.CombinedOutput()
 	if err != nil {
-		// If main doesn't exist or other error, just show all changes
+		// If the base branch doesn't exist or other error, just show all changes
 		cmd = exec.Command("git", "-C", r.Path, "diff")
 		diffOutput, err = cmd.CombinedOutput()
 		if err != nil {
@@ -381,54 +732,77 @@ func (r *Repository) getChanges() (*Changes, error) {
 
 func (r *Repository) generateFromIssue(agents map[int]*agent.Agent, workflow *agent.Workflow, issue *Issue) (bool, error) {
 	prompt := ""
-	var unresolvedCommentId int64
+	var pr *PullRequest
+	var unresolvedComments []*Comment
 	var promptInput agent.PromptInput
 	// if issue has not PR, send issue prompt
 	if !issue.PrExists() {
 		promptInput = agent.PromptInput{
-			IssueTitle:  issue.Title,
-			IssueBody:   issue.Body,
-			Commits:     "",
-			Diff:        "",
-			PRComment:   prompt,
-			IsPRComment: false,
+			IssueTitle:    issue.Title,
+			IssueBody:     issue.Body,
+			IssueComments: issue.RenderComments(),
+			Commits:       "",
+			Diff:          "",
+			PRComment:     prompt,
+			IsPRComment:   false,
 		}
 	} else {
 		// if issue has PR, send PR comment prompt
-		pr, hasUnresolvedComments := issue.PRHasUnresolvedComments()
-		unresolvedComment := pr.FirstUnresolvedComment()
-		if hasUnresolvedComments {
-			unresolvedCommentId = unresolvedComment.ID
+		var hasUnresolvedComments bool
+		pr, hasUnresolvedComments = issue.PRHasUnresolvedComments()
+		if !hasUnresolvedComments {
+			return false, fmt.Errorf("expected PR with unresolved comments, but none found")
+		}
+		if r.commentMode() == CommentModeBatch {
+			unresolvedComments = pr.UnresolvedComments()
+			promptInput = reviewPromptInput(issue, pr, unresolvedComments)
+		} else {
+			unresolvedComment := pr.FirstUnresolvedComment()
+			unresolvedComments = []*Comment{unresolvedComment}
 			promptInput = agent.PromptInput{
 				IssueTitle:        issue.Title,
 				IssueBody:         issue.Body,
+				IssueComments:     issue.RenderComments(),
 				Commits:           "",
 				Diff:              pr.Diff,
 				PRComment:         unresolvedComment.Body,
 				PRCommentDiffHunk: unresolvedComment.DiffHunk,
+				PRCommentKind:     unresolvedComment.Kind,
 				IsPRComment:       true,
 			}
-		} else {
-			return false, fmt.Errorf("expected PR with unresolved comments, but none found")
 		}
 	}
 
 	// err := agent.RunWorkflow(agents, promptInput, r.Path)
-	_, err := agent.ExecuteWorkflow(workflow.Steps, agents, promptInput, r.Path, r.Logger, workflow.ValidationFunctions)
+	results, err := agent.ExecuteWorkflow(workflow.Steps, agents, promptInput, r.Path, r.Logger, workflow.ValidationFunctions)
 	if err != nil {
 		r.Logger.Error(err, "Error running agent")
 		return false, err
 	}
+	err = r.discardExcludedChanges()
+	if err != nil {
+		return false, err
+	}
 
-	// If we're handling a PR comment, commit and push to the existing branch
-	if unresolvedCommentId != 0 {
-		return true, r.updatePR(agents, unresolvedCommentId)
+	// If we're handling PR comments, commit and push to the existing branch
+	if len(unresolvedComments) == 0 {
+		return false, nil
+	}
+	addressed := parseAddressed(results["final"].Content, unresolvedComments)
+	if len(promptInput.ReviewComments) == 0 {
+		// a single comment is addressed by running the workflow
+		id := unresolvedComments[0].ID
+		addressed = map[int64]string{id: addressed[id]}
+	} else if len(addressed) == 0 {
+		return false, fmt.Errorf("agent did not address any of the %d review comments", len(unresolvedComments))
 	}
-	return false, nil
+	return true, r.updatePR(agents, issue, pr, addressed)
 }
 
-func (r *Repository) updatePR(agents map[int]*agent.Agent, commentId int64) error {
-	if commentId == 0 {
+// updatePR pushes the changes made for review comments and acknowledges the
+// addressed ones
+func (r *Repository) updatePR(agents map[int]*agent.Agent, issue *Issue, pr *PullRequest, addressed map[int64]string) error {
+	if len(addressed) == 0 {
 		return fmt.Errorf("expected PR comment ID, but none found")
 	}
 	summary, err := r.ChangeSummary()
@@ -436,18 +810,29 @@ func (r *Repository) updatePR(agents map[int]*agent.Agent, commentId int64) erro
 		r.Logger.Error(err, "Error getting change summary")
 		return err
 	}
-	commitMessage, err := agents[settings.CommitAgent].Generate("", agent.PromptInput{
+	conventions, err := r.loadConventions()
+	if err != nil {
+		r.Logger.Error(err, "Error loading commit conventions")
+		return err
+	}
+	commitMessage, err := r.generateCommitMessage(agents[settings.CommitAgent], agent.PromptInput{
 		IssueTitle:  "",
 		IssueBody:   "",
 		Commits:     "",
 		Diff:        summary,
 		PRComment:   "",
 		IsPRComment: false,
-	})
+	}, conventions, "address review comments", issue.Labels)
 	if err != nil {
 		r.Logger.Error(err, "Error generating commit message")
 		return err
 	}
+	commitMessage = r.withCoAuthor(commitMessage, issue)
+	if r.dryRun != nil {
+		r.dryRun.PullRequest = pr.Number
+		r.dryRun.AddressedComments = slices.Sorted(maps.Keys(addressed))
+		return r.recordDryRun(commitMessage)
+	}
 
 	// Commit changes
 	err = r.Commit(commitMessage)
@@ -463,61 +848,53 @@ func (r *Repository) updatePR(agents map[int]*agent.Agent, commentId int64) erro
 		return err
 	}
 
-	// Add reaction to mark comment as addressed
-	err = r.Remote.AddCommentReaction(r.RemotePath, "+1", commentId)
-	if err != nil {
-		r.Logger.Error(err, "Error acknowledging PR comment")
-		return err
-	}
-	return nil
+	// Add reactions to mark the comments as addressed
+	return r.acknowledgeComments(pr, addressed)
 }
 
 // ignore unused code error
-func (r *Repository) createPR(agents map[int]*agent.Agent, issue *Issue) error {
+func (r *Repository) createPR(agents map[int]*agent.Agent, issue *Issue) (int, error) {
 	summary, err := r.ChangeSummary()
 	if err != nil {
 		r.Logger.Error(err, "Error getting change summary")
-		return err
+		return 0, err
 	}
 
 	promptInput := agent.PromptInput{
-		IssueTitle:  issue.Title,
-		IssueBody:   issue.Body,
-		Commits:     "",
-		Diff:        summary,
-		PRComment:   "",
-		IsPRComment: false,
+		IssueTitle:    issue.Title,
+		IssueBody:     issue.Body,
+		IssueComments: issue.RenderComments(),
+		Commits:       "",
+		Diff:          summary,
+		PRComment:     "",
+		IsPRComment:   false,
 	}
 
-	commitMessage, err := agents[settings.CommitAgent].Generate("", promptInput)
+	// the commit messages and the description follow the conventions of
+	// the repository
+	conventions, err := r.loadConventions()
 	if err != nil {
-		r.Logger.Error(err, "Error generating commit message")
-		return err
-	}
-	// Commit changes
-	err = r.Commit(commitMessage)
-	if err != nil {
-		r.Logger.Error(err, "Error committing changes")
-		return err
+		r.Logger.Error(err, "Error loading commit conventions")
+		return 0, err
 	}
 
-	// Push changes
-	err = r.Push()
+	commitMessage, err := r.generateCommitMessage(agents[settings.CommitAgent], promptInput, conventions, issue.Title, issue.Labels)
 	if err != nil {
-		r.Logger.Error(err, "Error pushing changes")
-		return err
+		r.Logger.Error(err, "Error generating commit message")
+		return 0, err
 	}
+	commitMessage = r.withCoAuthor(commitMessage, issue)
 
-	prTitle, err := agents[settings.PRTitleAgent].Generate("", promptInput)
+	prTitle, err := r.generatePRTitle(agents[settings.PRTitleAgent], promptInput, conventions, issue)
 	if err != nil {
 		r.Logger.Error(err, "Error generating PR title")
-		return err
+		return 0, err
 	}
 
-	prDescription, err := agents[settings.PRBodyAgent].Generate("", promptInput)
+	prDescription, err := r.generatePRBody(agents[settings.PRBodyAgent], promptInput, conventions, issue)
 	if err != nil {
 		r.Logger.Error(err, "Error generating PR description")
-		return err
+		return 0, err
 	}
 
 	// add issue close tag to description
@@ -525,13 +902,33 @@ func (r *Repository) createPR(agents map[int]*agent.Agent, issue *Issue) error {
 		prDescription,
 		fmt.Sprintf("Closes #%d", issue.ID),
 		issue.SourceURL)
+	if r.dryRun != nil {
+		r.dryRun.PRTitle = prTitle
+		r.dryRun.PRBody = prDescription
+		return 0, r.recordDryRun(commitMessage)
+	}
+
+	// Commit changes
+	err = r.Commit(commitMessage)
+	if err != nil {
+		r.Logger.Error(err, "Error committing changes")
+		return 0, err
+	}
+
+	// Push changes
+	err = r.Push()
+	if err != nil {
+		r.Logger.Error(err, "Error pushing changes")
+		return 0, err
+	}
 	return r.Remote.CreateDraftPR(r.Path, types.PullRequestInput{
 		Title:               prTitle,
 		Branch:              r.State.CurrentBranch,
-		Base:                "main",
+		Base:                r.baseBranch(),
 		Description:         prDescription,
 		Draft:               true,
 		MaintainerCanModify: true,
+		Labels:              r.pullRequestLabels(),
 	})
 }
 
@@ -540,7 +937,7 @@ func (r *Repository) lock() error {
 	locked := r.Locked
 	r.Mu.RUnlock()
 	if locked {
-		return fmt.Errorf("repository is locked")
+		return ErrRepositoryLocked
 	}
 	r.Mu.Lock()
 	defer r.Mu.Unlock()
@@ -0,0 +1,281 @@
+package repository
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/mule-ai/mule/pkg/agent"
+	"github.com/mule-ai/mule/pkg/remote/types"
+)
+
+// decides how the review comments on the pull request of an issue are
+// worked on
+
+type CommentMode string
+
+/*
+Modes:
+single: one workflow run and one commit per review comment, the oldest
+unresolved comment first
+batch: all unresolved review comments in one workflow run and one commit, the
+agent lists the comments it addressed
+*/
+const (
+	CommentModeSingle CommentMode = "single"
+	CommentModeBatch  CommentMode = "batch"
+)
+
+// DefaultCommentMode is used when a repository does not set a comment mode
+const DefaultCommentMode = CommentModeSingle
+
+// replyMarker tags the replies to review comments with the ID of the comment
+// they answer. Replies aren't worked on as review comments, and on providers
+// with review threads they mark the answered comment as addressed.
+const replyMarker = "<!-- mule:reply %d -->"
+
+var replyPattern = regexp.MustCompile(`<!-- mule:reply (\d+) -->`)
+
+// addressedPattern matches the lines the agent lists the addressed comments
+// with, "ADDRESSED <id>: <explanation>"
+var addressedPattern = regexp.MustCompile(`(?m)^[\s*-]*ADDRESSED\s+#?(\d+)[ \t]*(?:[:-][ \t]*(.*?))?[ \t]*$`)
+
+// ParseCommentMode checks a comment mode name, an empty name gives the default
+func ParseCommentMode(name string) (CommentMode, error) {
+	mode := CommentMode(strings.TrimSpace(name))
+	switch mode {
+	case "":
+		return DefaultCommentMode, nil
+	case CommentModeSingle, CommentModeBatch:
+		return mode, nil
+	}
+	return "", fmt.Errorf("unknown comment mode: %s", name)
+}
+
+func (r *Repository) commentMode() CommentMode {
+	r.Mu.RLock()
+	defer r.Mu.RUnlock()
+	if r.CommentMode == "" {
+		return DefaultCommentMode
+	}
+	return r.CommentMode
+}
+
+// UnresolvedComments returns the review comments that are not acknowledged yet
+func (p *PullRequest) UnresolvedComments() []*Comment {
+	var comments []*Comment
+	for _, comment := range p.Comments {
+		if !comment.Acknowledged {
+			comments = append(comments, comment)
+		}
+	}
+	return comments
+}
+
+// reviewPromptInput builds the prompt input for addressing all unresolved
+// review comments of the pull request at once
+func reviewPromptInput(issue *Issue, pr *PullRequest, comments []*Comment) agent.PromptInput {
+	reviewComments := make([]agent.ReviewComment, len(comments))
+	for i, comment := range comments {
+		reviewComments[i] = agent.ReviewComment{
+			ID:          comment.ID,
+			Path:        comment.Path,
+			Line:        comment.Line,
+			DiffHunk:    comment.DiffHunk,
+			Body:        comment.Body,
+			Kind:        comment.Kind,
+			ReviewState: comment.ReviewState,
+		}
+	}
+	return agent.PromptInput{
+		IssueTitle:     issue.Title,
+		IssueBody:      issue.Body,
+		IssueComments:  issue.RenderComments(),
+		Diff:           pr.Diff,
+		PRComment:      renderReviewComments(reviewComments),
+		IsPRComment:    true,
+		ReviewComments: reviewComments,
+	}
+}
+
+// renderReviewComments lists the review comments for prompt templates that
+// don't range over them, followed by how to report the addressed ones
+func renderReviewComments(comments []agent.ReviewComment) string {
+	var rendered strings.Builder
+	for _, comment := range comments {
+		switch comment.Kind {
+		case types.CommentKindConversation:
+			fmt.Fprintf(&rendered, "Comment %d on the pull request", comment.ID)
+		case types.CommentKindReview:
+			fmt.Fprintf(&rendered, "Review %d", comment.ID)
+			if comment.ReviewState != "" {
+				fmt.Fprintf(&rendered, " (%s)", strings.ToLower(strings.ReplaceAll(comment.ReviewState, "_", " ")))
+			}
+		default:
+			fmt.Fprintf(&rendered, "Comment %d", comment.ID)
+			if comment.Path != "" {
+				fmt.Fprintf(&rendered, " on %s", comment.Path)
+				if comment.Line > 0 {
+					fmt.Fprintf(&rendered, " line %d", comment.Line)
+				}
+			}
+		}
+		fmt.Fprintf(&rendered, ":\n%s\n", strings.TrimSpace(comment.Body))
+		if comment.DiffHunk != "" {
+			fmt.Fprintf(&rendered, "\non the following lines:\n%s\n", strings.TrimRight(comment.DiffHunk, "\n"))
+		}
+		rendered.WriteString("\n")
+	}
+	rendered.WriteString("Address as many of these comments as you can. When you are done, list every comment you addressed on its own line as\n" +
+		"ADDRESSED <comment id>: <one sentence explaining the change>")
+	return rendered.String()
+}
+
+// parseAddressed returns the comments the agent output lists as addressed,
+// mapped to the explanation it gave. IDs of other comments are ignored.
+func parseAddressed(output string, comments []*Comment) map[int64]string {
+	known := make(map[int64]bool, len(comments))
+	for _, comment := range comments {
+		known[comment.ID] = true
+	}
+	addressed := make(map[int64]string)
+	for _, match := range addressedPattern.FindAllStringSubmatch(output, -1) {
+		id, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil || !known[id] {
+			continue
+		}
+		addressed[id] = strings.TrimSpace(match[2])
+	}
+	return addressed
+}
+
+// acknowledgeComments marks the addressed review comments as resolved and,
+// when the repository replies to comments, answers them with the explanation.
+// Review threads are resolved once all their open comments are addressed,
+// other inline comments get a +1 reaction. General feedback is answered on
+// the pull request.
+func (r *Repository) acknowledgeComments(pr *PullRequest, addressed map[int64]string) error {
+	r.Mu.RLock()
+	reply := r.ReplyToComments
+	r.Mu.RUnlock()
+
+	resolvable := resolvableThreads(pr, addressed)
+	resolved := make(map[string]bool)
+	// in comment order, so the replies read in the order of the review
+	for _, id := range slices.Sorted(maps.Keys(addressed)) {
+		explanation := addressed[id]
+		comment := pr.comment(id)
+		threaded := comment != nil && comment.ThreadID != ""
+		general := comment != nil && comment.Kind != ""
+
+		if !threaded && !general {
+			err := r.Remote.AddCommentReaction(r.RemotePath, "+1", id)
+			if err != nil {
+				r.Logger.Error(err, "Error acknowledging PR comment", "comment", id)
+				return err
+			}
+		}
+
+		// general feedback has no thread to resolve, and a thread can't be
+		// resolved while it has other open comments, the reply marks the
+		// comment as addressed then
+		replyNeeded := general || (threaded && !resolvable[comment.ThreadID])
+		if replyNeeded && explanation == "" {
+			explanation = "Addressed."
+		}
+		if replyNeeded || (reply && explanation != "") {
+			body := fmt.Sprintf(replyMarker+"\n%s", id, explanation)
+			var err error
+			if general {
+				err = r.Remote.CreatePRComment(r.RemotePath, pr.Number, types.Comment{Body: body})
+			} else {
+				err = r.Remote.ReplyToComment(r.RemotePath, pr.Number, id, body)
+			}
+			if err != nil {
+				r.Logger.Error(err, "Error replying to PR comment", "comment", id)
+				// otherwise the comment is acknowledged, a missing reply is
+				// not worth a retry
+				if replyNeeded {
+					return err
+				}
+			}
+		}
+
+		if threaded && resolvable[comment.ThreadID] && !resolved[comment.ThreadID] {
+			err := r.Remote.ResolveComment(r.RemotePath, pr.Number, id)
+			if err != nil {
+				r.Logger.Error(err, "Error resolving review thread", "comment", id)
+				return err
+			}
+			resolved[comment.ThreadID] = true
+		}
+	}
+	return nil
+}
+
+// resolvableThreads returns the review threads whose open comments are all
+// addressed
+func resolvableThreads(pr *PullRequest, addressed map[int64]string) map[string]bool {
+	resolvable := make(map[string]bool)
+	for _, comment := range pr.Comments {
+		if comment.ThreadID == "" || comment.Acknowledged {
+			continue
+		}
+		_, ok := addressed[comment.ID]
+		if open, seen := resolvable[comment.ThreadID]; seen {
+			resolvable[comment.ThreadID] = open && ok
+		} else {
+			resolvable[comment.ThreadID] = ok
+		}
+	}
+	return resolvable
+}
+
+func (p *PullRequest) comment(id int64) *Comment {
+	for _, comment := range p.Comments {
+		if comment.ID == id {
+			return comment
+		}
+	}
+	return nil
+}
+
+// repliedComments returns the IDs of the comments that replies of mule's
+// account carrying the reply marker answer
+func repliedComments(comments []*types.Comment, account string) map[int64]bool {
+	replied := make(map[int64]bool)
+	for _, comment := range comments {
+		if !postedBy(comment, account) {
+			continue
+		}
+		for _, match := range replyPattern.FindAllStringSubmatch(comment.Body, -1) {
+			if id, err := strconv.ParseInt(match[1], 10, 64); err == nil {
+				replied[id] = true
+			}
+		}
+	}
+	return replied
+}
+
+// acknowledged decides whether a review comment still needs work. The replies
+// of mule are never worked on. Comments in a review thread are done when the
+// thread is resolved or outdated, or when mule replied to them, other
+// comments when they have a +1 reaction.
+func acknowledged(comment *types.Comment, replied map[int64]bool, account string) bool {
+	if (postedBy(comment, account) && replyPattern.MatchString(comment.Body)) || replied[comment.ID] {
+		return true
+	}
+	if comment.ThreadID != "" {
+		return comment.Resolved || comment.Outdated
+	}
+	return comment.Reactions.PlusOne > 0
+}
+
+// postedBy reports whether the comment was written by the account, reply
+// markers pasted by anyone else don't count
+func postedBy(comment *types.Comment, account string) bool {
+	return account != "" && strings.EqualFold(comment.Author, account)
+}
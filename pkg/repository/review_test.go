@@ -0,0 +1,272 @@
+package repository
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr"
+
+	"github.com/mule-ai/mule/pkg/remote/local"
+	"github.com/mule-ai/mule/pkg/remote/types"
+)
+
+func TestParseAddressed(t *testing.T) {
+	comments := []*Comment{{ID: 11}, {ID: 12}, {ID: 13}}
+	output := "Renamed the handler and added a test.\n\n" +
+		"ADDRESSED 11: renamed handleSync to syncHandler\n" +
+		"- ADDRESSED #12\n" +
+		"ADDRESSED 99: not a comment of this pull request\n" +
+		"NOT ADDRESSED 13: needs a decision from the reviewer\n"
+
+	addressed := parseAddressed(output, comments)
+	if len(addressed) != 2 {
+		t.Fatalf("expected comments 11 and 12 to be addressed, got %v", addressed)
+	}
+	if addressed[11] != "renamed handleSync to syncHandler" {
+		t.Errorf("unexpected explanation for comment 11: %q", addressed[11])
+	}
+	if explanation, ok := addressed[12]; !ok || explanation != "" {
+		t.Errorf("expected comment 12 without explanation, got %q, %v", explanation, ok)
+	}
+}
+
+func TestReviewPromptInput(t *testing.T) {
+	issue := &Issue{Number: 1, Title: "Rename sync"}
+	pr := &PullRequest{
+		Number: 4,
+		Diff:   "diff --git a/sync.go b/sync.go",
+		Comments: []*Comment{
+			{ID: 11, Body: "rename this", Path: "sync.go", Line: 3, DiffHunk: "@@ -1,3 +1,3 @@\n-func handleSync() {}"},
+			{ID: 12, Body: "done", Acknowledged: true},
+			{ID: 13, Body: "add a test please"},
+			{ID: 14, Body: "please update the changelog", Kind: types.CommentKindConversation},
+			{ID: 15, Body: "needs a test", Kind: types.CommentKindReview, ReviewState: "CHANGES_REQUESTED"},
+		},
+	}
+
+	input := reviewPromptInput(issue, pr, pr.UnresolvedComments())
+	if len(input.ReviewComments) != 4 || input.ReviewComments[0].Path != "sync.go" || input.ReviewComments[3].Kind != types.CommentKindReview {
+		t.Fatalf("expected the unresolved comments, got %+v", input.ReviewComments)
+	}
+	if !input.IsPRComment || input.Diff != pr.Diff {
+		t.Errorf("expected a PR comment prompt with the diff, got %+v", input)
+	}
+	for _, want := range []string{"Comment 11 on sync.go line 3:\nrename this", "-func handleSync() {}", "Comment 13:\nadd a test please",
+		"Comment 14 on the pull request:\nplease update the changelog", "Review 15 (changes requested):\nneeds a test", "ADDRESSED <comment id>"} {
+		if !strings.Contains(input.PRComment, want) {
+			t.Errorf("expected %q in the rendered comments:\n%s", want, input.PRComment)
+		}
+	}
+	if strings.Contains(input.PRComment, "Comment 12") {
+		t.Errorf("expected acknowledged comments to be left out:\n%s", input.PRComment)
+	}
+}
+
+func TestAcknowledgeComments(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	provider := &local.Provider{
+		Issues: make(map[int]*types.Issue),
+		PullRequests: map[int]*types.PullRequest{
+			4: {Number: 4, Comments: []*types.Comment{{ID: 11, Body: "rename this"}, {ID: 12, Body: "add a test"}}},
+		},
+	}
+	repo := NewRepository(t.TempDir())
+	repo.Logger = logr.Discard()
+	repo.Remote = provider
+	repo.ReplyToComments = true
+
+	pr := ghPullRequestToPullRequest(*provider.PullRequests[4], local.MuleUser)
+	err := repo.acknowledgeComments(pr, map[int64]string{11: "renamed it", 12: ""})
+	if err != nil {
+		t.Fatalf("acknowledgeComments returned error: %v", err)
+	}
+
+	comments := provider.PullRequests[4].Comments
+	if comments[0].Reactions.PlusOne != 1 || comments[1].Reactions.PlusOne != 1 {
+		t.Errorf("expected both comments to be acknowledged, got %+v and %+v", comments[0].Reactions, comments[1].Reactions)
+	}
+	if len(comments) != 3 || comments[2].InReplyTo != 11 || !strings.Contains(comments[2].Body, "renamed it") {
+		t.Fatalf("expected one reply to comment 11, got %d comments", len(comments))
+	}
+
+	// the reply must not come back as a review comment to work on
+	pr = ghPullRequestToPullRequest(*provider.PullRequests[4], local.MuleUser)
+	if pr.HasUnresolvedComments() {
+		t.Errorf("expected the reply to count as resolved, got %+v", pr.UnresolvedComments())
+	}
+}
+
+// threadedProvider records resolved comments like a provider with review
+// threads
+type threadedProvider struct {
+	*local.Provider
+	resolved []int64
+}
+
+func (p *threadedProvider) ResolveComment(remotePath string, prNumber int, commentID int64) error {
+	p.resolved = append(p.resolved, commentID)
+	return nil
+}
+
+func TestAcknowledgeThreadedComments(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	provider := &threadedProvider{Provider: &local.Provider{
+		Issues: make(map[int]*types.Issue),
+		PullRequests: map[int]*types.PullRequest{
+			4: {Number: 4, Comments: []*types.Comment{
+				{ID: 11, Body: "rename this", ThreadID: "T1"},
+				{ID: 12, Body: "and update the docs", ThreadID: "T1", InReplyTo: 11},
+				{ID: 13, Body: "add a test", ThreadID: "T2"},
+			}},
+		},
+	}}
+	repo := NewRepository(t.TempDir())
+	repo.Logger = logr.Discard()
+	repo.Remote = provider
+
+	pr := ghPullRequestToPullRequest(*provider.PullRequests[4], local.MuleUser)
+	err := repo.acknowledgeComments(pr, map[int64]string{11: "renamed it", 13: ""})
+	if err != nil {
+		t.Fatalf("acknowledgeComments returned error: %v", err)
+	}
+
+	// T2 has no other open comment, T1 still waits for comment 12
+	if len(provider.resolved) != 1 || provider.resolved[0] != 13 {
+		t.Errorf("expected only the thread of comment 13 to be resolved, got %v", provider.resolved)
+	}
+	comments := provider.PullRequests[4].Comments
+	for _, comment := range comments[:3] {
+		if comment.Reactions.PlusOne != 0 {
+			t.Errorf("expected no reactions on threaded comments, got %+v on %d", comment.Reactions, comment.ID)
+		}
+	}
+	if len(comments) != 4 || comments[3].InReplyTo != 11 {
+		t.Fatalf("expected a reply to comment 11, got %d comments", len(comments))
+	}
+	comments[3].ThreadID = "T1"
+
+	pr = ghPullRequestToPullRequest(*provider.PullRequests[4], local.MuleUser)
+	unresolved := pr.UnresolvedComments()
+	if len(unresolved) != 2 || unresolved[0].ID != 12 || unresolved[1].ID != 13 {
+		t.Errorf("expected comment 11 to be addressed by the reply, got %+v", unresolved)
+	}
+}
+
+func TestAcknowledgeGeneralFeedback(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	provider := &local.Provider{
+		Issues: make(map[int]*types.Issue),
+		PullRequests: map[int]*types.PullRequest{
+			4: {Number: 4, Comments: []*types.Comment{
+				{ID: 21, Body: "please also add tests", Kind: types.CommentKindConversation},
+				{ID: 22, Body: "looks fine otherwise", Kind: types.CommentKindReview, ReviewState: "COMMENTED"},
+			}},
+		},
+	}
+	repo := NewRepository(t.TempDir())
+	repo.Logger = logr.Discard()
+	repo.Remote = provider
+
+	pr := ghPullRequestToPullRequest(*provider.PullRequests[4], local.MuleUser)
+	if err := repo.acknowledgeComments(pr, map[int64]string{21: "added tests for the sync"}); err != nil {
+		t.Fatalf("acknowledgeComments returned error: %v", err)
+	}
+
+	comments := provider.PullRequests[4].Comments
+	if comments[0].Reactions.PlusOne != 0 {
+		t.Errorf("expected no reaction on general feedback, got %+v", comments[0].Reactions)
+	}
+	if len(comments) != 3 || comments[2].InReplyTo != 0 || !strings.Contains(comments[2].Body, "added tests for the sync") {
+		t.Fatalf("expected an answer on the pull request, got %d comments", len(comments))
+	}
+
+	pr = ghPullRequestToPullRequest(*provider.PullRequests[4], local.MuleUser)
+	unresolved := pr.UnresolvedComments()
+	if len(unresolved) != 1 || unresolved[0].ID != 22 {
+		t.Errorf("expected only the review summary to be left, got %+v", unresolved)
+	}
+}
+
+func TestGeneralFeedbackUntilAnswered(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	provider := &local.Provider{
+		Issues: make(map[int]*types.Issue),
+		PullRequests: map[int]*types.PullRequest{
+			4: {Number: 4, Comments: []*types.Comment{
+				{ID: 21, Body: "please also add tests", Kind: types.CommentKindConversation, Author: "alice", CreatedAt: "2026-01-01T00:00:00Z"},
+				{ID: 22, Body: "and update the docs", Kind: types.CommentKindConversation, Author: "alice", CreatedAt: "2026-01-01T00:01:00Z"},
+			}},
+		},
+	}
+	repo := NewRepository(t.TempDir())
+	repo.Logger = logr.Discard()
+	repo.Remote = provider
+	repo.CommentMode = CommentModeSingle
+
+	// in single mode each sync addresses and pushes one comment, the other
+	// one stays open until it is answered too
+	for _, id := range []int64{21, 22} {
+		pr := ghPullRequestToPullRequest(*provider.PullRequests[4], local.MuleUser)
+		first := pr.FirstUnresolvedComment()
+		if first == nil || first.ID != id {
+			t.Fatalf("expected comment %d to be worked on next, got %+v", id, first)
+		}
+		if err := repo.acknowledgeComments(pr, map[int64]string{id: "done"}); err != nil {
+			t.Fatalf("acknowledgeComments returned error: %v", err)
+		}
+	}
+	pr := ghPullRequestToPullRequest(*provider.PullRequests[4], local.MuleUser)
+	if pr.HasUnresolvedComments() {
+		t.Errorf("expected both comments to be answered, got %+v", pr.UnresolvedComments())
+	}
+}
+
+func TestAcknowledged(t *testing.T) {
+	tests := []struct {
+		name    string
+		comment *types.Comment
+		want    bool
+	}{
+		{"reaction without thread", &types.Comment{ID: 1, Reactions: types.Reactions{PlusOne: 1}}, true},
+		{"no reaction without thread", &types.Comment{ID: 1}, false},
+		{"reaction in thread", &types.Comment{ID: 1, ThreadID: "T", Reactions: types.Reactions{PlusOne: 1}}, false},
+		{"resolved thread", &types.Comment{ID: 1, ThreadID: "T", Resolved: true}, true},
+		{"outdated thread", &types.Comment{ID: 1, ThreadID: "T", Outdated: true}, true},
+		{"replied to", &types.Comment{ID: 7, ThreadID: "T"}, true},
+		{"reply", &types.Comment{ID: 8, ThreadID: "T", Author: "Mule", Body: "<!-- mule:reply 7 -->\ndone"}, true},
+		{"pasted reply", &types.Comment{ID: 9, ThreadID: "T", Author: "mallory", Body: "<!-- mule:reply 7 -->\ndone"}, false},
+	}
+	replied := map[int64]bool{7: true}
+	for _, tt := range tests {
+		if got := acknowledged(tt.comment, replied, "mule"); got != tt.want {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestRepliedComments(t *testing.T) {
+	replied := repliedComments([]*types.Comment{
+		{ID: 1, Body: "fix the typo"},
+		{ID: 2, Author: "mule", Body: "<!-- mule:reply 1 -->\ndone"},
+		{ID: 3, Body: "and the other one"},
+		{ID: 4, Author: "mallory", Body: "<!-- mule:reply 3 -->\ndone"},
+	}, "mule")
+	if !replied[1] || replied[3] {
+		t.Errorf("expected only the reply of mule's account to count, got %v", replied)
+	}
+	if replied := repliedComments([]*types.Comment{{ID: 2, Author: "mule", Body: "<!-- mule:reply 1 -->"}}, ""); len(replied) != 0 {
+		t.Errorf("expected no replies without a known account, got %v", replied)
+	}
+}
+
+func TestParseCommentMode(t *testing.T) {
+	if mode, err := ParseCommentMode(""); err != nil || mode != DefaultCommentMode {
+		t.Errorf("expected the default mode, got %q, %v", mode, err)
+	}
+	if mode, err := ParseCommentMode("batch"); err != nil || mode != CommentModeBatch {
+		t.Errorf("expected the batch mode, got %q, %v", mode, err)
+	}
+	if _, err := ParseCommentMode("all"); err == nil {
+		t.Errorf("expected an error for an unknown mode")
+	}
+}
@@ -0,0 +1,214 @@
+package repository
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// decides the order the issues selected by a sync are worked on in
+
+type SchedulingPolicy string
+
+/*
+Policies:
+priority: issues labelled mule:p0 first, then mule:p1 and so on, issues
+without a priority label last
+age: oldest issues first
+feedback: issues with unresolved review comments on their pull request first,
+then by priority
+queue: the issues of the explicit queue in its order, then the others by
+priority
+Remaining ties are broken by age, so the order is the same on every sync.
+*/
+const (
+	PolicyPriority      SchedulingPolicy = "priority"
+	PolicyAge           SchedulingPolicy = "age"
+	PolicyFeedbackFirst SchedulingPolicy = "feedback"
+	PolicyQueue         SchedulingPolicy = "queue"
+)
+
+// DefaultSchedulingPolicy is used when a repository does not set a policy
+const DefaultSchedulingPolicy = PolicyPriority
+
+// PriorityLabelPrefix is followed by the priority of an issue, lower is more
+// urgent
+const PriorityLabelPrefix = "mule:p"
+
+// ParseSchedulingPolicy checks a policy name, an empty name gives the default
+func ParseSchedulingPolicy(name string) (SchedulingPolicy, error) {
+	policy := SchedulingPolicy(strings.TrimSpace(name))
+	switch policy {
+	case "":
+		return DefaultSchedulingPolicy, nil
+	case PolicyPriority, PolicyAge, PolicyFeedbackFirst, PolicyQueue:
+		return policy, nil
+	}
+	return "", fmt.Errorf("unknown scheduling policy: %s", name)
+}
+
+// QueuedIssue is an issue in the order it will be worked on
+type QueuedIssue struct {
+	Number int    `json:"number"`
+	Title  string `json:"title"`
+	// Priority is the priority from the issue labels, -1 without one
+	Priority  int    `json:"priority"`
+	CreatedAt string `json:"createdAt"`
+	// Queued is set for issues in the explicit queue
+	Queued        bool `json:"queued"`
+	NeedsFeedback bool `json:"needsFeedback"`
+	Completed     bool `json:"completed"`
+}
+
+// Priority returns the priority from the mule:p<n> labels of the issue, the
+// most urgent one when there are several. Issues without a priority label
+// get math.MaxInt.
+func (i *Issue) Priority() int {
+	priority := math.MaxInt
+	for _, label := range i.Labels {
+		value, found := strings.CutPrefix(label, PriorityLabelPrefix)
+		if !found {
+			continue
+		}
+		if n, err := strconv.Atoi(value); err == nil && n >= 0 && n < priority {
+			priority = n
+		}
+	}
+	return priority
+}
+
+func (i *Issue) needsFeedback() bool {
+	_, hasUnresolvedComments := i.PRHasUnresolvedComments()
+	return hasUnresolvedComments
+}
+
+func (r *Repository) schedulingPolicy() SchedulingPolicy {
+	if r.SchedulingPolicy == "" {
+		return DefaultSchedulingPolicy
+	}
+	return r.SchedulingPolicy
+}
+
+// orderIssues sorts the issues in the order they are worked on
+func (r *Repository) orderIssues(issues []*Issue) {
+	r.Mu.RLock()
+	policy := r.schedulingPolicy()
+	positions := queuePositions(r.Queue)
+	r.Mu.RUnlock()
+
+	slices.SortStableFunc(issues, func(a, b *Issue) int {
+		return compareIssues(policy, positions, a, b)
+	})
+}
+
+// IssueQueue returns the open issues in the order the next sync works on them
+func (r *Repository) IssueQueue() []QueuedIssue {
+	issues, _ := r.GetIssues()
+	r.orderIssues(issues)
+
+	r.Mu.RLock()
+	positions := queuePositions(r.Queue)
+	r.Mu.RUnlock()
+
+	queue := make([]QueuedIssue, len(issues))
+	for i, issue := range issues {
+		priority := issue.Priority()
+		if priority == math.MaxInt {
+			priority = -1
+		}
+		_, queued := positions[issue.Number]
+		queue[i] = QueuedIssue{
+			Number:        issue.Number,
+			Title:         issue.Title,
+			Priority:      priority,
+			CreatedAt:     issue.CreatedAt,
+			Queued:        queued,
+			NeedsFeedback: issue.needsFeedback(),
+			Completed:     issue.Completed(),
+		}
+	}
+	return queue
+}
+
+func queuePositions(queue []int) map[int]int {
+	positions := make(map[int]int, len(queue))
+	for i, number := range queue {
+		if _, ok := positions[number]; !ok {
+			positions[number] = i
+		}
+	}
+	return positions
+}
+
+func compareIssues(policy SchedulingPolicy, positions map[int]int, a, b *Issue) int {
+	switch policy {
+	case PolicyAge:
+		return compareAge(a, b)
+	case PolicyQueue:
+		if c := compareQueued(positions, a, b); c != 0 {
+			return c
+		}
+	case PolicyFeedbackFirst:
+		// true sorts first
+		if c := cmp.Compare(boolRank(b.needsFeedback()), boolRank(a.needsFeedback())); c != 0 {
+			return c
+		}
+	}
+	if c := cmp.Compare(a.Priority(), b.Priority()); c != 0 {
+		return c
+	}
+	return compareAge(a, b)
+}
+
+func compareQueued(positions map[int]int, a, b *Issue) int {
+	positionA, queuedA := positions[a.Number]
+	positionB, queuedB := positions[b.Number]
+	switch {
+	case queuedA && queuedB:
+		return cmp.Compare(positionA, positionB)
+	case queuedA:
+		return -1
+	case queuedB:
+		return 1
+	}
+	return 0
+}
+
+// timeLayouts are the timestamp formats of the remote providers, GitHub
+// issues use the default format of time.Time
+var timeLayouts = []string{time.RFC3339, "2006-01-02 15:04:05 -0700 MST"}
+
+func parseTime(value string) (time.Time, error) {
+	var err error
+	for _, layout := range timeLayouts {
+		var parsed time.Time
+		if parsed, err = time.Parse(layout, value); err == nil {
+			return parsed, nil
+		}
+	}
+	return time.Time{}, err
+}
+
+// compareAge orders older issues first, issues without a parsable creation
+// time by number
+func compareAge(a, b *Issue) int {
+	createdA, errA := parseTime(a.CreatedAt)
+	createdB, errB := parseTime(b.CreatedAt)
+	if errA == nil && errB == nil {
+		if c := createdA.Compare(createdB); c != 0 {
+			return c
+		}
+	}
+	return cmp.Compare(a.Number, b.Number)
+}
+
+func boolRank(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
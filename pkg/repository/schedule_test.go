@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/mule-ai/mule/pkg/remote/types"
+)
+
+func issueNumbers(issues []*Issue) []int {
+	numbers := make([]int, len(issues))
+	for i, issue := range issues {
+		numbers[i] = issue.Number
+	}
+	return numbers
+}
+
+func TestOrderIssues(t *testing.T) {
+	feedback := &PullRequest{Comments: []*Comment{{ID: 1, Body: "please rename"}}}
+	newIssues := func() []*Issue {
+		return []*Issue{
+			{Number: 1, CreatedAt: "2024-03-01T00:00:00Z"},
+			{Number: 2, CreatedAt: "2024-01-01T00:00:00Z", Labels: []string{"mule", "mule:p2"}},
+			{Number: 3, CreatedAt: "2024-02-01T00:00:00Z", Labels: []string{"mule:p0"}},
+			{Number: 4, CreatedAt: "2024-04-01T00:00:00Z", PullRequests: []*PullRequest{feedback}},
+			{Number: 5, CreatedAt: "2024-05-01T00:00:00Z", Labels: []string{"mule:p2", "mule:p1"}},
+		}
+	}
+
+	tests := []struct {
+		policy SchedulingPolicy
+		queue  []int
+		want   []int
+	}{
+		{policy: "", want: []int{3, 5, 2, 1, 4}},
+		{policy: PolicyPriority, want: []int{3, 5, 2, 1, 4}},
+		{policy: PolicyAge, want: []int{2, 3, 1, 4, 5}},
+		{policy: PolicyFeedbackFirst, want: []int{4, 3, 5, 2, 1}},
+		{policy: PolicyQueue, queue: []int{1, 9, 4}, want: []int{1, 4, 3, 5, 2}},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			repo := NewRepository(t.TempDir())
+			repo.SchedulingPolicy = tt.policy
+			repo.Queue = tt.queue
+			issues := newIssues()
+			// the input order must not matter
+			slices.Reverse(issues)
+			repo.orderIssues(issues)
+			if got := issueNumbers(issues); !slices.Equal(got, tt.want) {
+				t.Errorf("expected order %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestIssueLabelsFromRemote(t *testing.T) {
+	issue := ghIssueToIssue(types.Issue{Number: 1, Labels: []string{"mule", "mule:p1"}})
+	if got := issue.Priority(); got != 1 {
+		t.Errorf("expected priority 1 from the remote labels, got %d", got)
+	}
+}
+
+func TestCompareAgeFormats(t *testing.T) {
+	// GitHub issues use the default time format, the other providers RFC 3339
+	older := &Issue{Number: 9, CreatedAt: "2024-01-01 10:00:00 +0000 UTC"}
+	newer := &Issue{Number: 2, CreatedAt: "2024-01-01T12:00:00+01:00"}
+	if got := compareAge(older, newer); got != -1 {
+		t.Errorf("expected the older issue first, got %d", got)
+	}
+}
+
+func TestIssuePriority(t *testing.T) {
+	tests := []struct {
+		labels []string
+		want   int
+	}{
+		{labels: []string{"mule:p0"}, want: 0},
+		{labels: []string{"mule:p3", "mule:p1"}, want: 1},
+		{labels: []string{"mule", "mule:pending", "mule:p-1"}, want: math.MaxInt},
+	}
+	for _, tt := range tests {
+		issue := &Issue{Labels: tt.labels}
+		if got := issue.Priority(); got != tt.want {
+			t.Errorf("expected priority %d for %v, got %d", tt.want, tt.labels, got)
+		}
+	}
+}
+
+func TestParseSchedulingPolicy(t *testing.T) {
+	if policy, err := ParseSchedulingPolicy(""); err != nil || policy != DefaultSchedulingPolicy {
+		t.Errorf("expected the default policy, got %q, %v", policy, err)
+	}
+	if policy, err := ParseSchedulingPolicy("queue"); err != nil || policy != PolicyQueue {
+		t.Errorf("expected the queue policy, got %q, %v", policy, err)
+	}
+	if _, err := ParseSchedulingPolicy("random"); err == nil {
+		t.Errorf("expected an error for an unknown policy")
+	}
+}
@@ -26,7 +26,7 @@ func (r *Repository) UpdateStatus() error {
 }
 
 func (r *Repository) Status() (*Status, error) {
-	repo, err := git.PlainOpen(r.Path)
+	repo, err := openRepository(r.Path)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/mule-ai/mule/pkg/agent"
+	"github.com/mule-ai/mule/pkg/validation"
+)
+
+// picks the workflow a repository and each of its issues are worked on with
+
+// WorkflowLabelPrefix starts the label an issue picks its workflow with, like
+// mule:workflow=docs
+const WorkflowLabelPrefix = "mule:workflow="
+
+// configuredWorkflow returns the workflow the repository is worked on with:
+// the one its configuration file names, the one of the repository, or
+// fallback. The validation of the configuration file is applied to it.
+func (r *Repository) configuredWorkflow(fallback *agent.Workflow) *agent.Workflow {
+	workflow := fallback
+	r.Mu.RLock()
+	for _, name := range []string{r.repoConfig.Workflow, r.Workflow} {
+		if named, ok := r.Workflows[name]; ok && name != "" {
+			workflow = named
+			break
+		}
+	}
+	r.Mu.RUnlock()
+	return r.withConfiguredValidation(workflow)
+}
+
+// issueWorkflow returns the workflow the workflow label of the issue names,
+// workflow when it has none. Naming an unknown workflow is an error.
+func (r *Repository) issueWorkflow(issue *Issue, workflow *agent.Workflow) (*agent.Workflow, error) {
+	name := issue.workflowLabel()
+	if name == "" {
+		return workflow, nil
+	}
+	r.Mu.RLock()
+	named, ok := r.Workflows[name]
+	r.Mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown workflow %q in label %s%s", name, WorkflowLabelPrefix, name)
+	}
+	return r.withConfiguredValidation(named), nil
+}
+
+// workflowLabel returns the workflow the issue's labels name, the first one
+// when there are several
+func (i *Issue) workflowLabel() string {
+	for _, label := range i.Labels {
+		if name, ok := strings.CutPrefix(label, WorkflowLabelPrefix); ok && strings.TrimSpace(name) != "" {
+			return strings.TrimSpace(name)
+		}
+	}
+	return ""
+}
+
+// withConfiguredValidation returns a copy of the workflow with its
+// validation functions replaced and extended as the configuration file asks
+func (r *Repository) withConfiguredValidation(workflow *agent.Workflow) *agent.Workflow {
+	config := r.repositoryConfig().Validation
+	if len(config.Functions) == 0 && len(config.Commands) == 0 {
+		return workflow
+	}
+
+	configured := *workflow
+	if len(config.Functions) > 0 {
+		configured.ValidationFunctions = slices.Clone(config.Functions)
+	} else {
+		configured.ValidationFunctions = slices.Clone(workflow.ValidationFunctions)
+	}
+	for _, command := range config.Commands {
+		configured.ValidationFunctions = append(configured.ValidationFunctions, validation.CommandPrefix+command)
+	}
+	return &configured
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/go-logr/logr"
+
+	"github.com/mule-ai/mule/pkg/agent"
+)
+
+func newWorkflows(names ...string) map[string]*agent.Workflow {
+	workflows := make(map[string]*agent.Workflow)
+	for _, name := range names {
+		workflows[name] = agent.NewWorkflow(agent.WorkflowSettings{Name: name}, nil, logr.Discard())
+	}
+	return workflows
+}
+
+func TestConfiguredWorkflow(t *testing.T) {
+	repo := NewRepository(t.TempDir())
+	repo.Workflows = newWorkflows("default", "go", "docs")
+	fallback := repo.Workflows["default"]
+
+	if workflow := repo.configuredWorkflow(fallback); workflow != fallback {
+		t.Errorf("expected the fallback workflow, got %s", workflow.Name())
+	}
+	repo.Workflow = "go"
+	if workflow := repo.configuredWorkflow(fallback); workflow.Name() != "go" {
+		t.Errorf("expected the workflow of the repository, got %s", workflow.Name())
+	}
+	repo.repoConfig = RepoConfig{Workflow: "docs"}
+	if workflow := repo.configuredWorkflow(fallback); workflow.Name() != "docs" {
+		t.Errorf("expected the workflow of the configuration file, got %s", workflow.Name())
+	}
+}
+
+func TestIssueWorkflow(t *testing.T) {
+	repo := NewRepository(t.TempDir())
+	repo.Workflows = newWorkflows("default", "docs")
+	fallback := repo.Workflows["default"]
+
+	workflow, err := repo.issueWorkflow(&Issue{Labels: []string{"mule"}}, fallback)
+	if err != nil || workflow != fallback {
+		t.Errorf("expected the workflow of the sync without a label, got %v, %v", workflow, err)
+	}
+	workflow, err = repo.issueWorkflow(&Issue{Labels: []string{"mule", "mule:workflow=docs"}}, fallback)
+	if err != nil || workflow.Name() != "docs" {
+		t.Errorf("expected the workflow of the label, got %v, %v", workflow, err)
+	}
+	if _, err := repo.issueWorkflow(&Issue{Labels: []string{"mule:workflow=frontend"}}, fallback); err == nil {
+		t.Errorf("expected an error for an unknown workflow")
+	}
+}
@@ -0,0 +1,277 @@
+package repository
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// tracks the progress of the work on each issue across syncs and restarts
+
+type WorkStatus string
+
+/*
+An issue moves through the statuses as it is worked on:
+queued: selected by a sync, waiting for a free worktree
+generating: the workflow is running
+validating: checking the generated changes before opening a pull request
+pr_open: a pull request was opened or updated
+awaiting_feedback: review comments were addressed, waiting for the reviewer
+declined: the pull request was closed without merging, the issue is left
+alone until it is edited
+done: the issue was closed
+failed: the last attempt failed, see LastError
+*/
+const (
+	WorkQueued           WorkStatus = "queued"
+	WorkGenerating       WorkStatus = "generating"
+	WorkValidating       WorkStatus = "validating"
+	WorkPROpen           WorkStatus = "pr_open"
+	WorkAwaitingFeedback WorkStatus = "awaiting_feedback"
+	WorkDeclined         WorkStatus = "declined"
+	WorkDone             WorkStatus = "done"
+	WorkFailed           WorkStatus = "failed"
+)
+
+// WorkStateFile is the name of the work state file, stored next to the config
+const WorkStateFile = "work-state.json"
+
+// DefaultMaxAttempts is the number of failed attempts after which an issue is
+// skipped, until it is edited
+const DefaultMaxAttempts = 3
+
+type IssueWorkState struct {
+	Issue  int        `json:"issue"`
+	Status WorkStatus `json:"status"`
+	// Attempts counts the workflow runs since the last successful one
+	Attempts  int    `json:"attempts"`
+	LastError string `json:"lastError,omitempty"`
+	Branch    string `json:"branch,omitempty"`
+	// Workflow and RunID identify the run that last opened or updated the
+	// pull request
+	Workflow    string `json:"workflow,omitempty"`
+	RunID       string `json:"runId,omitempty"`
+	PullRequest int    `json:"pullRequest,omitempty"`
+	// IssueDigest identifies the issue's title, body and comments when the
+	// state was recorded, editing or commenting on a failing issue gives it
+	// a new set of attempts
+	IssueDigest string    `json:"issueDigest,omitempty"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+	// Outcome is set once the pull request was merged or closed
+	Outcome *PullRequestOutcome `json:"outcome,omitempty"`
+}
+
+// Active reports whether the issue is still worked on by mule
+func (s IssueWorkState) Active() bool {
+	return s.Status != "" && s.Status != WorkDone
+}
+
+// WorkStateStore keeps the work state of the issues of all repositories. It
+// is saved to its path on every change, a store without path is kept in
+// memory only.
+type WorkStateStore struct {
+	path         string
+	mu           sync.RWMutex
+	repositories map[string]map[int]*IssueWorkState
+}
+
+func NewWorkStateStore(path string) *WorkStateStore {
+	return &WorkStateStore{
+		path:         path,
+		repositories: make(map[string]map[int]*IssueWorkState),
+	}
+}
+
+// LoadWorkStateStore reads the store saved at path, a missing file gives an
+// empty store
+func LoadWorkStateStore(path string) (*WorkStateStore, error) {
+	store := NewWorkStateStore(path)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return store, nil
+	} else if err != nil {
+		return store, fmt.Errorf("error reading work state: %v", err)
+	}
+	if err := json.Unmarshal(data, &store.repositories); err != nil {
+		return store, fmt.Errorf("error decoding work state: %v", err)
+	}
+	return store, nil
+}
+
+// Get returns the work state of an issue, the zero state if it has none
+func (s *WorkStateStore) Get(repoPath string, issue int) IssueWorkState {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	state, ok := s.repositories[repoPath][issue]
+	if !ok {
+		return IssueWorkState{Issue: issue}
+	}
+	return *state
+}
+
+// Repository returns the work states of all issues of a repository
+func (s *WorkStateStore) Repository(repoPath string) map[int]IssueWorkState {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	states := make(map[int]IssueWorkState, len(s.repositories[repoPath]))
+	for issue, state := range s.repositories[repoPath] {
+		states[issue] = *state
+	}
+	return states
+}
+
+// Update changes the work state of an issue and saves the store
+func (s *WorkStateStore) Update(repoPath string, issue int, update func(*IssueWorkState)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	states, ok := s.repositories[repoPath]
+	if !ok {
+		states = make(map[int]*IssueWorkState)
+		s.repositories[repoPath] = states
+	}
+	state, ok := states[issue]
+	if !ok {
+		state = &IssueWorkState{Issue: issue}
+		states[issue] = state
+	}
+	update(state)
+	state.UpdatedAt = time.Now()
+	return s.save()
+}
+
+// RemoveRepository drops the work state of a repository that is no longer
+// tracked
+func (s *WorkStateStore) RemoveRepository(repoPath string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.repositories, repoPath)
+	return s.save()
+}
+
+func (s *WorkStateStore) save() error {
+	if s.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(s.repositories, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+	// write to a temporary file first so a crash can't leave half a file
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("error writing work state: %v", err)
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// WorkStates returns the work state of every issue of the repository
+func (r *Repository) WorkStates() map[int]IssueWorkState {
+	return r.workState().Repository(r.workStatePath())
+}
+
+// workStatePath is the path work states are recorded under, issue worktrees
+// use the one of their repository
+func (r *Repository) workStatePath() string {
+	if r.mainPath != "" {
+		return r.mainPath
+	}
+	return r.Path
+}
+
+func (r *Repository) workState() *WorkStateStore {
+	if r.WorkState == nil {
+		r.WorkState = NewWorkStateStore("")
+	}
+	return r.WorkState
+}
+
+// setWorkState updates the work state of an issue, failing to save it is
+// logged but doesn't stop the work. An issue edited since the state was
+// recorded gets a new set of attempts. Dry runs leave the work states alone.
+func (r *Repository) setWorkState(issue *Issue, update func(*IssueWorkState)) {
+	if r.dryRun != nil {
+		return
+	}
+	digest := issue.digest()
+	err := r.workState().Update(r.workStatePath(), issue.Number, func(state *IssueWorkState) {
+		if state.IssueDigest != digest {
+			state.Attempts = 0
+		}
+		update(state)
+		state.IssueDigest = digest
+	})
+	if err != nil {
+		r.Logger.Error(err, "Error saving work state", "issue", issue.Number)
+	}
+}
+
+// shouldSkip reports whether an issue failed too often to be tried again or
+// its pull request was declined. An issue edited since its last attempt is
+// tried again.
+func (r *Repository) shouldSkip(issue *Issue) bool {
+	state := r.workState().Get(r.workStatePath(), issue.Number)
+	switch {
+	case state.Status == WorkDeclined:
+	case state.Status != WorkFailed || state.Attempts < DefaultMaxAttempts:
+		return false
+	}
+	return state.IssueDigest == issue.digest()
+}
+
+// digest hashes what a user may change to help a failing issue along. The
+// issue's update time can't be used, the failure comments change it too.
+func (i *Issue) digest() string {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s\x00%s", i.Title, i.Body)
+	// a failing workflow label can be fixed without editing the issue
+	if name := i.workflowLabel(); name != "" {
+		fmt.Fprintf(hash, "\x00%s%s", WorkflowLabelPrefix, name)
+	}
+	for _, comment := range i.Comments {
+		if strings.Contains(comment.Body, failureCommentMarker) {
+			continue
+		}
+		fmt.Fprintf(hash, "\x00%s", comment.Body)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// recordPullRequest moves a completed issue to pr_open when its state lags
+// behind, for example after a restart during generation
+func (r *Repository) recordPullRequest(issue *Issue) {
+	state := r.workState().Get(r.workStatePath(), issue.Number)
+	if state.Status == WorkPROpen || state.Status == WorkAwaitingFeedback {
+		return
+	}
+	r.setWorkState(issue, func(state *IssueWorkState) {
+		state.Status = WorkPROpen
+		state.LastError = ""
+		if len(issue.PullRequests) > 0 {
+			state.PullRequest = issue.PullRequests[0].Number
+		}
+	})
+}
+
+// finishWorkStates marks issues that are no longer open as done
+func (r *Repository) finishWorkStates() {
+	for number, state := range r.WorkStates() {
+		if _, open := r.Issues[number]; open || !state.Active() {
+			continue
+		}
+		err := r.workState().Update(r.workStatePath(), number, func(state *IssueWorkState) {
+			state.Status = WorkDone
+		})
+		if err != nil {
+			r.Logger.Error(err, "Error saving work state", "issue", number)
+		}
+	}
+}
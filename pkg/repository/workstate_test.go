@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/go-logr/logr"
+)
+
+func TestWorkStateStorePersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), WorkStateFile)
+	store := NewWorkStateStore(path)
+	err := store.Update("/repos/mule", 4, func(state *IssueWorkState) {
+		state.Status = WorkFailed
+		state.Attempts = 2
+		state.LastError = "no changes found"
+	})
+	if err != nil {
+		t.Fatalf("Update returned error: %v", err)
+	}
+
+	loaded, err := LoadWorkStateStore(path)
+	if err != nil {
+		t.Fatalf("LoadWorkStateStore returned error: %v", err)
+	}
+	got := loaded.Get("/repos/mule", 4)
+	if got.Status != WorkFailed || got.Attempts != 2 || got.LastError != "no changes found" {
+		t.Errorf("unexpected loaded state %+v", got)
+	}
+	if got.UpdatedAt.IsZero() {
+		t.Errorf("expected the update time to be recorded")
+	}
+
+	if err := loaded.RemoveRepository("/repos/mule"); err != nil {
+		t.Fatalf("RemoveRepository returned error: %v", err)
+	}
+	if states := loaded.Repository("/repos/mule"); len(states) != 0 {
+		t.Errorf("expected no states after removing the repository, got %v", states)
+	}
+
+	// a missing file is an empty store
+	empty, err := LoadWorkStateStore(filepath.Join(t.TempDir(), WorkStateFile))
+	if err != nil {
+		t.Fatalf("LoadWorkStateStore returned error: %v", err)
+	}
+	if got := empty.Get("/repos/mule", 4); got.Status != "" {
+		t.Errorf("expected an empty state, got %+v", got)
+	}
+}
+
+func TestFailingIssuesAreSkippedUntilEdited(t *testing.T) {
+	repo := NewRepository("/repos/mule")
+	repo.Logger = logr.Discard()
+	issue := &Issue{ID: 4, Number: 4, Title: "Fix the build", Body: "It fails"}
+
+	for attempt := 1; attempt <= DefaultMaxAttempts; attempt++ {
+		if repo.shouldSkip(issue) {
+			t.Fatalf("expected attempt %d to run", attempt)
+		}
+		repo.setWorkState(issue, func(state *IssueWorkState) {
+			state.Attempts++
+			state.Status = WorkFailed
+		})
+	}
+	if !repo.shouldSkip(issue) {
+		t.Errorf("expected the issue to be skipped after %d failed attempts", DefaultMaxAttempts)
+	}
+
+	// mule's own failure comments don't count as an edit
+	issue.Comments = append(issue.Comments, &Comment{Body: failureCommentMarker + "\nWorking on this issue failed"})
+	if !repo.shouldSkip(issue) {
+		t.Errorf("expected a failure comment to keep the issue skipped")
+	}
+
+	issue.Comments = append(issue.Comments, &Comment{Body: "It fails on the lint step"})
+	if repo.shouldSkip(issue) {
+		t.Errorf("expected an issue with a new comment to be tried again")
+	}
+
+	issue.Comments = nil
+	issue.Body = "It fails on main"
+	if repo.shouldSkip(issue) {
+		t.Errorf("expected an edited issue to be tried again")
+	}
+}
+
+func TestEditedIssueGetsNewAttempts(t *testing.T) {
+	repo := NewRepository("/repos/mule")
+	repo.Logger = logr.Discard()
+	issue := &Issue{ID: 4, Number: 4, Title: "Fix the build", Body: "It fails"}
+	for attempt := 1; attempt <= DefaultMaxAttempts; attempt++ {
+		repo.setWorkState(issue, func(state *IssueWorkState) {
+			state.Attempts++
+			state.Status = WorkFailed
+		})
+	}
+
+	// the sync queues the edited issue before working on it
+	issue.Body = "It fails on main"
+	repo.setWorkState(issue, func(state *IssueWorkState) {
+		state.Status = WorkQueued
+	})
+	if state := repo.workState().Get(repo.workStatePath(), issue.Number); state.Attempts != 0 {
+		t.Errorf("expected the attempts of the edited issue to be reset, got %d", state.Attempts)
+	}
+	repo.setWorkState(issue, func(state *IssueWorkState) {
+		state.Attempts++
+	})
+	if state := repo.workState().Get(repo.workStatePath(), issue.Number); state.Attempts != 1 {
+		t.Errorf("expected the first attempt since the edit, got %d", state.Attempts)
+	}
+}
+
+func TestFinishWorkStates(t *testing.T) {
+	repo := NewRepository("/repos/mule")
+	repo.Logger = logr.Discard()
+	for number, status := range map[int]WorkStatus{1: WorkPROpen, 2: WorkAwaitingFeedback, 3: WorkFailed} {
+		repo.setWorkState(&Issue{Number: number}, func(state *IssueWorkState) {
+			state.Status = status
+		})
+	}
+	// issue 2 is still open, the others were closed
+	repo.Issues = map[int]*Issue{2: {Number: 2}}
+	repo.finishWorkStates()
+
+	states := repo.WorkStates()
+	want := map[int]WorkStatus{1: WorkDone, 2: WorkAwaitingFeedback, 3: WorkDone}
+	for number, status := range want {
+		if states[number].Status != status {
+			t.Errorf("issue %d: expected %s, got %s", number, status, states[number].Status)
+		}
+	}
+}
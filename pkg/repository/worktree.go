@@ -0,0 +1,248 @@
+package repository
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// each issue is worked on in its own git worktree, so several issues of a
+// repository can be generated at the same time while the main checkout stays
+// on the base branch
+
+const (
+	// worktreesPath holds the issue worktrees of every repository, relative
+	// to the home directory
+	worktreesPath = ".config/mule/worktrees"
+	// DefaultMaxConcurrency is the number of issues worked on at once when a
+	// repository does not set a limit
+	DefaultMaxConcurrency = 1
+)
+
+// openRepository opens the repository at path, which may be a linked
+// worktree sharing its objects and refs with the main repository
+func openRepository(path string) (*git.Repository, error) {
+	return git.PlainOpenWithOptions(path, &git.PlainOpenOptions{EnableDotGitCommonDir: true})
+}
+
+func (r *Repository) maxConcurrency() int {
+	if r.MaxConcurrency < 1 {
+		return DefaultMaxConcurrency
+	}
+	return r.MaxConcurrency
+}
+
+// worktreesDir returns the directory the issue worktrees of the repository
+// are created in
+func (r *Repository) worktreesDir() (string, error) {
+	return r.dataDir(worktreesPath)
+}
+
+// dataDir returns the directory of the repository below base, which is
+// relative to the home directory
+func (r *Repository) dataDir(base string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("error getting home directory: %v", err)
+	}
+	name := strings.Trim(strings.ReplaceAll(filepath.Clean(r.Path), string(filepath.Separator), "-"), "-")
+	return filepath.Join(home, base, name), nil
+}
+
+// git runs a git command in the repository
+func (r *Repository) git(args ...string) error {
+	_, err := r.gitOutput(args...)
+	return err
+}
+
+// gitOutput runs a git command in the repository and returns its output
+func (r *Repository) gitOutput(args ...string) (string, error) {
+	output, err := exec.Command("git", append([]string{"-C", r.Path}, args...)...).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %v: %s", strings.Join(args, " "), err, strings.TrimSpace(string(output)))
+	}
+	return string(output), nil
+}
+
+// issueWorktree returns a repository for the branch checked out in its own
+// worktree. The worktree is created on first use, with the branch started
+// from the base branch if it doesn't exist yet.
+func (r *Repository) issueWorktree(branchName string) (*Repository, error) {
+	dir, err := r.worktreesDir()
+	if err != nil {
+		return nil, err
+	}
+	path := filepath.Join(dir, branchName)
+
+	// git doesn't support adding and pruning worktrees concurrently
+	r.worktreeMu.Lock()
+	_, err = os.Stat(path)
+	if os.IsNotExist(err) {
+		err = r.createWorktree(dir, path, branchName)
+	}
+	r.worktreeMu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	return r.openWorktree(path, branchName)
+}
+
+// openWorktree returns a repository for a worktree of the repository, with
+// its changes discarded
+func (r *Repository) openWorktree(path, name string) (*Repository, error) {
+	// the settings the work on the issue depends on
+	r.Mu.RLock()
+	commits, authorization, workflows := r.Commits, r.Authorization, r.Workflows
+	dryRun, deleteBranches := r.DryRun, r.DeleteBranches
+	r.Mu.RUnlock()
+
+	worktree := &Repository{
+		Path:            path,
+		RemoteProvider:  r.RemoteProvider,
+		RemotePath:      r.RemotePath,
+		BaseBranch:      r.baseBranch(),
+		Auth:            r.Auth,
+		CommentMode:     r.CommentMode,
+		ReplyToComments: r.ReplyToComments,
+		Labels:          r.Labels,
+		Authorization:   authorization,
+		DeleteBranches:  deleteBranches,
+		Commits:         commits,
+		DryRun:          dryRun,
+		Workflows:       workflows,
+		Issues:          make(map[int]*Issue),
+		PullRequests:    make(map[int]*PullRequest),
+		Logger:          r.Logger.WithValues("worktree", name),
+		Remote:          r.Remote,
+		RAG:             r.RAG,
+		WorkState:       r.workState(),
+		mainPath:        r.Path,
+		repoConfig:      r.repositoryConfig(),
+	}
+
+	// a previous run may have left changes behind
+	if err := worktree.resetWorktree(); err != nil {
+		return nil, fmt.Errorf("error resetting worktree: %v", err)
+	}
+	if err := worktree.UpdateStatus(); err != nil {
+		return nil, err
+	}
+
+	if r.RAG != nil && !r.RAG.HasRepository(path) {
+		if err := r.RAG.AddRepository(path); err != nil {
+			worktree.Logger.Error(err, "Error adding worktree to RAG")
+		}
+	}
+	return worktree, nil
+}
+
+// resetWorktree discards all changes of the checkout, untracked files
+// included
+func (r *Repository) resetWorktree() error {
+	if err := r.Reset(); err != nil {
+		return err
+	}
+	return r.git("clean", "-fd")
+}
+
+func (r *Repository) createWorktree(dir, path, branchName string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("error creating worktrees directory: %v", err)
+	}
+	// drop worktrees whose directory was removed by hand
+	if err := r.git("worktree", "prune"); err != nil {
+		return err
+	}
+	return r.addWorktree(path, branchName)
+}
+
+func (r *Repository) addWorktree(path, branchName string) error {
+	startPoint, exists, err := r.issueStartPoint(branchName)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return r.git("worktree", "add", path, branchName)
+	}
+	return r.git("worktree", "add", "--no-track", "-b", branchName, path, startPoint)
+}
+
+// issueStartPoint returns where work on the branch continues from: the
+// branch itself when it exists locally, otherwise the branch on the remote
+// or the latest fetched base branch. exists reports the local branch.
+func (r *Repository) issueStartPoint(branchName string) (startPoint string, exists bool, err error) {
+	repo, err := openRepository(r.Path)
+	if err != nil {
+		return "", false, err
+	}
+	_, err = repo.Reference(plumbing.NewBranchReferenceName(branchName), true)
+	if err == nil {
+		return branchName, true, nil
+	}
+	if err != plumbing.ErrReferenceNotFound {
+		return "", false, err
+	}
+
+	// continue a branch that only exists on the remote, otherwise start
+	// from the latest fetched base branch
+	startPoint = r.baseBranch()
+	for _, name := range []string{branchName, startPoint} {
+		remoteRef := plumbing.NewRemoteReferenceName("origin", name)
+		if _, err := repo.Reference(remoteRef, true); err == nil {
+			return remoteRef.Short(), false, nil
+		}
+	}
+	return startPoint, false, nil
+}
+
+// removeWorktree deletes an issue worktree, the branch itself is kept
+func (r *Repository) removeWorktree(path string) error {
+	if r.RAG != nil {
+		if err := r.RAG.RemoveRepository(path); err != nil {
+			r.Logger.Error(err, "Error removing worktree from RAG", "worktree", path)
+		}
+	}
+	return r.git("worktree", "remove", "--force", path)
+}
+
+// cleanupWorktrees removes the worktrees of branches without an open pull
+// request, their work is either merged, closed or will be started again
+func (r *Repository) cleanupWorktrees() error {
+	r.worktreeMu.Lock()
+	defer r.worktreeMu.Unlock()
+
+	dir, err := r.worktreesDir()
+	if err != nil {
+		return err
+	}
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("error reading worktrees directory: %v", err)
+	}
+
+	openBranches := make(map[string]bool)
+	for _, pullRequest := range r.PullRequests {
+		if pullRequest.Open() {
+			openBranches[pullRequest.Branch] = true
+		}
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() || openBranches[entry.Name()] {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		r.Logger.Info("Removing worktree", "worktree", path)
+		if err := r.removeWorktree(path); err != nil {
+			return err
+		}
+	}
+	return r.git("worktree", "prune")
+}
@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/go-logr/logr"
+
+	"github.com/mule-ai/mule/pkg/auth"
+)
+
+// newClone clones a fresh remote into a temporary directory, with issue
+// worktrees kept below a temporary home directory
+func newClone(t *testing.T) *Repository {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	remoteDir := newRemote(t, "main")
+
+	repo := NewRepository(filepath.Join(t.TempDir(), "clone"))
+	repo.Logger = logr.Discard()
+	repo.Auth = auth.Settings{Method: auth.MethodHTTPS}
+	if err := repo.Clone(remoteDir); err != nil {
+		t.Fatalf("Clone returned error: %v", err)
+	}
+	if err := repo.UpdateStatus(); err != nil {
+		t.Fatalf("UpdateStatus returned error: %v", err)
+	}
+	return repo
+}
+
+func TestIssueWorktree(t *testing.T) {
+	repo := newClone(t)
+
+	worktree, err := repo.issueWorktree("fix-the-build")
+	if err != nil {
+		t.Fatalf("issueWorktree returned error: %v", err)
+	}
+	if worktree.State.CurrentBranch != "fix-the-build" {
+		t.Errorf("expected the issue branch in the worktree, got %q", worktree.State.CurrentBranch)
+	}
+
+	if err := os.WriteFile(filepath.Join(worktree.Path, "fix.txt"), []byte("fixed"), 0644); err != nil {
+		t.Fatalf("error writing file: %v", err)
+	}
+	if err := worktree.Commit("fix the build"); err != nil {
+		t.Fatalf("Commit returned error: %v", err)
+	}
+
+	// the main checkout is left alone on the base branch
+	if err := repo.UpdateStatus(); err != nil {
+		t.Fatalf("UpdateStatus returned error: %v", err)
+	}
+	if repo.State.CurrentBranch != "main" || repo.State.HasChanges {
+		t.Errorf("expected a clean main checkout, got %+v", repo.State)
+	}
+	if _, err := os.Stat(filepath.Join(repo.Path, "fix.txt")); !os.IsNotExist(err) {
+		t.Errorf("expected the change to stay in the worktree")
+	}
+
+	// the worktree is reused and its uncommitted changes discarded
+	if err := os.WriteFile(filepath.Join(worktree.Path, "fix.txt"), []byte("leftover"), 0644); err != nil {
+		t.Fatalf("error writing file: %v", err)
+	}
+	reused, err := repo.issueWorktree("fix-the-build")
+	if err != nil {
+		t.Fatalf("issueWorktree returned error: %v", err)
+	}
+	if reused.Path != worktree.Path || reused.State.HasChanges {
+		t.Errorf("expected the clean existing worktree to be reused, got %+v", reused.State)
+	}
+}
+
+func TestIssueWorktreeSettings(t *testing.T) {
+	repo := newClone(t)
+	repo.Commits = CommitSettings{
+		AuthorName:     "Release Bot",
+		AuthorEmail:    "bot@example.com",
+		CommitterName:  "CI",
+		CommitterEmail: "ci@example.com",
+	}
+	repo.Authorization = AuthorizationRules{Users: []string{"alice"}}
+	repo.DeleteBranches = true
+
+	worktree, err := repo.issueWorktree("fix-the-build")
+	if err != nil {
+		t.Fatalf("issueWorktree returned error: %v", err)
+	}
+	if !worktree.Authorization.Enabled() || !worktree.DeleteBranches {
+		t.Errorf("expected the settings of the repository in the worktree, got %+v %v", worktree.Authorization, worktree.DeleteBranches)
+	}
+	if err := os.WriteFile(filepath.Join(worktree.Path, "fix.txt"), []byte("fixed"), 0644); err != nil {
+		t.Fatalf("error writing file: %v", err)
+	}
+	if err := worktree.Commit("fix the build"); err != nil {
+		t.Fatalf("Commit returned error: %v", err)
+	}
+	if identity := gitOutput(t, worktree.Path, "log", "-1", "--format=%an <%ae> %cn <%ce>"); identity != "Release Bot <bot@example.com> CI <ci@example.com>" {
+		t.Errorf("expected the configured author and committer, got %q", identity)
+	}
+}
+
+func TestIssueWorktreesConcurrently(t *testing.T) {
+	repo := newClone(t)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 4)
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, err := repo.issueWorktree(fmt.Sprintf("issue-%d", i))
+			errs <- err
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Errorf("issueWorktree returned error: %v", err)
+		}
+	}
+}
+
+func TestCleanupWorktrees(t *testing.T) {
+	repo := newClone(t)
+
+	for _, branch := range []string{"open-pr", "closed-pr"} {
+		if _, err := repo.issueWorktree(branch); err != nil {
+			t.Fatalf("issueWorktree returned error: %v", err)
+		}
+	}
+	repo.PullRequests = map[int]*PullRequest{
+		1: {Number: 1, Branch: "open-pr", State: "open"},
+		2: {Number: 2, Branch: "closed-pr", State: "closed"},
+	}
+	if err := repo.cleanupWorktrees(); err != nil {
+		t.Fatalf("cleanupWorktrees returned error: %v", err)
+	}
+
+	dir, err := repo.worktreesDir()
+	if err != nil {
+		t.Fatalf("worktreesDir returned error: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "open-pr")); err != nil {
+		t.Errorf("expected the worktree of the open pull request to be kept: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "closed-pr")); !os.IsNotExist(err) {
+		t.Errorf("expected the worktree of the closed pull request to be removed")
+	}
+}
@@ -1,6 +1,9 @@
 package validation
 
-import "os/exec"
+import (
+	"os/exec"
+	"strings"
+)
 
 // Understood 
 
@@ -14,13 +17,31 @@ var functions = map[string]ValidationFunc{
 	"goTest":       goTest,
 }
 
+// CommandPrefix marks a validation that runs a shell command in the checkout
+// instead of a named function, like "command:make lint"
+const CommandPrefix = "command:"
+
 func Get(name string) (ValidationFunc, bool) {
 	if fn, ok := functions[name]; ok {
 		return fn, true
 	}
+	if command, ok := strings.CutPrefix(name, CommandPrefix); ok && strings.TrimSpace(command) != "" {
+		return Command(command), true
+	}
 	return nil, false
 }
 
+// Command returns a validation running the command with sh in the checkout,
+// it fails when the command exits with an error
+func Command(command string) ValidationFunc {
+	return func(path string) (string, error) {
+		cmd := exec.Command("sh", "-c", command)
+		cmd.Dir = path
+		out, err := cmd.CombinedOutput()
+		return string(out), err
+	}
+}
+
 func goFmt(path string) (string, error) {
 	cmd := exec.Command("go", "fmt", "./...")
 	cmd.Dir = path